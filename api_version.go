@@ -0,0 +1,147 @@
+package httputil
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+type (
+	// APIVersionOption allows default [NewAPIVersionMiddleware] config values
+	// to be overridden.
+	APIVersionOption func(o *apiVersionOptions)
+
+	apiVersionOptions struct {
+		defaultVersion string
+		header         string
+		vendor         string
+	}
+)
+
+// WithAPIVersionDefault sets the version used for requests that do not
+// specify one. By default, such requests are rejected.
+func WithAPIVersionDefault(version string) APIVersionOption {
+	return func(o *apiVersionOptions) {
+		o.defaultVersion = version
+	}
+}
+
+// WithAPIVersionHeader sets the name of the request header that carries the
+// version. An empty name disables the header. The default is API-Version.
+func WithAPIVersionHeader(name string) APIVersionOption {
+	return func(o *apiVersionOptions) {
+		o.header = name
+	}
+}
+
+// WithAPIVersionMediaType enables reading the version from vendor media types
+// in the Accept header, such as application/vnd.myapi.v2+json for a vendor of
+// myapi, which specifies the version v2.
+func WithAPIVersionMediaType(vendor string) APIVersionOption {
+	return func(o *apiVersionOptions) {
+		o.vendor = vendor
+	}
+}
+
+// apiVersionCtxKey is the context key for the API version of a request.
+type apiVersionCtxKey struct{}
+
+// NewAPIVersionMiddleware creates a MiddlewareFunc that resolves the API
+// version requested by the client and stores it in the request context, where
+// it can be read with [VersionFromContext]. The version is read from the
+// API-Version header, see [WithAPIVersionHeader], and then from a vendor media
+// type in the Accept header when [WithAPIVersionMediaType] is used.
+//
+// Requests for a version that is not one of supported are rejected with a
+// [problem.NotAcceptable] error. Requests that do not specify a version use
+// the version set by [WithAPIVersionDefault], or are rejected with a
+// [problem.BadRequest] error if there is none. The request headers that the
+// version is read from are added to the Vary header of the response.
+func NewAPIVersionMiddleware(supported []string, options ...APIVersionOption) MiddlewareFunc {
+	opts := apiVersionOptions{defaultVersion: "", header: "API-Version", vendor: ""}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	var varyOn []string
+	if opts.header != "" {
+		varyOn = append(varyOn, opts.header)
+	}
+
+	if opts.vendor != "" {
+		varyOn = append(varyOn, "Accept")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			AddVary(w.Header(), varyOn...)
+
+			version := opts.requestedVersion(r)
+			if version == "" {
+				version = opts.defaultVersion
+			}
+
+			if version == "" {
+				writeMiddlewareError(w, r, problem.BadRequest(r).WithDetail("The request must specify an API version"))
+				return
+			}
+
+			if !slices.Contains(supported, version) {
+				writeMiddlewareError(w, r, problem.NotAcceptable(r).WithDetail(
+					"API version "+version+" is not supported, supported versions are: "+strings.Join(supported, ", "),
+				))
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionCtxKey{}, version)))
+		})
+	}
+}
+
+// VersionFromContext returns the API version stored in ctx by
+// [NewAPIVersionMiddleware], or an empty string if there is none.
+func VersionFromContext(ctx context.Context) string {
+	version, _ := ctx.Value(apiVersionCtxKey{}).(string)
+	return version
+}
+
+// requestedVersion returns the version specified by r, or an empty string if
+// it does not specify one.
+func (o apiVersionOptions) requestedVersion(r *http.Request) string {
+	if o.header != "" {
+		if version := strings.TrimSpace(r.Header.Get(o.header)); version != "" {
+			return version
+		}
+	}
+
+	if o.vendor == "" {
+		return ""
+	}
+
+	prefix := "application/vnd." + strings.ToLower(o.vendor) + "."
+
+	for _, value := range r.Header.Values("Accept") {
+		for mediaRange := range strings.SplitSeq(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+
+			version, ok := strings.CutPrefix(mediaType, prefix)
+			if !ok {
+				continue
+			}
+
+			if version, _, _ = strings.Cut(version, "+"); version != "" {
+				return version
+			}
+		}
+	}
+
+	return ""
+}
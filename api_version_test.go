@@ -0,0 +1,141 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewAPIVersionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	supported := []string{"v1", "v2"}
+
+	testCases := map[string]struct {
+		options     []httputil.APIVersionOption
+		header      http.Header
+		wantStatus  int
+		wantVersion string
+		wantProblem func(r *http.Request) *problem.DetailedError
+		wantVary    string
+	}{
+		"a supported version in the header is stored on the context": {
+			options:     nil,
+			header:      http.Header{"Api-Version": {"v2"}},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v2",
+			wantProblem: nil,
+			wantVary:    "Api-Version",
+		},
+		"a supported version in a vendor media type is stored on the context": {
+			options:     []httputil.APIVersionOption{httputil.WithAPIVersionMediaType("myapi")},
+			header:      http.Header{"Accept": {"text/html, application/vnd.myapi.v1+json;q=0.9"}},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v1",
+			wantProblem: nil,
+			wantVary:    "Api-Version, Accept",
+		},
+		"the header takes precedence over the media type": {
+			options:     []httputil.APIVersionOption{httputil.WithAPIVersionMediaType("myapi")},
+			header:      http.Header{"Api-Version": {"v2"}, "Accept": {"application/vnd.myapi.v1+json"}},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v2",
+			wantProblem: nil,
+			wantVary:    "Api-Version, Accept",
+		},
+		"a custom header is read": {
+			options:     []httputil.APIVersionOption{httputil.WithAPIVersionHeader("X-Version")},
+			header:      http.Header{"X-Version": {"v1"}, "Api-Version": {"v3"}},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v1",
+			wantProblem: nil,
+			wantVary:    "X-Version",
+		},
+		"an unsupported version is not acceptable": {
+			options:     []httputil.APIVersionOption{httputil.WithAPIVersionMediaType("myapi")},
+			header:      http.Header{"Accept": {"application/vnd.myapi.v3+json"}},
+			wantStatus:  http.StatusNotAcceptable,
+			wantVersion: "",
+			wantProblem: func(r *http.Request) *problem.DetailedError {
+				return problem.NotAcceptable(r).WithDetail("API version v3 is not supported, supported versions are: v1, v2")
+			},
+			wantVary: "Api-Version, Accept",
+		},
+		"a missing version is rejected without a default": {
+			options:     nil,
+			header:      http.Header{"Accept": {"application/json"}},
+			wantStatus:  http.StatusBadRequest,
+			wantVersion: "",
+			wantProblem: func(r *http.Request) *problem.DetailedError {
+				return problem.BadRequest(r).WithDetail("The request must specify an API version")
+			},
+			wantVary: "Api-Version",
+		},
+		"a missing version uses the default": {
+			options:     []httputil.APIVersionOption{httputil.WithAPIVersionDefault("v1")},
+			header:      http.Header{},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v1",
+			wantProblem: nil,
+			wantVary:    "Api-Version",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			var gotVersion string
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodGet, Path: "/orders", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					gotVersion = httputil.VersionFromContext(r.Context())
+					w.WriteHeader(http.StatusOK)
+				})},
+			}.WithMiddleware(httputil.NewAPIVersionMiddleware(supported, testCase.options...))...)
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if gotVersion != testCase.wantVersion {
+				t.Errorf("VersionFromContext() = %q, want: %q", gotVersion, testCase.wantVersion)
+			}
+
+			if got := response.Header().Get("Vary"); got != testCase.wantVary {
+				t.Errorf("Vary = %q, want: %q", got, testCase.wantVary)
+			}
+
+			if testCase.wantProblem != nil {
+				want := testCase.wantProblem(request).MustMarshalJSONString()
+				if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+
+	t.Run("the version is empty outside of the middleware", func(t *testing.T) {
+		t.Parallel()
+
+		if got := httputil.VersionFromContext(t.Context()); got != "" {
+			t.Errorf("VersionFromContext() = %q, want: empty", got)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package httputil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// AuditSink receives the request body captured by [NewAuditMiddleware] once the
+// request has been handled.
+type AuditSink func(r *http.Request, body []byte)
+
+// NewAuditMiddleware creates a MiddlewareFunc that tees the request body into
+// sink for auditing. The body is captured as it is read by the handler, so the
+// handler still receives the full body and sink receives exactly the bytes that
+// the handler read. Because the body is read through the Server's max body size
+// limit, sink never receives more than the limit allows.
+//
+// The sink is called after the handler returns, including when it panics. A
+// request without a body results in sink being called with a nil body.
+func NewAuditMiddleware(sink AuditSink) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				defer sink(r, nil)
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			var body bytes.Buffer
+
+			r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, &body), Closer: r.Body}
+
+			defer func() { sink(r, body.Bytes()) }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// teeReadCloser pairs a tee'd Reader with the Closer of the original body.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,107 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+)
+
+func TestNewAuditMiddleware(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	newAction := func() httputil.Action[request, struct{}] {
+		return func(r httputil.RequestData[request]) (*httputil.Response, error) {
+			return httputil.OK(map[string]string{"name": r.Data.Name})
+		}
+	}
+
+	testCases := map[string]struct {
+		handler                http.Handler
+		serverOptions          []httputil.ServerOption
+		requestBody            string
+		wantAudited            string
+		wantResponseBody       string
+		wantResponseStatusCode int
+	}{
+		"the sink receives the exact bytes and the handler still decodes them": {
+			handler:                httputil.NewHandler(newAction()),
+			requestBody:            `{"name": "test"}`,
+			wantAudited:            `{"name": "test"}`,
+			wantResponseBody:       `{"name":"test"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"the sink receives the exact bytes when the handler replays the body for schema validation": {
+			handler: httputil.NewHandler(
+				newAction(),
+				httputil.WithHandlerRequestSchema([]byte(`{"type": "object", "required": ["name"]}`)),
+			),
+			requestBody:            `{"name": "test"}`,
+			wantAudited:            `{"name": "test"}`,
+			wantResponseBody:       `{"name":"test"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"the sink receives no more than the max body size": {
+			handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buf := make([]byte, 64)
+				for {
+					if _, err := r.Body.Read(buf); err != nil {
+						break
+					}
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			}),
+			serverOptions:          []httputil.ServerOption{httputil.WithServerMaxBodySize(4)},
+			requestBody:            `{"name": "test"}`,
+			wantAudited:            `{"na`,
+			wantResponseStatusCode: http.StatusNoContent,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var audited []byte
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.serverOptions...)
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodPost, Path: "/test", Handler: testCase.handler},
+			}.WithMiddleware(httputil.NewAuditMiddleware(func(_ *http.Request, body []byte) {
+				audited = body
+			}))...)
+
+			request := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(testCase.requestBody))
+			// Clear the content length so that the max body size is enforced whilst reading.
+			request.ContentLength = -1
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantResponseStatusCode {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantResponseStatusCode)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			if got := string(audited); got != testCase.wantAudited {
+				t.Errorf("audited body = %q, want: %q", got, testCase.wantAudited)
+			}
+		})
+	}
+}
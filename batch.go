@@ -0,0 +1,137 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+type (
+	// BatchAction defines the function that is called by a batch handler for
+	// each item of a batch request. It takes the context of the request and the
+	// item and returns the result for the item or an error.
+	BatchAction[In, Out any] func(ctx context.Context, item In) (Out, error)
+
+	// BatchResponse is the response data written by a batch handler. It holds
+	// the result of every item of the batch, in the order of the items in the
+	// request.
+	BatchResponse[Out any] struct {
+		Results []BatchResult[Out] `json:"results"`
+	}
+
+	// BatchResult is the result of a single item of a batch request. Status is
+	// the HTTP status code for the item. Data is set when the item succeeds and
+	// Error when it fails.
+	BatchResult[Out any] struct {
+		Status int                    `json:"status"`
+		Data   *Out                   `json:"data,omitempty"`
+		Error  *problem.DetailedError `json:"error,omitempty"`
+	}
+)
+
+// NewBatchHandler creates a new Handler that decodes a request body holding an
+// array of items and calls action for each of them, running at most
+// concurrency actions at a time. Values of concurrency less than 1 process the
+// items one at a time.
+//
+// The response is always written with a status code of 207 Multi-Status and a
+// [BatchResponse] holding a result for each item, so that the failure of one
+// item does not fail the whole batch. Items that are structs are validated
+// before action is called and are given a 422 [problem.ConstraintViolation]
+// result when invalid. An item that succeeds is given a 200 result holding the
+// data returned by action. An item whose action returns a problem or a sentinel
+// error is given a result holding that problem, and any other error or a panic
+// is given a 500 [problem.ServerError] result and is logged.
+//
+// Options are applied to the underlying handler as with [NewHandler]. Item
+// errors are translated by the ErrorMappers set with [WithServerErrorMapper],
+// and item validation messages are generated by the MessageFunc set with
+// [WithHandlerMessages], as they are for a handler created by [NewHandler].
+// Errors decoding the request body fail the whole batch.
+func NewBatchHandler[In, Out any](action BatchAction[In, Out], concurrency int, options ...HandlerOption) http.Handler {
+	concurrency = max(concurrency, 1)
+	validateItems := reflect.TypeFor[In]().Kind() == reflect.Struct
+
+	// The action reads the error mappers and message func from the handler,
+	// which are resolved from the Server before the action is called.
+	var h *handler[[]In, struct{}]
+
+	h, _ = newHandler(func(r RequestData[[]In]) (*Response, error) {
+		results := make([]BatchResult[Out], len(r.Data))
+		semaphore := make(chan struct{}, concurrency)
+
+		var wg sync.WaitGroup
+
+		for i, item := range r.Data {
+			semaphore <- struct{}{}
+
+			wg.Go(func() {
+				defer func() { <-semaphore }()
+
+				results[i] = runBatchItem(h, &r, action, item, validateItems)
+			})
+		}
+
+		wg.Wait()
+
+		return NewResponse(http.StatusMultiStatus, BatchResponse[Out]{Results: results}), nil
+	}, false, options).(*handler[[]In, struct{}])
+
+	return h
+}
+
+// runBatchItem validates item and calls action with it, converting the outcome
+// to a BatchResult using the error mappers and message func of h. Panics in
+// action are recovered and reported as a server error for the item.
+func runBatchItem[In, Out any](
+	h *handler[[]In, struct{}],
+	r *RequestData[[]In],
+	action BatchAction[In, Out],
+	item In,
+	validateItem bool,
+) (result BatchResult[Out]) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = batchItemError[Out](h, r, fmt.Errorf("batch item panicked: %v", recovered))
+		}
+	}()
+
+	if validateItem {
+		if err := validate.StructCtx(r.Context(), &item); err != nil {
+			if errs, ok := errors.AsType[validator.ValidationErrors](err); ok {
+				problemDetails := problem.ConstraintViolation(r.Request, validationProperties(errs, h.messageFunc)...)
+				return BatchResult[Out]{Status: problemDetails.Status, Data: nil, Error: problemDetails}
+			}
+
+			return batchItemError[Out](h, r, fmt.Errorf("validating batch item: %w", err))
+		}
+	}
+
+	out, err := action(r.Context(), item)
+	if err != nil {
+		return batchItemError[Out](h, r, err)
+	}
+
+	return BatchResult[Out]{Status: http.StatusOK, Data: &out, Error: nil}
+}
+
+// batchItemError converts the error of a batch item to a BatchResult using the
+// error mappers of h. Errors that do not map to a problem are logged and
+// reported as a server error.
+func batchItemError[Out any, In any](h *handler[[]In, struct{}], r *RequestData[[]In], err error) BatchResult[Out] {
+	problemDetails, ok := problemFromError(r.Request, err, h.errorMappers)
+	if !ok {
+		r.logger.ErrorContext(r.Context(), "Batch handler received an unhandled error for an item", slog.Any("error", err), durationAttr(r.startedAt))
+		problemDetails = problem.ServerError(r.Request)
+	}
+
+	return BatchResult[Out]{Status: problemDetails.Status, Data: nil, Error: problemDetails}
+}
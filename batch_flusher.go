@@ -0,0 +1,226 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBatchFlusherClosed is returned when writing to a [BatchFlusher] after it
+// has been closed.
+var ErrBatchFlusherClosed = errors.New("batch flusher is closed")
+
+// BatchFlusher is an io.WriteCloser for streaming responses, such as
+// server-sent events or newline delimited JSON, that batches flushes of the
+// underlying http.ResponseWriter. Each call to Write is treated as a message
+// and the written messages are flushed once a number of messages have been
+// written or a duration has elapsed since the first unflushed message,
+// whichever happens first. By default, every message is flushed as it is
+// written.
+//
+// Close must be called before the handler returns to flush any remaining
+// messages and stop the flush timer. A BatchFlusher is safe for concurrent use.
+type BatchFlusher struct {
+	mu          sync.Mutex
+	controller  *http.ResponseController
+	w           http.ResponseWriter
+	every       int
+	interval    time.Duration
+	pending     int
+	timer       *time.Timer
+	err         error
+	closed      bool
+	ctx         context.Context //nolint:containedctx // Watched for the deadline of the request, see WithFlushOnTimeout.
+	onTimeout   func(w io.Writer)
+	stopTimeout func() bool
+}
+
+// BatchFlusherOption allows default BatchFlusher config values to be
+// overridden.
+type BatchFlusherOption func(f *BatchFlusher)
+
+// WithFlushEvery sets the number of messages to write before flushing. Values
+// less than 1 are ignored. If not set, every message is flushed unless
+// [WithFlushInterval] is set.
+func WithFlushEvery(messages int) BatchFlusherOption {
+	return func(f *BatchFlusher) {
+		if messages > 0 {
+			f.every = messages
+		}
+	}
+}
+
+// WithFlushInterval sets the maximum duration that a written message waits
+// before being flushed. If set without [WithFlushEvery], messages are only
+// flushed by the interval or when the BatchFlusher is closed.
+func WithFlushInterval(interval time.Duration) BatchFlusherOption {
+	return func(f *BatchFlusher) {
+		if interval > 0 {
+			f.interval = interval
+		}
+	}
+}
+
+// WithFlushOnTimeout calls onTimeout when the deadline of ctx, usually the
+// context of the request, is exceeded mid-stream, so that the handler can write
+// a terminal frame rather than the connection being cut abruptly. For example,
+// an error event for server-sent events or a trailing error object for newline
+// delimited JSON. The frame written to w by onTimeout is flushed and the
+// BatchFlusher is closed, so later writes return [ErrBatchFlusherClosed].
+// onTimeout is not called if ctx is canceled for another reason, such as the
+// client disconnecting, or once the BatchFlusher is closed.
+func WithFlushOnTimeout(ctx context.Context, onTimeout func(w io.Writer)) BatchFlusherOption {
+	return func(f *BatchFlusher) {
+		f.ctx = ctx
+		f.onTimeout = onTimeout
+	}
+}
+
+// NewBatchFlusher creates a new BatchFlusher that writes to w. The
+// http.ResponseWriter must support flushing, either directly or through an
+// Unwrap method, otherwise flushing returns an error wrapping
+// http.ErrNotSupported.
+func NewBatchFlusher(w http.ResponseWriter, opts ...BatchFlusherOption) *BatchFlusher {
+	//nolint:exhaustruct // Zero values for the remaining fields are intentional.
+	f := &BatchFlusher{
+		controller: http.NewResponseController(w),
+		w:          w,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.every == 0 && f.interval == 0 {
+		f.every = 1
+	}
+
+	if f.ctx != nil && f.onTimeout != nil {
+		f.stopTimeout = context.AfterFunc(f.ctx, f.flushOnTimeout)
+	}
+
+	return f
+}
+
+// Write writes p to the response as a single message, flushing the response if
+// the batch is full. It returns any error from a previous timed flush.
+func (f *BatchFlusher) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, ErrBatchFlusherClosed
+	}
+
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("writing message: %w", err)
+	}
+
+	f.pending++
+
+	if f.every > 0 && f.pending >= f.every {
+		return n, f.flush()
+	}
+
+	if f.interval > 0 && f.timer == nil {
+		f.timer = time.AfterFunc(f.interval, f.flushOnTimer)
+	}
+
+	return n, nil
+}
+
+// Flush flushes any messages written since the last flush.
+func (f *BatchFlusher) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrBatchFlusherClosed
+	}
+
+	return f.flush()
+}
+
+// Close flushes any messages written since the last flush and stops the flush
+// timer. Calling Close more than once, or after the timeout frame of
+// [WithFlushOnTimeout] has been written, has no effect.
+func (f *BatchFlusher) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stopTimeout != nil {
+		f.stopTimeout()
+	}
+
+	if f.closed {
+		return nil
+	}
+
+	f.closed = true
+
+	return f.flush()
+}
+
+// flushOnTimer flushes pending messages once the flush interval has elapsed.
+// An error is recorded to be returned by the next call to Write.
+func (f *BatchFlusher) flushOnTimer() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	if err := f.flush(); err != nil {
+		f.err = err
+	}
+}
+
+// flushOnTimeout writes the timeout frame of [WithFlushOnTimeout] once the
+// deadline of its context is exceeded, flushes it and closes the BatchFlusher.
+func (f *BatchFlusher) flushOnTimeout() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed || !errors.Is(f.ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+
+	f.closed = true
+
+	f.onTimeout(f.w)
+	f.pending++
+
+	// There is no later call to Write to return the error from, and the
+	// handler learns of the timeout from its context.
+	_ = f.flush()
+}
+
+// flush flushes the response if there are pending messages. f.mu must be held.
+func (f *BatchFlusher) flush() error {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+
+	if f.pending == 0 {
+		return nil
+	}
+
+	f.pending = 0
+
+	if err := f.controller.Flush(); err != nil {
+		return fmt.Errorf("flushing response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/httputil"
+)
+
+// flushRecorder records the response body at the time of each flush. Flushes
+// triggered by the flush timer happen on another goroutine.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+
+	mu      sync.Mutex
+	flushed []string
+}
+
+func newFlushRecorder() *flushRecorder {
+	return &flushRecorder{ResponseRecorder: httptest.NewRecorder(), mu: sync.Mutex{}, flushed: nil}
+}
+
+func (r *flushRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.flushed = append(r.flushed, r.Body.String())
+	r.ResponseRecorder.Flush()
+}
+
+func (r *flushRecorder) Flushed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.flushed
+}
+
+func TestBatchFlusher(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		opts        []httputil.BatchFlusherOption
+		run         func(t *testing.T, f *httputil.BatchFlusher, r *flushRecorder)
+		wantFlushed []string
+	}{
+		"every message is flushed by default": {
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b", "c")
+			},
+			wantFlushed: []string{"a", "ab", "abc"},
+		},
+		"messages are flushed in batches of the given size": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(2)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b", "c", "d", "e")
+			},
+			wantFlushed: []string{"ab", "abcd"},
+		},
+		"messages are flushed once the interval has elapsed": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushInterval(time.Second)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, r *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a")
+				sleepAndWait(500 * time.Millisecond)
+				writeMessages(t, f, "b")
+				sleepAndWait(499 * time.Millisecond)
+				assertFlushes(t, r, 0)
+				sleepAndWait(time.Millisecond)
+				writeMessages(t, f, "c")
+				sleepAndWait(time.Second)
+			},
+			wantFlushed: []string{"ab", "abc"},
+		},
+		"a full batch is flushed before the interval has elapsed": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(2), httputil.WithFlushInterval(time.Second)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b", "c")
+				sleepAndWait(time.Second)
+				writeMessages(t, f, "d")
+				sleepAndWait(10 * time.Second)
+			},
+			wantFlushed: []string{"ab", "abc", "abcd"},
+		},
+		"the interval restarts after a full batch is flushed": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(2), httputil.WithFlushInterval(time.Second)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, r *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a")
+				sleepAndWait(900 * time.Millisecond)
+				writeMessages(t, f, "b", "c")
+				sleepAndWait(900 * time.Millisecond)
+				assertFlushes(t, r, 1)
+				sleepAndWait(100 * time.Millisecond)
+			},
+			wantFlushed: []string{"ab", "abc"},
+		},
+		"pending messages are flushed on close": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(2), httputil.WithFlushInterval(time.Second)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b", "c")
+				closeFlusher(t, f)
+				sleepAndWait(10 * time.Second)
+			},
+			wantFlushed: []string{"ab", "abc"},
+		},
+		"closing without pending messages does not flush": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(2)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b")
+				closeFlusher(t, f)
+				closeFlusher(t, f)
+			},
+			wantFlushed: []string{"ab"},
+		},
+		"an explicit flush flushes pending messages": {
+			opts: []httputil.BatchFlusherOption{httputil.WithFlushEvery(10)},
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				writeMessages(t, f, "a", "b")
+
+				if err := f.Flush(); err != nil {
+					t.Errorf("Flush() error = %v, want: nil", err)
+				}
+
+				writeMessages(t, f, "c")
+			},
+			wantFlushed: []string{"ab"},
+		},
+		"writing after close returns an error": {
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ *flushRecorder) {
+				t.Helper()
+				closeFlusher(t, f)
+
+				if _, err := io.WriteString(f, "a"); !errors.Is(err, httputil.ErrBatchFlusherClosed) {
+					t.Errorf("Write() error = %v, want: %v", err, httputil.ErrBatchFlusherClosed)
+				}
+			},
+			wantFlushed: nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			synctest.Test(t, func(t *testing.T) {
+				recorder := newFlushRecorder()
+				flusher := httputil.NewBatchFlusher(recorder, testCase.opts...)
+
+				testCase.run(t, flusher, recorder)
+
+				if diff := cmp.Diff(testCase.wantFlushed, recorder.Flushed()); diff != "" {
+					t.Errorf("flushed bodies mismatch (-want +got):\n%s", diff)
+				}
+			})
+		})
+	}
+}
+
+func TestBatchFlusher_OnTimeout(t *testing.T) {
+	t.Parallel()
+
+	sseFrame := func(w io.Writer) {
+		_, _ = io.WriteString(w, "event: error\ndata: {\"error\":\"timeout\"}\n\n")
+	}
+
+	ndjsonFrame := func(w io.Writer) {
+		_, _ = io.WriteString(w, `{"error":"timeout"}`+"\n")
+	}
+
+	testCases := map[string]struct {
+		onTimeout   func(w io.Writer)
+		run         func(t *testing.T, f *httputil.BatchFlusher, cancel context.CancelFunc)
+		wantFlushed []string
+	}{
+		"a terminal error event is flushed when the deadline is exceeded mid-stream": {
+			onTimeout: sseFrame,
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ context.CancelFunc) {
+				t.Helper()
+				writeMessages(t, f, "data: 1\n\n")
+				sleepAndWait(time.Second)
+
+				if _, err := io.WriteString(f, "data: 2\n\n"); !errors.Is(err, httputil.ErrBatchFlusherClosed) {
+					t.Errorf("Write() error = %v, want: %v", err, httputil.ErrBatchFlusherClosed)
+				}
+
+				closeFlusher(t, f)
+			},
+			wantFlushed: []string{
+				"data: 1\n\n",
+				"data: 1\n\nevent: error\ndata: {\"error\":\"timeout\"}\n\n",
+			},
+		},
+		"a trailing error object is flushed when the deadline is exceeded mid-stream": {
+			onTimeout: ndjsonFrame,
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ context.CancelFunc) {
+				t.Helper()
+				writeMessages(t, f, `{"id":1}`+"\n")
+				sleepAndWait(time.Second)
+				closeFlusher(t, f)
+			},
+			wantFlushed: []string{
+				`{"id":1}` + "\n",
+				`{"id":1}` + "\n" + `{"error":"timeout"}` + "\n",
+			},
+		},
+		"no frame is written when the stream is closed before the deadline": {
+			onTimeout: ndjsonFrame,
+			run: func(t *testing.T, f *httputil.BatchFlusher, _ context.CancelFunc) {
+				t.Helper()
+				writeMessages(t, f, `{"id":1}`+"\n")
+				closeFlusher(t, f)
+				sleepAndWait(time.Second)
+			},
+			wantFlushed: []string{`{"id":1}` + "\n"},
+		},
+		"no frame is written when the context is canceled": {
+			onTimeout: ndjsonFrame,
+			run: func(t *testing.T, f *httputil.BatchFlusher, cancel context.CancelFunc) {
+				t.Helper()
+				writeMessages(t, f, `{"id":1}`+"\n")
+				cancel()
+				synctest.Wait()
+				closeFlusher(t, f)
+			},
+			wantFlushed: []string{`{"id":1}` + "\n"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			synctest.Test(t, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(t.Context(), 500*time.Millisecond)
+				defer cancel()
+
+				recorder := newFlushRecorder()
+				flusher := httputil.NewBatchFlusher(recorder, httputil.WithFlushOnTimeout(ctx, testCase.onTimeout))
+
+				testCase.run(t, flusher, cancel)
+
+				if diff := cmp.Diff(testCase.wantFlushed, recorder.Flushed()); diff != "" {
+					t.Errorf("flushed bodies mismatch (-want +got):\n%s", diff)
+				}
+			})
+		})
+	}
+}
+
+func TestBatchFlusher_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	// A bare http.ResponseWriter that does not implement http.Flusher.
+	var w struct{ http.ResponseWriter }
+
+	w.ResponseWriter = httptest.NewRecorder()
+
+	flusher := httputil.NewBatchFlusher(w)
+
+	if _, err := io.WriteString(flusher, "a"); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Write() error = %v, want: %v", err, http.ErrNotSupported)
+	}
+}
+
+func writeMessages(t *testing.T, f *httputil.BatchFlusher, messages ...string) {
+	t.Helper()
+
+	for _, message := range messages {
+		if _, err := io.WriteString(f, message); err != nil {
+			t.Fatalf("Write() error = %v, want: nil", err)
+		}
+	}
+}
+
+func closeFlusher(t *testing.T, f *httputil.BatchFlusher) {
+	t.Helper()
+
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() error = %v, want: nil", err)
+	}
+}
+
+func assertFlushes(t *testing.T, r *flushRecorder, want int) {
+	t.Helper()
+
+	if got := len(r.Flushed()); got != want {
+		t.Errorf("flushes = %d, want: %d", got, want)
+	}
+}
+
+// sleepAndWait advances the fake clock of the synctest bubble by d and waits
+// for any flushes triggered by the flush timer to complete.
+func sleepAndWait(d time.Duration) {
+	time.Sleep(d)
+	synctest.Wait()
+}
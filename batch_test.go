@@ -0,0 +1,198 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewBatchHandler(t *testing.T) {
+	t.Parallel()
+
+	type (
+		item struct {
+			Name string `json:"name" validate:"required"`
+		}
+		result struct {
+			ID string `json:"id"`
+		}
+	)
+
+	t.Run("a mixed batch reports the status of each item", func(t *testing.T) {
+		t.Parallel()
+
+		logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/users/batch",
+			Handler: httputil.NewBatchHandler(func(ctx context.Context, it item) (result, error) {
+				switch it.Name {
+				case "missing":
+					return result{}, problem.NotFound(httptest.NewRequestWithContext(ctx, http.MethodPost, "/users/batch", nil))
+				case "broken":
+					return result{}, errors.New("database unavailable")
+				case "panics":
+					panic("boom")
+				default:
+					return result{ID: "id-" + it.Name}, nil
+				}
+			}, 2),
+		})
+
+		body := `[{"name":"alice"},{"name":""},{"name":"missing"},{"name":"broken"},{"name":"panics"},{"name":"bob"}]`
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(body)))
+
+		if response.Code != http.StatusMultiStatus {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusMultiStatus)
+		}
+
+		request := httptest.NewRequest(http.MethodPost, "/users/batch", http.NoBody)
+		want := `{"results":[` +
+			`{"status":200,"data":{"id":"id-alice"}},` +
+			`{"status":422,"error":` + problem.ConstraintViolation(request, problem.Property{Detail: "is required", Pointer: "/name"}).MustMarshalJSONString() + `},` +
+			`{"status":404,"error":` + problem.NotFound(request).MustMarshalJSONString() + `},` +
+			`{"status":500,"error":` + problem.ServerError(request).MustMarshalJSONString() + `},` +
+			`{"status":500,"error":` + problem.ServerError(request).MustMarshalJSONString() + `},` +
+			`{"status":200,"data":{"id":"id-bob"}}` +
+			`]}`
+
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+
+		testutil.AssertLog(t, logs, slog.LevelError, "Batch handler received an unhandled error for an item", map[string]slog.Value{
+			"error": slog.AnyValue(errors.New("database unavailable")),
+		})
+
+		if got := logs.Len(); got != 2 {
+			t.Errorf("logs.Len() = %d, want: 2, logs: %+v", got, logs.AsSliceOfNestedKeyValuePairs())
+		}
+	})
+
+	t.Run("items are processed with bounded concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			var inFlight, maxInFlight atomic.Int64
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/users/batch",
+				Handler: httputil.NewBatchHandler(func(_ context.Context, it item) (result, error) {
+					current := inFlight.Add(1)
+					defer inFlight.Add(-1)
+
+					for {
+						seen := maxInFlight.Load()
+						if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+							break
+						}
+					}
+
+					time.Sleep(time.Second)
+
+					return result{ID: it.Name}, nil
+				}, 2),
+			})
+
+			body := `[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"},{"name":"e"}]`
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(body)))
+
+			if response.Code != http.StatusMultiStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusMultiStatus)
+			}
+
+			if got := maxInFlight.Load(); got != 2 {
+				t.Errorf("max in flight = %d, want: 2", got)
+			}
+		})
+	})
+
+	t.Run("item errors use the server error mappers and handler messages", func(t *testing.T) {
+		t.Parallel()
+
+		errDuplicate := errors.New("duplicate user")
+
+		logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger, httputil.WithServerErrorMapper(func(r *http.Request, err error) *problem.DetailedError {
+			if errors.Is(err, errDuplicate) {
+				return problem.ResourceExists(r)
+			}
+
+			return nil
+		}))
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/users/batch",
+			Handler: httputil.NewBatchHandler(func(_ context.Context, it item) (result, error) {
+				if it.Name == "taken" {
+					return result{}, errDuplicate
+				}
+
+				return result{ID: it.Name}, nil
+			}, 1, httputil.WithHandlerMessages(func(tag, _ string) string { return "message for " + tag })),
+		})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(`[{"name":"taken"},{"name":""}]`)))
+
+		if response.Code != http.StatusMultiStatus {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusMultiStatus)
+		}
+
+		request := httptest.NewRequest(http.MethodPost, "/users/batch", http.NoBody)
+		want := `{"results":[` +
+			`{"status":409,"error":` + problem.ResourceExists(request).MustMarshalJSONString() + `},` +
+			`{"status":422,"error":` + problem.ConstraintViolation(request, problem.Property{Detail: "message for required", Pointer: "/name"}).MustMarshalJSONString() + `}` +
+			`]}`
+
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+
+		if !logs.IsEmpty() {
+			t.Errorf("logs = %+v, want: none", logs.AsSliceOfNestedKeyValuePairs())
+		}
+	})
+
+	t.Run("a body that is not an array fails the whole batch", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/users/batch",
+			Handler: httputil.NewBatchHandler(func(_ context.Context, it item) (result, error) {
+				return result{ID: it.Name}, nil
+			}, 1),
+		})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(`{"name":"alice"}`)))
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
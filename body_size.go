@@ -0,0 +1,129 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// BodySizes records the number of bytes read from the request body and written
+// to the response body by a [Handler]. A BodySizes is created for each request
+// served by a [Server] so that access log and metrics middleware can report
+// them once the handler has returned:
+//
+//	next.ServeHTTP(w, r)
+//	sizes := httputil.BodySizesFromContext(r.Context())
+//	logger.Info("Request served", slog.Int64("request_bytes", sizes.RequestBytes()))
+//
+// Only handlers created with [NewHandler] or [NewFormHandler] record sizes;
+// they are not recorded for net/http handlers. BodySizes is safe for concurrent
+// use.
+type BodySizes struct {
+	request  atomic.Int64
+	response atomic.Int64
+}
+
+// bodySizesCtxKey is the context key for BodySizes.
+type bodySizesCtxKey struct{}
+
+// newBodySizesContext returns a copy of ctx holding an empty BodySizes.
+func newBodySizesContext(ctx context.Context) context.Context {
+	//nolint:exhaustruct // Zero value counters are ready to use.
+	return context.WithValue(ctx, bodySizesCtxKey{}, &BodySizes{})
+}
+
+// BodySizesFromContext returns the BodySizes for the request that ctx belongs
+// to, or nil if the request is not being served by a [Server].
+func BodySizesFromContext(ctx context.Context) *BodySizes {
+	sizes, _ := ctx.Value(bodySizesCtxKey{}).(*BodySizes)
+	return sizes
+}
+
+// RequestBytes returns the number of bytes read from the request body. Calling
+// RequestBytes on a nil BodySizes returns 0.
+func (s *BodySizes) RequestBytes() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.request.Load()
+}
+
+// ResponseBytes returns the number of bytes written to the response body.
+// Calling ResponseBytes on a nil BodySizes returns 0.
+func (s *BodySizes) ResponseBytes() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.response.Load()
+}
+
+// countingReadCloser is an io.ReadCloser that records the number of bytes read
+// from the underlying io.ReadCloser.
+type countingReadCloser struct {
+	io.ReadCloser
+
+	count *atomic.Int64
+}
+
+// Read reads from the underlying io.ReadCloser and records the bytes read.
+func (rc *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := rc.ReadCloser.Read(p)
+	rc.count.Add(int64(n))
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned unwrapped.
+}
+
+// countingResponseWriter is a http.ResponseWriter that records the number of
+// bytes written to the response body.
+type countingResponseWriter struct {
+	http.ResponseWriter
+
+	count *atomic.Int64
+}
+
+// Write writes b to the underlying http.ResponseWriter and records the bytes
+// written.
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.count.Add(int64(n))
+
+	if err != nil {
+		return n, fmt.Errorf("writing response: %w", err)
+	}
+
+	return n, nil
+}
+
+// Flush flushes the underlying http.ResponseWriter, if it supports flushing,
+// so that actions can stream responses through the http.Flusher interface.
+func (w *countingResponseWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying http.ResponseWriter for use with
+// http.ResponseController.
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// countBodySizes returns w and a shallow copy of r that record the bytes
+// written to the response body and read from the request body in the
+// BodySizes for the request. They are returned unchanged if there is no
+// BodySizes for the request.
+func countBodySizes(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
+	sizes := BodySizesFromContext(r.Context())
+	if sizes == nil {
+		return w, r
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		r = r.WithContext(r.Context())
+		r.Body = &countingReadCloser{ReadCloser: r.Body, count: &sizes.request}
+	}
+
+	return &countingResponseWriter{ResponseWriter: w, count: &sizes.response}, r
+}
@@ -0,0 +1,121 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestBodySizes(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Name string `json:"name"`
+	}
+
+	// recordedResponseLen expects the length of the recorded response body.
+	const recordedResponseLen = -1
+
+	testCases := map[string]struct {
+		handler           http.Handler
+		requestBody       string
+		wantRequestBytes  int64
+		wantResponseBytes int64
+	}{
+		"records the request and response body sizes": {
+			handler: httputil.NewHandler(func(r httputil.RequestData[request]) (*httputil.Response, error) {
+				return httputil.OK(map[string]string{"greeting": "hello " + r.Data.Name})
+			}),
+			requestBody:       `{"name": "test"}`,
+			wantRequestBytes:  int64(len(`{"name": "test"}`)),
+			wantResponseBytes: int64(len(`{"greeting":"hello test"}` + "\n")),
+		},
+		"records the request body once when it is replayed for schema validation": {
+			handler: httputil.NewHandler(func(r httputil.RequestData[request]) (*httputil.Response, error) {
+				return httputil.OK(map[string]string{"greeting": "hello " + r.Data.Name})
+			}, httputil.WithHandlerRequestSchema([]byte(`{"type": "object", "required": ["name"]}`))),
+			requestBody:       `{"name": "test"}`,
+			wantRequestBytes:  int64(len(`{"name": "test"}`)),
+			wantResponseBytes: int64(len(`{"greeting":"hello test"}` + "\n")),
+		},
+		"records an error response": {
+			handler: httputil.NewHandler(func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+				return httputil.NoContent()
+			}),
+			requestBody:       `{"name": `,
+			wantRequestBytes:  int64(len(`{"name": `)),
+			wantResponseBytes: recordedResponseLen,
+		},
+		"records nothing for a net/http handler": {
+			handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("hello"))
+			}),
+			requestBody:       `{"name": "test"}`,
+			wantRequestBytes:  0,
+			wantResponseBytes: 0,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var sizes *httputil.BodySizes
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodPost, Path: "/test", Handler: testCase.handler},
+			}.WithMiddleware(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r)
+					sizes = httputil.BodySizesFromContext(r.Context())
+				})
+			})...)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(testCase.requestBody)))
+
+			if sizes == nil {
+				t.Fatal("BodySizesFromContext() = nil, want: non-nil")
+			}
+
+			if got := sizes.RequestBytes(); got != testCase.wantRequestBytes {
+				t.Errorf("sizes.RequestBytes() = %d, want: %d", got, testCase.wantRequestBytes)
+			}
+
+			wantResponseBytes := testCase.wantResponseBytes
+			if wantResponseBytes == recordedResponseLen {
+				wantResponseBytes = int64(response.Body.Len())
+			}
+
+			if got := sizes.ResponseBytes(); got != wantResponseBytes {
+				t.Errorf("sizes.ResponseBytes() = %d, want: %d", got, wantResponseBytes)
+			}
+		})
+	}
+}
+
+func TestBodySizesFromContext(t *testing.T) {
+	t.Parallel()
+
+	sizes := httputil.BodySizesFromContext(context.Background())
+	if sizes != nil {
+		t.Fatalf("BodySizesFromContext() = %v, want: nil", sizes)
+	}
+
+	if got := sizes.RequestBytes(); got != 0 {
+		t.Errorf("sizes.RequestBytes() = %d, want: 0", got)
+	}
+
+	if got := sizes.ResponseBytes(); got != 0 {
+		t.Errorf("sizes.ResponseBytes() = %d, want: 0", got)
+	}
+}
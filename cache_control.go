@@ -0,0 +1,109 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl describes the directives of a Cache-Control response header.
+// Use [Response.WithCacheControl] to set it on a Response. Durations are
+// pointers so that a zero duration, as in "max-age=0", can be distinguished
+// from an unset one, which is omitted. They are rendered in whole seconds,
+// rounded down, and can be set with new, as in new(time.Hour).
+type CacheControl struct {
+	// Public allows the response to be stored by shared caches, even if it
+	// would otherwise be considered non-cacheable.
+	Public bool
+	// Private restricts storing the response to private caches, such as the
+	// browser. It takes precedence over Public.
+	Private bool
+	// NoCache allows the response to be stored but requires it to be
+	// revalidated with the server before each reuse.
+	NoCache bool
+	// NoStore prevents the response from being stored by any cache.
+	NoStore bool
+	// NoTransform prevents intermediaries from transforming the response body.
+	NoTransform bool
+	// MustRevalidate prevents a stale response from being reused without first
+	// being revalidated with the server.
+	MustRevalidate bool
+	// ProxyRevalidate is equivalent to MustRevalidate for shared caches only.
+	ProxyRevalidate bool
+	// Immutable indicates that the response will not change while it is fresh.
+	Immutable bool
+	// MaxAge is how long the response remains fresh.
+	MaxAge *time.Duration
+	// SharedMaxAge overrides MaxAge for shared caches (s-maxage).
+	SharedMaxAge *time.Duration
+	// StaleWhileRevalidate is how long a stale response may be reused whilst
+	// it is revalidated in the background.
+	StaleWhileRevalidate *time.Duration
+	// StaleIfError is how long a stale response may be reused when
+	// revalidation fails with a server error.
+	StaleIfError *time.Duration
+}
+
+// String renders the directives as the value of a Cache-Control header.
+func (cc CacheControl) String() string {
+	directives := make([]string, 0)
+
+	switch {
+	case cc.Private:
+		directives = append(directives, "private")
+	case cc.Public:
+		directives = append(directives, "public")
+	}
+
+	flags := []struct {
+		set       bool
+		directive string
+	}{
+		{set: cc.NoCache, directive: "no-cache"},
+		{set: cc.NoStore, directive: "no-store"},
+		{set: cc.NoTransform, directive: "no-transform"},
+		{set: cc.MustRevalidate, directive: "must-revalidate"},
+		{set: cc.ProxyRevalidate, directive: "proxy-revalidate"},
+		{set: cc.Immutable, directive: "immutable"},
+	}
+
+	for _, flag := range flags {
+		if flag.set {
+			directives = append(directives, flag.directive)
+		}
+	}
+
+	durations := []struct {
+		value     *time.Duration
+		directive string
+	}{
+		{value: cc.MaxAge, directive: "max-age"},
+		{value: cc.SharedMaxAge, directive: "s-maxage"},
+		{value: cc.StaleWhileRevalidate, directive: "stale-while-revalidate"},
+		{value: cc.StaleIfError, directive: "stale-if-error"},
+	}
+
+	for _, duration := range durations {
+		if duration.value != nil {
+			seconds := max(int64(*duration.value/time.Second), 0)
+			directives = append(directives, duration.directive+"="+strconv.FormatInt(seconds, 10))
+		}
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// WithCacheControl sets the Cache-Control header of the response to the given
+// directives, replacing any previously set value. It does not affect other
+// caching headers such as ETag or Last-Modified. It returns the Response to
+// allow chaining.
+func (r *Response) WithCacheControl(cc CacheControl) *Response {
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+
+	r.header.Set("Cache-Control", cc.String())
+
+	return r
+}
@@ -0,0 +1,168 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestCacheControl_String(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		cacheControl httputil.CacheControl
+		want         string
+	}{
+		"no directives renders an empty value": {
+			cacheControl: httputil.CacheControl{},
+			want:         "",
+		},
+		"public max-age": {
+			cacheControl: httputil.CacheControl{Public: true, MaxAge: new(time.Hour)},
+			want:         "public, max-age=3600",
+		},
+		"private no-cache": {
+			cacheControl: httputil.CacheControl{Private: true, NoCache: true},
+			want:         "private, no-cache",
+		},
+		"no-store": {
+			cacheControl: httputil.CacheControl{NoStore: true},
+			want:         "no-store",
+		},
+		"private takes precedence over public": {
+			cacheControl: httputil.CacheControl{Public: true, Private: true},
+			want:         "private",
+		},
+		"durations are rendered in whole seconds": {
+			cacheControl: httputil.CacheControl{MaxAge: new(1500 * time.Millisecond), SharedMaxAge: new(500 * time.Millisecond)},
+			want:         "max-age=1, s-maxage=0",
+		},
+		"zero durations are rendered explicitly": {
+			cacheControl: httputil.CacheControl{Public: true, MustRevalidate: true, MaxAge: new(time.Duration(0)), SharedMaxAge: new(time.Duration(0))},
+			want:         "public, must-revalidate, max-age=0, s-maxage=0",
+		},
+		"negative durations are rendered as zero": {
+			cacheControl: httputil.CacheControl{MaxAge: new(-time.Minute)},
+			want:         "max-age=0",
+		},
+		"all directives": {
+			cacheControl: httputil.CacheControl{
+				Public:               true,
+				NoCache:              true,
+				NoStore:              true,
+				NoTransform:          true,
+				MustRevalidate:       true,
+				ProxyRevalidate:      true,
+				Immutable:            true,
+				MaxAge:               new(time.Minute),
+				SharedMaxAge:         new(2 * time.Minute),
+				StaleWhileRevalidate: new(30 * time.Second),
+				StaleIfError:         new(time.Hour),
+			},
+			want: "public, no-cache, no-store, no-transform, must-revalidate, proxy-revalidate, immutable, " +
+				"max-age=60, s-maxage=120, stale-while-revalidate=30, stale-if-error=3600",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := testCase.cacheControl.String(); got != testCase.want {
+				t.Errorf("CacheControl.String() = %q, want: %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestResponse_WithCacheControl(t *testing.T) {
+	t.Parallel()
+
+	cacheControl := httputil.CacheControl{Public: true, MaxAge: new(time.Hour)}
+
+	testCases := map[string]struct {
+		action         httputil.Action[struct{}, struct{}]
+		wantHeader     http.Header
+		wantStatusCode int
+	}{
+		"with data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+				return res.WithCacheControl(cacheControl), nil
+			},
+			wantHeader:     http.Header{"Cache-Control": {"public, max-age=3600"}},
+			wantStatusCode: http.StatusOK,
+		},
+		"without data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.NoContent()
+				return res.WithCacheControl(cacheControl), nil
+			},
+			wantHeader:     http.Header{"Cache-Control": {"public, max-age=3600"}},
+			wantStatusCode: http.StatusNoContent,
+		},
+		"with a redirect": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.Redirect(http.StatusMovedPermanently, "/elsewhere")
+				return res.WithCacheControl(cacheControl), nil
+			},
+			wantHeader:     http.Header{"Cache-Control": {"public, max-age=3600"}},
+			wantStatusCode: http.StatusMovedPermanently,
+		},
+		"the last value set wins": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+				return res.WithCacheControl(cacheControl).WithCacheControl(httputil.CacheControl{NoStore: true}), nil
+			},
+			wantHeader:     http.Header{"Cache-Control": {"no-store"}},
+			wantStatusCode: http.StatusOK,
+		},
+		"composes with ETag and Last-Modified headers": {
+			action: func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				r.ResponseWriter.Header().Set("ETag", `"v1"`)
+				r.ResponseWriter.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+
+				return res.WithCacheControl(httputil.CacheControl{Private: true, NoCache: true}), nil
+			},
+			wantHeader: http.Header{
+				"Cache-Control": {"private, no-cache"},
+				"Etag":          {`"v1"`},
+				"Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if response.Code != testCase.wantStatusCode {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatusCode)
+			}
+
+			for key := range testCase.wantHeader {
+				if diff := cmp.Diff(testCase.wantHeader.Values(key), response.Header().Values(key)); diff != "" {
+					t.Errorf("response.Header().Values(%q) mismatch (-want +got):\n%s", key, diff)
+				}
+			}
+		})
+	}
+}
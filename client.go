@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"runtime/debug"
 	"slices"
 	"strings"
 )
@@ -27,6 +28,10 @@ func NewClient(options ...ClientOption) *Client {
 		transport = intercept(transport)
 	}
 
+	if opts.budget {
+		transport = newBudgetInterceptor()(transport)
+	}
+
 	return &Client{
 		basePath: strings.TrimRight(opts.basePath, "/"),
 		client: &http.Client{
@@ -49,10 +54,43 @@ func (c *Client) Client() *http.Client {
 	return c.client
 }
 
+// TransportPanicError is returned by the Client when its transport, or one of
+// its interceptors, panics whilst executing a request.
+type TransportPanicError struct {
+	// Value is the value that was recovered from the panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *TransportPanicError) Error() string {
+	return fmt.Sprintf("transport panicked: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error so that it can be
+// matched with errors.Is and errors.As.
+func (e *TransportPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
 // Do executes the provided request using the Client's underlying *http.Client.
 // Unlike the method helpers (Get, Post, etc.), Do does not prepend BasePath;
-// the caller is responsible for constructing the full URL.
-func (c *Client) Do(req *http.Request) (*http.Response, error) {
+// the caller is responsible for constructing the full URL. A panic in the
+// transport or an interceptor is recovered and returned as a
+// [*TransportPanicError].
+func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+
+			resp, err = nil, &TransportPanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+
 	return c.client.Do(req) //nolint:wrapcheck,gosec // No additional context to add; G704: caller controls request URL (standard http.Client.Do semantics).
 }
 
@@ -124,3 +162,27 @@ func (c *Client) do(ctx context.Context, method, path string, body any, options
 
 	return resp, nil
 }
+
+// maxRedirects is the maximum number of redirects followed by
+// safeRedirectPolicy. This matches the default policy of http.Client.
+const maxRedirects = 10
+
+// errTooManyRedirects is returned by safeRedirectPolicy when the maximum
+// number of redirects has been followed.
+var errTooManyRedirects = fmt.Errorf("stopped after %d redirects", maxRedirects)
+
+// safeRedirectPolicy is a RedirectPolicy that removes credentials from a
+// redirected request unless it has the same origin as the original request.
+func safeRedirectPolicy(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errTooManyRedirects
+	}
+
+	if original := via[0].URL; req.URL.Scheme != original.Scheme || req.URL.Host != original.Host {
+		for _, header := range []string{"Authorization", "Cookie", "Proxy-Authorization"} {
+			req.Header.Del(header)
+		}
+	}
+
+	return nil
+}
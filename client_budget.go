@@ -0,0 +1,294 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// retryBaseDelay is the delay before the first retry of a request made
+	// within a RequestBudget, which doubles for each later retry.
+	retryBaseDelay = 100 * time.Millisecond
+	// retryMaxDelay caps the delay between retries of a request made within a
+	// RequestBudget.
+	retryMaxDelay = 5 * time.Second
+)
+
+// ErrRequestBudgetExhausted is returned by a Client created with
+// [WithClientBudget] when a request is made after the time of its
+// [RequestBudget] has run out.
+var ErrRequestBudgetExhausted = errors.New("request budget exhausted")
+
+// RequestBudget bounds the total time and number of retries of the client
+// calls made while serving one inbound request. The budget is shared by every
+// call whose context carries it, see [NewRequestBudgetContext], so that an
+// early slow call leaves less time and fewer retries for later ones. A
+// RequestBudget is safe for concurrent use.
+type RequestBudget struct {
+	deadline time.Time
+	retries  atomic.Int64
+}
+
+// NewRequestBudget creates a RequestBudget that allows calls to be made for
+// timeout from now and to be retried up to retries times in total.
+func NewRequestBudget(timeout time.Duration, retries int) *RequestBudget {
+	budget := &RequestBudget{deadline: time.Now().Add(timeout), retries: atomic.Int64{}}
+	budget.retries.Store(int64(retries))
+
+	return budget
+}
+
+// Remaining returns the time left in the budget, which is zero once it has run
+// out.
+func (b *RequestBudget) Remaining() time.Duration {
+	return max(time.Until(b.deadline), 0)
+}
+
+// RetriesRemaining returns the number of retries left in the budget.
+func (b *RequestBudget) RetriesRemaining() int {
+	return int(max(b.retries.Load(), 0))
+}
+
+// Exhausted reports whether the time in the budget has run out.
+func (b *RequestBudget) Exhausted() bool {
+	return b.Remaining() == 0
+}
+
+// takeRetry consumes one retry from the budget. It reports false, consuming
+// nothing, if no retries or no time are left.
+func (b *RequestBudget) takeRetry() bool {
+	if b.Exhausted() {
+		return false
+	}
+
+	for {
+		retries := b.retries.Load()
+		if retries <= 0 {
+			return false
+		}
+
+		if b.retries.CompareAndSwap(retries, retries-1) {
+			return true
+		}
+	}
+}
+
+// requestBudgetCtxKey is the context key for a RequestBudget.
+type requestBudgetCtxKey struct{}
+
+// NewRequestBudgetContext returns a copy of ctx carrying budget. Requests made
+// with the returned context, or a context derived from it, by a Client created
+// with [WithClientBudget] share budget.
+func NewRequestBudgetContext(ctx context.Context, budget *RequestBudget) context.Context {
+	return context.WithValue(ctx, requestBudgetCtxKey{}, budget)
+}
+
+// RequestBudgetFromContext returns the RequestBudget carried by ctx, or nil if
+// it does not carry one.
+func RequestBudgetFromContext(ctx context.Context) *RequestBudget {
+	budget, _ := ctx.Value(requestBudgetCtxKey{}).(*RequestBudget)
+	return budget
+}
+
+// newBudgetInterceptor creates an InterceptorFunc that bounds requests by the
+// RequestBudget carried by their context and retries them within it. Each
+// attempt is given the time remaining in the budget as its deadline. Attempts
+// that fail with a transport error or a 502, 503 or 504 status are retried
+// while the budget has retries and time left, provided that the request is
+// idempotent and its body can be replayed. Retries are delayed by a capped
+// exponential backoff with jitter, or by the Retry-After header of a 503
+// response, and are not made if the delay would outlast the budget. Requests
+// made once the time in the budget has run out fail with
+// [ErrRequestBudgetExhausted] without being sent. Requests without a budget are
+// passed through unchanged.
+func newBudgetInterceptor() InterceptorFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			budget := RequestBudgetFromContext(req.Context())
+			if budget == nil {
+				return next.RoundTrip(req) //nolint:wrapcheck // The interceptor is transparent without a budget.
+			}
+
+			for attempt, retry := req, 0; ; retry++ {
+				if budget.Exhausted() {
+					return nil, fmt.Errorf("sending request: %w", ErrRequestBudgetExhausted)
+				}
+
+				resp, err := roundTripWithinBudget(next, attempt, budget)
+				if !shouldRetry(attempt, resp, err) {
+					return resp, err
+				}
+
+				delay := retryDelay(retry, resp)
+				if delay >= budget.Remaining() || !budget.takeRetry() {
+					return resp, err
+				}
+
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+
+				if err = waitToRetry(req.Context(), delay); err != nil {
+					return nil, err
+				}
+
+				if attempt, err = rewindRequest(req); err != nil {
+					return nil, err
+				}
+			}
+		})
+	}
+}
+
+// roundTripWithinBudget sends req with the deadline of budget. The deadline is
+// released when the response body is closed, or immediately if the request
+// fails.
+func roundTripWithinBudget(next http.RoundTripper, req *http.Request, budget *RequestBudget) (*http.Response, error) {
+	ctx, cancel := context.WithDeadline(req.Context(), budget.deadline)
+
+	resp, err := next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err //nolint:wrapcheck // Errors from the transport are returned as is.
+	}
+
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// shouldRetry reports whether the attempt to send req that resulted in resp
+// and err can be retried. Only idempotent requests are retried, as the
+// upstream may already have applied the attempt.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !replayable || !isIdempotent(req) {
+		return false
+	}
+
+	if err != nil {
+		// Errors caused by the deadline or cancellation of the request are not
+		// retried as the next attempt would fail in the same way.
+		return req.Context().Err() == nil && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the delay before the retry numbered retry, counting from
+// zero, of an attempt that resulted in resp. The Retry-After header of a 503
+// response is honoured. Otherwise, the delay grows exponentially from
+// retryBaseDelay up to retryMaxDelay, and half of it is randomised so that
+// concurrent callers do not retry in step.
+func retryDelay(retry int, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return delay
+		}
+	}
+
+	delay := retryBaseDelay
+	for range retry {
+		if delay *= 2; delay >= retryMaxDelay {
+			delay = retryMaxDelay
+			break
+		}
+	}
+
+	return delay/2 + rand.N(delay/2) //nolint:gosec // Jitter does not need a secure source of randomness.
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date, into the delay that it asks for.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// waitToRetry waits for delay, returning early with an error if ctx is done.
+func waitToRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting to retry request: %w", ctx.Err())
+	}
+}
+
+// isIdempotent reports whether req can be sent more than once without changing
+// its effect, either because of its method or because it carries an
+// Idempotency-Key header. This follows the retry rule of net/http.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+
+	return hasKey || hasXKey
+}
+
+// rewindRequest returns a copy of req with a fresh body for another attempt.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewinding request body for retry: %w", err)
+	}
+
+	rewound := req.Clone(req.Context())
+	rewound.Body = body
+
+	return rewound, nil
+}
+
+// cancelOnCloseBody is an io.ReadCloser that calls cancel when it is closed so
+// that the deadline of a request is held until its response has been read.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+// Close closes the body and releases the deadline of the request.
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+
+	if err := b.ReadCloser.Close(); err != nil {
+		return fmt.Errorf("closing response body: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/nickbryan/httputil"
+)
+
+// budgetTransport is a fake transport that records the requests it receives
+// and responds with the next status in statuses for each path. A status of 0
+// blocks until the request context is done. Responses carry retryAfter as
+// their Retry-After header when it is set.
+type budgetTransport struct {
+	mu         sync.Mutex
+	statuses   map[string][]int
+	attempts   map[string]int
+	bodies     []string
+	sentAt     []time.Time
+	retryAfter string
+}
+
+func newBudgetTransport(statuses map[string][]int) *budgetTransport {
+	return &budgetTransport{mu: sync.Mutex{}, statuses: statuses, attempts: make(map[string]int), bodies: nil, sentAt: nil, retryAfter: ""}
+}
+
+func (bt *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bt.mu.Lock()
+	status := bt.statuses[req.URL.Path][0]
+	bt.statuses[req.URL.Path] = bt.statuses[req.URL.Path][1:]
+	bt.attempts[req.URL.Path]++
+	bt.sentAt = append(bt.sentAt, time.Now())
+
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		bt.bodies = append(bt.bodies, string(body))
+	}
+	bt.mu.Unlock()
+
+	if status == 0 {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	header := http.Header{}
+	if bt.retryAfter != "" {
+		header.Set("Retry-After", bt.retryAfter)
+	}
+
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func newBudgetClient(transport *budgetTransport) *httputil.Client {
+	return httputil.NewClient(
+		httputil.WithClientBasePath("http://example.com"),
+		httputil.WithClientTransport(transport),
+		httputil.WithClientBudget(),
+	)
+}
+
+func (bt *budgetTransport) attemptsTo(path string) int {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	return bt.attempts[path]
+}
+
+func TestWithClientBudget(t *testing.T) {
+	t.Parallel()
+
+	get := func(t *testing.T, client *httputil.Client, ctx context.Context, path string) (int, error) {
+		t.Helper()
+
+		resp, err := client.Get(ctx, path)
+		if err != nil {
+			return 0, err
+		}
+
+		if err = resp.Body.Close(); err != nil {
+			t.Errorf("unexpected error closing response body: %v", err)
+		}
+
+		return resp.StatusCode, nil
+	}
+
+	t.Run("an early slow call exhausts the budget and a later call refuses to retry", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			transport := newBudgetTransport(map[string][]int{"/slow": {0}, "/flaky": {http.StatusServiceUnavailable}})
+			client := newBudgetClient(transport)
+
+			budget := httputil.NewRequestBudget(2*time.Second, 3)
+			ctx := httputil.NewRequestBudgetContext(t.Context(), budget)
+
+			if _, err := get(t, client, ctx, "/slow"); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("slow call error = %v, want: %v", err, context.DeadlineExceeded)
+			}
+
+			if !budget.Exhausted() {
+				t.Errorf("budget.Exhausted() = false, want: true with %s remaining", budget.Remaining())
+			}
+
+			if _, err := get(t, client, ctx, "/flaky"); !errors.Is(err, httputil.ErrRequestBudgetExhausted) {
+				t.Fatalf("later call error = %v, want: %v", err, httputil.ErrRequestBudgetExhausted)
+			}
+
+			if got := transport.attemptsTo("/flaky"); got != 0 {
+				t.Errorf("attempts to /flaky = %d, want: 0", got)
+			}
+
+			if got := budget.RetriesRemaining(); got != 3 {
+				t.Errorf("budget.RetriesRemaining() = %d, want: 3", got)
+			}
+		})
+	})
+
+	t.Run("retries used by an early call are not available to a later call", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{
+			"/first":  {http.StatusServiceUnavailable, http.StatusOK},
+			"/second": {http.StatusBadGateway, http.StatusOK},
+		})
+		client := newBudgetClient(transport)
+
+		budget := httputil.NewRequestBudget(time.Minute, 1)
+		ctx := httputil.NewRequestBudgetContext(t.Context(), budget)
+
+		if status, err := get(t, client, ctx, "/first"); err != nil || status != http.StatusOK {
+			t.Fatalf("first call = (%d, %v), want: (%d, nil)", status, err, http.StatusOK)
+		}
+
+		if status, err := get(t, client, ctx, "/second"); err != nil || status != http.StatusBadGateway {
+			t.Fatalf("second call = (%d, %v), want: (%d, nil)", status, err, http.StatusBadGateway)
+		}
+
+		if got := transport.attemptsTo("/first"); got != 2 {
+			t.Errorf("attempts to /first = %d, want: 2", got)
+		}
+
+		if got := transport.attemptsTo("/second"); got != 1 {
+			t.Errorf("attempts to /second = %d, want: 1", got)
+		}
+	})
+
+	t.Run("only gateway and unavailable statuses are retried", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{"/": {http.StatusInternalServerError, http.StatusOK}})
+		client := newBudgetClient(transport)
+
+		ctx := httputil.NewRequestBudgetContext(t.Context(), httputil.NewRequestBudget(time.Minute, 3))
+
+		if status, err := get(t, client, ctx, "/"); err != nil || status != http.StatusInternalServerError {
+			t.Fatalf("call = (%d, %v), want: (%d, nil)", status, err, http.StatusInternalServerError)
+		}
+
+		if got := transport.attemptsTo("/"); got != 1 {
+			t.Errorf("attempts = %d, want: 1", got)
+		}
+	})
+
+	t.Run("request bodies are replayed on retry", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{"/": {http.StatusGatewayTimeout, http.StatusCreated}})
+		client := newBudgetClient(transport)
+
+		ctx := httputil.NewRequestBudgetContext(t.Context(), httputil.NewRequestBudget(time.Minute, 1))
+
+		resp, err := client.Put(ctx, "/", map[string]string{"name": "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("resp.StatusCode = %d, want: %d", resp.StatusCode, http.StatusCreated)
+		}
+
+		if want := []string{`{"name":"test"}`, `{"name":"test"}`}; !slices.Equal(transport.bodies, want) {
+			t.Errorf("bodies = %q, want: %q", transport.bodies, want)
+		}
+	})
+
+	t.Run("retries back off exponentially between attempts", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			transport := newBudgetTransport(map[string][]int{"/": {http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}})
+			client := newBudgetClient(transport)
+
+			ctx := httputil.NewRequestBudgetContext(t.Context(), httputil.NewRequestBudget(time.Minute, 3))
+
+			if status, err := get(t, client, ctx, "/"); err != nil || status != http.StatusOK {
+				t.Fatalf("call = (%d, %v), want: (%d, nil)", status, err, http.StatusOK)
+			}
+
+			if got := len(transport.sentAt); got != 3 {
+				t.Fatalf("attempts = %d, want: 3", got)
+			}
+
+			for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+				if got := transport.sentAt[i+1].Sub(transport.sentAt[i]); got < want/2 || got >= want {
+					t.Errorf("delay before retry %d = %s, want: between %s and %s", i+1, got, want/2, want)
+				}
+			}
+		})
+	})
+
+	t.Run("the retry after header of a 503 response is honoured", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			transport := newBudgetTransport(map[string][]int{"/": {http.StatusServiceUnavailable, http.StatusOK}})
+			transport.retryAfter = "3"
+			client := newBudgetClient(transport)
+
+			ctx := httputil.NewRequestBudgetContext(t.Context(), httputil.NewRequestBudget(time.Minute, 1))
+
+			if status, err := get(t, client, ctx, "/"); err != nil || status != http.StatusOK {
+				t.Fatalf("call = (%d, %v), want: (%d, nil)", status, err, http.StatusOK)
+			}
+
+			if got := transport.sentAt[1].Sub(transport.sentAt[0]); got != 3*time.Second {
+				t.Errorf("delay before retry = %s, want: %s", got, 3*time.Second)
+			}
+		})
+	})
+
+	t.Run("a retry that would outlast the budget is not made", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			transport := newBudgetTransport(map[string][]int{"/": {http.StatusServiceUnavailable, http.StatusOK}})
+			transport.retryAfter = "5"
+			client := newBudgetClient(transport)
+
+			budget := httputil.NewRequestBudget(2*time.Second, 1)
+			ctx := httputil.NewRequestBudgetContext(t.Context(), budget)
+
+			if status, err := get(t, client, ctx, "/"); err != nil || status != http.StatusServiceUnavailable {
+				t.Fatalf("call = (%d, %v), want: (%d, nil)", status, err, http.StatusServiceUnavailable)
+			}
+
+			if got := transport.attemptsTo("/"); got != 1 {
+				t.Errorf("attempts = %d, want: 1", got)
+			}
+
+			if got := budget.RetriesRemaining(); got != 1 {
+				t.Errorf("budget.RetriesRemaining() = %d, want: 1", got)
+			}
+		})
+	})
+
+	t.Run("non-idempotent requests are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{"/": {http.StatusGatewayTimeout, http.StatusCreated}})
+		client := newBudgetClient(transport)
+
+		budget := httputil.NewRequestBudget(time.Minute, 1)
+		ctx := httputil.NewRequestBudgetContext(t.Context(), budget)
+
+		resp, err := client.Post(ctx, "/", map[string]string{"name": "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusGatewayTimeout {
+			t.Errorf("resp.StatusCode = %d, want: %d", resp.StatusCode, http.StatusGatewayTimeout)
+		}
+
+		if got := transport.attemptsTo("/"); got != 1 {
+			t.Errorf("attempts = %d, want: 1", got)
+		}
+
+		if got := budget.RetriesRemaining(); got != 1 {
+			t.Errorf("budget.RetriesRemaining() = %d, want: 1", got)
+		}
+	})
+
+	t.Run("non-idempotent requests with an idempotency key are retried", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{"/": {http.StatusGatewayTimeout, http.StatusCreated}})
+		client := newBudgetClient(transport)
+
+		ctx := httputil.NewRequestBudgetContext(t.Context(), httputil.NewRequestBudget(time.Minute, 1))
+
+		resp, err := client.Post(ctx, "/", map[string]string{"name": "test"}, httputil.WithRequestHeader("Idempotency-Key", "abc"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("resp.StatusCode = %d, want: %d", resp.StatusCode, http.StatusCreated)
+		}
+	})
+
+	t.Run("requests without a budget are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		transport := newBudgetTransport(map[string][]int{"/": {http.StatusServiceUnavailable, http.StatusOK}})
+		client := newBudgetClient(transport)
+
+		if status, err := get(t, client, t.Context(), "/"); err != nil || status != http.StatusServiceUnavailable {
+			t.Fatalf("call = (%d, %v), want: (%d, nil)", status, err, http.StatusServiceUnavailable)
+		}
+
+		if got := transport.attemptsTo("/"); got != 1 {
+			t.Errorf("attempts = %d, want: 1", got)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package httputil
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"slices"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// clientCertIdentityCtxKey is the context key for the identity of the client
+// certificate verified by NewClientCertGuard.
+type clientCertIdentityCtxKey struct{}
+
+// NewClientCertGuard creates a Guard that authorizes requests made over mutual
+// TLS by the client certificate. The request must carry a certificate that was
+// verified against the client certificate authorities of the Server, see
+// [WithServerTLSConfig], and one of the names of that certificate must be in
+// allowed. The names of a certificate are its subject common name, DNS names,
+// URIs and email addresses.
+//
+// The matched name is stored in the request context, where it can be read with
+// [ClientCertIdentityFromContext]. Requests without a verified certificate, or
+// with a certificate that has no allowed name, are rejected with a
+// [problem.Forbidden] error.
+func NewClientCertGuard(allowed []string) GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 || len(r.TLS.VerifiedChains) == 0 {
+			return nil, problem.Forbidden(r)
+		}
+
+		for _, name := range certificateNames(r.TLS.PeerCertificates[0]) {
+			if slices.Contains(allowed, name) {
+				return r.WithContext(context.WithValue(r.Context(), clientCertIdentityCtxKey{}, name)), nil
+			}
+		}
+
+		return nil, problem.Forbidden(r)
+	}
+}
+
+// ClientCertIdentityFromContext returns the name of the client certificate
+// verified by [NewClientCertGuard], or an empty string if there is none.
+func ClientCertIdentityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(clientCertIdentityCtxKey{}).(string)
+	return identity
+}
+
+// certificateNames returns the names that identify the subject of cert, in
+// the order: subject common name, DNS names, URIs and email addresses. Empty
+// names are dropped.
+func certificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, 1+len(cert.DNSNames)+len(cert.URIs)+len(cert.EmailAddresses))
+
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	names = append(names, cert.DNSNames...)
+
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+
+	return append(names, cert.EmailAddresses...)
+}
@@ -0,0 +1,108 @@
+package httputil_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewClientCertGuard(t *testing.T) {
+	t.Parallel()
+
+	verified := func(cert *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+
+	allowed := []string{"billing", "orders.internal", "spiffe://example.org/payments"}
+
+	testCases := map[string]struct {
+		tls          *tls.ConnectionState
+		wantStatus   int
+		wantIdentity string
+	}{
+		"a certificate with an allowed common name is accepted": {
+			tls:          verified(&x509.Certificate{Subject: pkix.Name{CommonName: "billing"}}),
+			wantStatus:   http.StatusOK,
+			wantIdentity: "billing",
+		},
+		"a certificate with an allowed DNS name is accepted": {
+			tls:          verified(&x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}, DNSNames: []string{"orders.internal"}}),
+			wantStatus:   http.StatusOK,
+			wantIdentity: "orders.internal",
+		},
+		"a certificate with an allowed URI is accepted": {
+			tls:          verified(&x509.Certificate{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/payments"}}}),
+			wantStatus:   http.StatusOK,
+			wantIdentity: "spiffe://example.org/payments",
+		},
+		"a certificate without an allowed name is forbidden": {
+			tls:          verified(&x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}, DNSNames: []string{"reporting.internal"}}),
+			wantStatus:   http.StatusForbidden,
+			wantIdentity: "",
+		},
+		"an unverified certificate is forbidden": {
+			tls:          &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "billing"}}}},
+			wantStatus:   http.StatusForbidden,
+			wantIdentity: "",
+		},
+		"a request without TLS is forbidden": {
+			tls:          nil,
+			wantStatus:   http.StatusForbidden,
+			wantIdentity: "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var gotIdentity string
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/invoices",
+				Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+					gotIdentity = httputil.ClientCertIdentityFromContext(r.Context())
+					return httputil.OK(map[string]string{})
+				}, httputil.WithHandlerGuard(httputil.NewClientCertGuard(allowed))),
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+			request.TLS = testCase.tls
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if gotIdentity != testCase.wantIdentity {
+				t.Errorf("ClientCertIdentityFromContext() = %q, want: %q", gotIdentity, testCase.wantIdentity)
+			}
+
+			if testCase.wantStatus == http.StatusForbidden {
+				want := problem.Forbidden(request).MustMarshalJSONString()
+				if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
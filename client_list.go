@@ -0,0 +1,78 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingTotalCount is returned by [DecodeList] when the response carries
+// neither a "total" envelope member nor an X-Total-Count header.
+var ErrMissingTotalCount = errors.New("response has no total count")
+
+// listEnvelope is the envelope of a list response, see [DecodeList].
+type listEnvelope struct {
+	Data  json.RawMessage `json:"data"`
+	Total *int            `json:"total"`
+}
+
+// DecodeList decodes the items of a JSON list response into into and returns
+// the total number of items across all pages. Two conventions are supported:
+// an envelope of the form {"data": [...], "total": N}, and a bare JSON array
+// with the total in the X-Total-Count header. The total of an envelope without
+// a "total" member is also read from the header. An error wrapping
+// [ErrMissingTotalCount] is returned if neither is present.
+//
+// DecodeList reads and closes the body of resp.
+func DecodeList(resp *http.Response, into any) (int, error) {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '{' {
+		if err = json.Unmarshal(body, into); err != nil {
+			return 0, fmt.Errorf("decoding list items: %w", err)
+		}
+
+		return totalCountHeader(resp.Header)
+	}
+
+	var envelope listEnvelope
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		return 0, fmt.Errorf("decoding list envelope: %w", err)
+	}
+
+	if err = json.Unmarshal(envelope.Data, into); err != nil {
+		return 0, fmt.Errorf("decoding list items: %w", err)
+	}
+
+	if envelope.Total != nil {
+		return *envelope.Total, nil
+	}
+
+	return totalCountHeader(resp.Header)
+}
+
+// totalCountHeader returns the total number of items in the X-Total-Count
+// header.
+func totalCountHeader(header http.Header) (int, error) {
+	value := strings.TrimSpace(header.Get("X-Total-Count"))
+	if value == "" {
+		return 0, ErrMissingTotalCount
+	}
+
+	total, err := strconv.Atoi(value)
+	if err != nil || total < 0 {
+		return 0, fmt.Errorf("parsing X-Total-Count header %q: invalid total count", value)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,155 @@
+package httputil_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestDecodeList(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID string `json:"id"`
+	}
+
+	testCases := map[string]struct {
+		header    http.Header
+		body      string
+		wantItems []item
+		wantTotal int
+		wantErr   string
+		wantIsErr error
+	}{
+		"decodes the items and total of an envelope": {
+			header:    nil,
+			body:      `{"data":[{"id":"1"},{"id":"2"}],"total":42}`,
+			wantItems: []item{{ID: "1"}, {ID: "2"}},
+			wantTotal: 42,
+			wantErr:   "",
+			wantIsErr: nil,
+		},
+		"the total of an envelope takes precedence over the header": {
+			header:    http.Header{"X-Total-Count": {"7"}},
+			body:      `{"data":[{"id":"1"}],"total":42}`,
+			wantItems: []item{{ID: "1"}},
+			wantTotal: 42,
+			wantErr:   "",
+			wantIsErr: nil,
+		},
+		"the total of an envelope without a total member is read from the header": {
+			header:    http.Header{"X-Total-Count": {"7"}},
+			body:      `{"data":[{"id":"1"}]}`,
+			wantItems: []item{{ID: "1"}},
+			wantTotal: 7,
+			wantErr:   "",
+			wantIsErr: nil,
+		},
+		"decodes the items of an array and the total from the header": {
+			header:    http.Header{"X-Total-Count": {"42"}},
+			body:      `[{"id":"1"},{"id":"2"}]`,
+			wantItems: []item{{ID: "1"}, {ID: "2"}},
+			wantTotal: 42,
+			wantErr:   "",
+			wantIsErr: nil,
+		},
+		"an empty array has a total of zero from the header": {
+			header:    http.Header{"X-Total-Count": {"0"}},
+			body:      `[]`,
+			wantItems: []item{},
+			wantTotal: 0,
+			wantErr:   "",
+			wantIsErr: nil,
+		},
+		"an array without a total count header returns an error": {
+			header:    nil,
+			body:      `[{"id":"1"}]`,
+			wantItems: []item{{ID: "1"}},
+			wantTotal: 0,
+			wantErr:   "response has no total count",
+			wantIsErr: httputil.ErrMissingTotalCount,
+		},
+		"a malformed total count header returns an error": {
+			header:    http.Header{"X-Total-Count": {"many"}},
+			body:      `[{"id":"1"}]`,
+			wantItems: []item{{ID: "1"}},
+			wantTotal: 0,
+			wantErr:   `parsing X-Total-Count header "many": invalid total count`,
+			wantIsErr: nil,
+		},
+		"a negative total count header returns an error": {
+			header:    http.Header{"X-Total-Count": {"-1"}},
+			body:      `[]`,
+			wantItems: []item{},
+			wantTotal: 0,
+			wantErr:   `parsing X-Total-Count header "-1": invalid total count`,
+			wantIsErr: nil,
+		},
+		"items that do not match into return an error": {
+			header:    http.Header{"X-Total-Count": {"1"}},
+			body:      `{"data":{"id":"1"},"total":1}`,
+			wantItems: nil,
+			wantTotal: 0,
+			wantErr:   "decoding list items: json: cannot unmarshal object into Go value of type []httputil_test.item",
+			wantIsErr: nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			body := &closeTrackingReader{Reader: strings.NewReader(testCase.body), closed: false}
+			resp := &http.Response{StatusCode: http.StatusOK, Header: testCase.header, Body: body}
+
+			var items []item
+
+			total, err := httputil.DecodeList(resp, &items)
+
+			if testCase.wantErr != "" {
+				if err == nil {
+					t.Fatalf("DecodeList() error = nil, want: %q", testCase.wantErr)
+				}
+
+				if diff := cmp.Diff(testCase.wantErr, err.Error()); diff != "" {
+					t.Errorf("DecodeList() error mismatch (-want +got):\n%s", diff)
+				}
+
+				if testCase.wantIsErr != nil && !errors.Is(err, testCase.wantIsErr) {
+					t.Errorf("DecodeList() error = %v, want it to wrap: %v", err, testCase.wantIsErr)
+				}
+			} else if err != nil {
+				t.Fatalf("DecodeList() error = %v, want: nil", err)
+			}
+
+			if total != testCase.wantTotal {
+				t.Errorf("DecodeList() total = %d, want: %d", total, testCase.wantTotal)
+			}
+
+			if diff := cmp.Diff(testCase.wantItems, items); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+
+			if !body.closed {
+				t.Error("DecodeList() did not close the response body")
+			}
+		})
+	}
+}
+
+type closeTrackingReader struct {
+	io.Reader
+
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
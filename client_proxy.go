@@ -0,0 +1,110 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// hopByHopHeaders are the headers that apply to a single connection and must
+// not be forwarded by a proxy, as listed by RFC 9110 section 7.6.1.
+//
+//nolint:gochecknoglobals // Lookup table for removeHopByHopHeaders.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy forwards the inbound request to the target base URL and returns the
+// upstream response. The path and query of inbound are resolved against
+// targetBase, and its method, headers and body are copied without buffering,
+// so the body is streamed to the upstream as it is read. Hop-by-hop headers,
+// including those named by the Connection header, are removed from both the
+// forwarded request and the returned response.
+//
+// Unlike the method helpers (Get, Post, etc.), Proxy does not use BasePath. The
+// caller must close the body of the returned response, for example by
+// streaming it back to the client with [CopyResponse].
+func (c *Client) Proxy(ctx context.Context, inbound *http.Request, targetBase string) (*http.Response, error) {
+	target, err := url.Parse(targetBase)
+	if err != nil {
+		return nil, fmt.Errorf("parsing target base url: %w", err)
+	}
+
+	target = target.JoinPath(inbound.URL.Path)
+	target.RawQuery = inbound.URL.RawQuery
+
+	var body io.Reader
+	if inbound.Body != nil && inbound.Body != http.NoBody {
+		body = inbound.Body
+	}
+
+	req, err := http.NewRequestWithContext(ctx, inbound.Method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header = inbound.Header.Clone()
+	removeHopByHopHeaders(req.Header)
+
+	if body != nil {
+		req.ContentLength = inbound.ContentLength
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+
+	removeHopByHopHeaders(resp.Header)
+
+	return resp, nil
+}
+
+// CopyResponse streams resp back to the client through w, copying its headers,
+// status code and body, and closes the body of resp. It is intended for
+// responses returned by [Client.Proxy]; handlers that use it should return
+// [NothingToHandle].
+func CopyResponse(w http.ResponseWriter, resp *http.Response) error {
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	w.WriteHeader(resp.StatusCode)
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("copying response body: %w", err)
+	}
+
+	return nil
+}
+
+// removeHopByHopHeaders removes the hop-by-hop headers from header, including
+// the headers named by its Connection header.
+func removeHopByHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
@@ -0,0 +1,107 @@
+package httputil_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestClient_Proxy(t *testing.T) {
+	t.Parallel()
+
+	type upstreamRequest struct {
+		Method, URI, Body string
+		Header            http.Header
+	}
+
+	received := make(chan upstreamRequest, 1)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		received <- upstreamRequest{
+			Method: r.Method,
+			URI:    r.RequestURI,
+			Body:   string(body),
+			Header: http.Header{
+				"X-Request-Id": r.Header.Values("X-Request-Id"),
+				"X-Hop":        r.Header.Values("X-Hop"),
+				"Keep-Alive":   r.Header.Values("Keep-Alive"),
+				"Upgrade":      r.Header.Values("Upgrade"),
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "orders")
+		w.Header().Set("Keep-Alive", "timeout=5")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1","echo":` + string(body) + `}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := httputil.NewClient()
+
+	logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+	server := httputil.NewServer(logger)
+	server.Register(httputil.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/orders",
+		Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+			resp, err := client.Proxy(r.Context(), r.Request, upstream.URL+"/api")
+			if err != nil {
+				return nil, err
+			}
+
+			if err := httputil.CopyResponse(r.ResponseWriter, resp); err != nil {
+				return nil, err
+			}
+
+			return httputil.NothingToHandle()
+		}),
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/orders?expand=items", strings.NewReader(`{"item":"book"}`))
+	request.Header.Set("X-Request-Id", "abc")
+	request.Header.Set("Connection", "X-Hop")
+	request.Header.Set("X-Hop", "dropped")
+	request.Header.Set("Keep-Alive", "timeout=5")
+	request.Header.Set("Upgrade", "websocket")
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	wantUpstream := upstreamRequest{
+		Method: http.MethodPost,
+		URI:    "/api/orders?expand=items",
+		Body:   `{"item":"book"}`,
+		Header: http.Header{"X-Request-Id": {"abc"}, "X-Hop": nil, "Keep-Alive": nil, "Upgrade": nil},
+	}
+
+	if diff := cmp.Diff(wantUpstream, <-received); diff != "" {
+		t.Errorf("upstream request mismatch (-want +got):\n%s", diff)
+	}
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusCreated)
+	}
+
+	if got, want := response.Body.String(), `{"id":"1","echo":{"item":"book"}}`; got != want {
+		t.Errorf("response.Body = %q, want: %q", got, want)
+	}
+
+	wantHeader := http.Header{"Content-Type": {"application/json"}, "X-Upstream": {"orders"}, "Keep-Alive": nil}
+	for name, want := range wantHeader {
+		if diff := cmp.Diff(want, response.Header().Values(name)); diff != "" {
+			t.Errorf("response.Header[%s] mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
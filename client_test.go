@@ -705,3 +705,103 @@ func newBodyAwareServerClient(t *testing.T) *httputil.Client {
 
 	return httputil.NewClient(httputil.WithClientBasePath(server.URL))
 }
+
+// trackedBody records whether it has been closed.
+type trackedBody struct {
+	io.Reader
+
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClient_TransportPanic(t *testing.T) {
+	t.Parallel()
+
+	errInterceptor := errors.New("interceptor failed")
+
+	testCases := map[string]struct {
+		recovered any
+		wantErrIs error
+		wantError string
+	}{
+		"a panic with a value is returned as an error": {
+			recovered: "interceptor bug",
+			wantError: "transport panicked: interceptor bug",
+		},
+		"a panic with an error can be matched": {
+			recovered: errInterceptor,
+			wantErrIs: errInterceptor,
+			wantError: "transport panicked: interceptor failed",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			client := httputil.NewClient(
+				httputil.WithClientBasePath(server.URL),
+				httputil.WithClientInterceptor(func(_ http.RoundTripper) http.RoundTripper {
+					return httputil.RoundTripperFunc(func(_ *http.Request) (*http.Response, error) {
+						panic(testCase.recovered)
+					})
+				}),
+			)
+
+			assertPanicError := func(t *testing.T, resp *http.Response, err error) {
+				t.Helper()
+
+				if resp != nil {
+					t.Errorf("resp = %v, want: nil", resp)
+				}
+
+				panicErr, ok := errors.AsType[*httputil.TransportPanicError](err)
+				if !ok {
+					t.Fatalf("err = %v, want: *httputil.TransportPanicError", err)
+				}
+
+				if panicErr.Value != testCase.recovered {
+					t.Errorf("panicErr.Value = %v, want: %v", panicErr.Value, testCase.recovered)
+				}
+
+				if !strings.Contains(string(panicErr.Stack), "TestClient_TransportPanic") {
+					t.Errorf("panicErr.Stack does not contain the panicking call site:\n%s", panicErr.Stack)
+				}
+
+				if !strings.Contains(err.Error(), testCase.wantError) {
+					t.Errorf("err.Error() = %q, want it to contain: %q", err.Error(), testCase.wantError)
+				}
+
+				if testCase.wantErrIs != nil && !errors.Is(err, testCase.wantErrIs) {
+					t.Errorf("errors.Is(err, %v) = false, want: true", testCase.wantErrIs)
+				}
+			}
+
+			body := &trackedBody{Reader: strings.NewReader(`{"name":"test"}`), closed: false}
+
+			resp, err := client.Post(t.Context(), "/test", body) //nolint:bodyclose // The response is nil.
+			assertPanicError(t, resp, err)
+
+			if !body.closed {
+				t.Error("request body was not closed")
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatalf("http.NewRequestWithContext() error = %v", err)
+			}
+
+			resp, err = client.Do(req) //nolint:bodyclose // The response is nil.
+			assertPanicError(t, resp, err)
+		})
+	}
+}
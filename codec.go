@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -16,6 +17,32 @@ import (
 	"github.com/nickbryan/httputil/problem"
 )
 
+// ErrTrailingJSONData is returned by the Decode method of a JSONServerCodec
+// created with [WithJSONDisallowTrailingData] when the request body holds data
+// after the JSON document. Handlers report it as a [problem.BadRequest]
+// response.
+var ErrTrailingJSONData = errors.New("request body has data after the JSON document")
+
+// trailingJSONDataDetail is the problem detail used when the request body has
+// data after the JSON document.
+const trailingJSONDataDetail = "The request body must contain a single JSON document"
+
+// utf8BOM is the UTF-8 byte order mark, which is not valid JSON but is
+// prepended to JSON bodies by some clients.
+const utf8BOM = "\ufeff"
+
+// skipBOM returns a reader of r that skips a leading UTF-8 byte order mark.
+// Errors reading the start of r are returned by the first read.
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	return br
+}
+
 // ClientEncoder is an interface for encoding HTTP request bodies for the
 // client. It provides methods for encoding request data and advertising the
 // Content-Type media type. Response decoding is left to the caller, since the
@@ -62,7 +89,11 @@ type ServerCodec interface {
 	// called.
 	Decode(r *http.Request, into any) error
 	// Encode writes the given data to the http.ResponseWriter after encoding it,
-	// returning an error if encoding fails.
+	// returning an error if encoding fails. Encode is called with the status
+	// code of every Response that has data, including non-2xx responses
+	// created with [NewResponse], so implementations can vary the
+	// serialization by status, such as wrapping error data in an envelope.
+	// Problems are written with EncodeError instead.
 	Encode(w http.ResponseWriter, statusCode int, data any) error
 	// EncodeError encodes the provided error into the HTTP response writer and
 	// returns an error if encoding fails.
@@ -71,38 +102,167 @@ type ServerCodec interface {
 
 // JSONServerCodec provides methods to encode data as JSON or decode data from JSON in
 // HTTP requests and responses.
-type JSONServerCodec struct{}
+type JSONServerCodec struct {
+	contentType        string
+	disallowTrailing   bool
+	disableHTMLEscape  bool
+	indent             string
+	problemContentType string
+	useNumber          bool
+}
 
 // Ensure JSONServerCodec implements ServerCodec.
-var _ ServerCodec = JSONServerCodec{}
+var _ ServerCodec = JSONServerCodec{} //nolint:exhaustruct // Compile time implementation check.
+
+const (
+	// defaultJSONCharset is the charset advertised by JSONServerCodec. RFC 8259
+	// requires JSON exchanged between systems to be encoded as UTF-8.
+	defaultJSONCharset = "utf-8"
+	// jsonMediaType is the media type of successful JSON responses.
+	jsonMediaType = "application/json"
+	// problemJSONMediaType is the media type of JSON problem responses.
+	problemJSONMediaType = "application/problem+json"
+)
+
+// JSONServerCodecOption allows default JSONServerCodec config values to be
+// overridden.
+type JSONServerCodecOption func(c *JSONServerCodec)
+
+// WithJSONCharset sets the charset parameter advertised in the Content-Type
+// header of both successful (application/json) and problem
+// (application/problem+json) responses. An empty charset omits the parameter
+// entirely. If not set, the charset defaults to utf-8.
+//
+// The charset only affects the advertised Content-Type; response bodies are
+// always encoded as UTF-8.
+func WithJSONCharset(charset string) JSONServerCodecOption {
+	return func(c *JSONServerCodec) {
+		c.contentType = withCharset(jsonMediaType, charset)
+		c.problemContentType = withCharset(problemJSONMediaType, charset)
+	}
+}
+
+// WithJSONUseNumber causes Decode to decode numbers into an interface{} value
+// as a json.Number instead of a float64. This preserves the precision of large
+// integers decoded into any or map[string]any. Numbers decoded into typed
+// fields are unaffected.
+func WithJSONUseNumber() JSONServerCodecOption {
+	return func(c *JSONServerCodec) {
+		c.useNumber = true
+	}
+}
+
+// WithJSONDisallowTrailingData causes Decode to reject request bodies that
+// hold anything other than whitespace after the JSON document, such as two
+// concatenated objects, with an error wrapping [ErrTrailingJSONData]. By
+// default, trailing data is ignored, as it is by json.Decoder.
+func WithJSONDisallowTrailingData() JSONServerCodecOption {
+	return func(c *JSONServerCodec) {
+		c.disallowTrailing = true
+	}
+}
+
+// WithJSONIndent causes Encode and EncodeError to indent the encoded JSON,
+// with each level beginning on a new line and indented by indent. This is
+// useful for human-readable responses, such as those of debug endpoints. An
+// empty indent produces compact JSON, which is the default.
+func WithJSONIndent(indent string) JSONServerCodecOption {
+	return func(c *JSONServerCodec) {
+		c.indent = indent
+	}
+}
+
+// WithJSONEscapeHTML sets whether the characters &, < and > are escaped within
+// encoded JSON strings so that the JSON can be safely embedded in HTML.
+// Disabling escaping keeps URLs containing query strings readable. If not set,
+// HTML characters are escaped.
+func WithJSONEscapeHTML(escape bool) JSONServerCodecOption {
+	return func(c *JSONServerCodec) {
+		c.disableHTMLEscape = !escape
+	}
+}
+
+// NewJSONServerCodec creates a new JSONServerCodec instance. Options can be
+// used to customize the advertised charset, how numbers are decoded and how
+// responses are encoded.
+func NewJSONServerCodec(opts ...JSONServerCodecOption) JSONServerCodec {
+	codec := JSONServerCodec{
+		contentType:        withCharset(jsonMediaType, defaultJSONCharset),
+		disallowTrailing:   false,
+		disableHTMLEscape:  false,
+		indent:             "",
+		problemContentType: withCharset(problemJSONMediaType, defaultJSONCharset),
+		useNumber:          false,
+	}
 
-// NewJSONServerCodec creates a new JSONServerCodec instance.
-func NewJSONServerCodec() JSONServerCodec {
-	return JSONServerCodec{}
+	for _, opt := range opts {
+		opt(&codec)
+	}
+
+	return codec
+}
+
+// ContentType returns the Content-Type header value used for successful
+// responses.
+func (c JSONServerCodec) ContentType() string {
+	if c.contentType == "" {
+		return withCharset(jsonMediaType, defaultJSONCharset)
+	}
+
+	return c.contentType
+}
+
+// ProblemContentType returns the Content-Type header value used for problem
+// responses.
+func (c JSONServerCodec) ProblemContentType() string {
+	if c.problemContentType == "" {
+		return withCharset(problemJSONMediaType, defaultJSONCharset)
+	}
+
+	return c.problemContentType
 }
 
 // Decode reads and decodes the JSON body of an HTTP request into the provided
-// target struct or variable. Returns an error if decoding fails or if the
-// request body is nil.
+// target struct or variable. A leading UTF-8 byte order mark, which some
+// clients prepend to JSON bodies, is ignored. Returns an error if decoding
+// fails or, when [WithJSONDisallowTrailingData] is used, if data follows the
+// JSON document.
 func (c JSONServerCodec) Decode(r *http.Request, into any) error {
 	if r.Body == nil {
 		return nil
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(into); err != nil {
+	dec := json.NewDecoder(skipBOM(r.Body))
+	if c.useNumber {
+		dec.UseNumber()
+	}
+
+	if err := dec.Decode(into); err != nil {
 		return fmt.Errorf("decoding request body as JSON: %w", err)
 	}
 
+	if c.disallowTrailing {
+		if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+			return fmt.Errorf("decoding request body as JSON: %w", ErrTrailingJSONData)
+		}
+	}
+
 	return nil
 }
 
 // Encode writes the given data as JSON to the provided HTTP response writer
-// with the appropriate Content-Type header.
+// with the appropriate Content-Type header. A json.RawMessage is written
+// verbatim without being re-encoded, so it must already be valid JSON; an
+// empty json.RawMessage is written as null.
 func (c JSONServerCodec) Encode(w http.ResponseWriter, statusCode int, data any) error {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Type", c.ContentType())
 	w.WriteHeader(statusCode)
 
-	return writeJSON(w, data)
+	if raw, ok := data.(json.RawMessage); ok {
+		return writeRawJSON(w, raw)
+	}
+
+	return c.writeJSON(w, data)
 }
 
 // EncodeError encodes an error into an HTTP response, handling
@@ -110,19 +270,48 @@ func (c JSONServerCodec) Encode(w http.ResponseWriter, statusCode int, data any)
 // falling back to standard JSON encoding otherwise.
 func (c JSONServerCodec) EncodeError(w http.ResponseWriter, statusCode int, err error) error {
 	if problemDetails, ok := errors.AsType[*problem.DetailedError](err); ok {
-		w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+		w.Header().Set("Content-Type", c.ProblemContentType())
 		w.WriteHeader(statusCode)
 
-		return writeJSON(w, problemDetails)
+		return c.writeJSON(w, problemDetails)
 	}
 
 	return c.Encode(w, statusCode, err)
 }
 
-// writeJSON writes the given data as JSON to the provided writer. It returns an
-// error if encoding fails.
-func writeJSON(w io.Writer, data any) error {
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+// withCharset appends the charset parameter to mediaType. An empty charset
+// returns mediaType unchanged.
+func withCharset(mediaType, charset string) string {
+	if charset == "" {
+		return mediaType
+	}
+
+	return mediaType + "; charset=" + charset
+}
+
+// writeRawJSON writes the given pre-encoded JSON to the provided writer as is.
+// It returns an error if writing fails.
+func writeRawJSON(w io.Writer, raw json.RawMessage) error {
+	if len(raw) == 0 {
+		raw = json.RawMessage("null")
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		return fmt.Errorf("writing raw JSON response data: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSON writes the given data as JSON to the provided writer using the
+// configured indentation and HTML escaping. It returns an error if encoding
+// fails.
+func (c JSONServerCodec) writeJSON(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!c.disableHTMLEscape)
+	enc.SetIndent("", c.indent)
+
+	if err := enc.Encode(data); err != nil {
 		return fmt.Errorf("encoding response data as JSON: %w", err)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"html/template"
 	"io"
+	"log/slog"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 	"github.com/go-playground/form/v4"
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/nickbryan/slogutil"
+
 	"github.com/nickbryan/httputil"
 	"github.com/nickbryan/httputil/internal/testutil"
 	"github.com/nickbryan/httputil/problem"
@@ -102,6 +105,42 @@ func TestJSONServerCodec_Decode(t *testing.T) {
 				Foo: "bar",
 			},
 		},
+		"decodes a json request body with a leading byte order mark": {
+			request: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("\ufeff"+`{"foo":"bar"}`)),
+			into:    &testStruct{},
+			wantErr: false,
+			wantIntoVal: &testStruct{
+				Foo: "bar",
+			},
+		},
+		"decodes a json request body with a byte order mark and leading whitespace": {
+			request: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("\ufeff\r\n  "+`{"foo":"bar"}`)),
+			into:    &testStruct{},
+			wantErr: false,
+			wantIntoVal: &testStruct{
+				Foo: "bar",
+			},
+		},
+		"decodes a json request body shorter than a byte order mark": {
+			request: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("1")),
+			into:    new(int),
+			wantErr: false,
+			wantIntoVal: func() *int {
+				v := 1
+				return &v
+			}(),
+		},
+		"returns an error for a byte order mark that is not at the start of the body": {
+			request: httptest.NewRequest(http.MethodPost, "/", strings.NewReader(" \ufeff"+`{"foo":"bar"}`)),
+			into:    &testStruct{},
+			wantErr: true,
+		},
+		"returns an io.EOF error for a body holding only a byte order mark": {
+			request:   httptest.NewRequest(http.MethodPost, "/", strings.NewReader("\ufeff")),
+			into:      &testStruct{},
+			wantErr:   true,
+			wantErrAs: io.EOF,
+		},
 		"returns no error for a nil request body": {
 			request: &http.Request{Body: nil},
 			into:    &testStruct{},
@@ -246,6 +285,333 @@ func TestJSONServerCodec_EncodeError(t *testing.T) {
 	}
 }
 
+func TestJSONServerCodec_EncodeRawMessage(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data     json.RawMessage
+		wantBody string
+	}{
+		"writes the raw message byte for byte": {
+			data:     json.RawMessage(`{"z": 1, "a": [true,  false], "html": "<b>"}`),
+			wantBody: `{"z": 1, "a": [true,  false], "html": "<b>"}`,
+		},
+		"writes an empty raw message as null": {
+			data:     nil,
+			wantBody: `null`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			if err := httputil.NewJSONServerCodec().Encode(w, http.StatusOK, tc.data); err != nil {
+				t.Fatalf("Encode() error = %v, want: nil", err)
+			}
+
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("Body = %q, want: %q", got, tc.wantBody)
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type header = %q, want: %q", contentType, "application/json; charset=utf-8")
+			}
+		})
+	}
+}
+
+func TestJSONServerCodec_WithJSONCharset(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		codec                  httputil.JSONServerCodec
+		wantContentType        string
+		wantProblemContentType string
+	}{
+		"the charset defaults to utf-8": {
+			codec:                  httputil.NewJSONServerCodec(),
+			wantContentType:        "application/json; charset=utf-8",
+			wantProblemContentType: "application/problem+json; charset=utf-8",
+		},
+		"the zero value codec uses the default charset": {
+			codec:                  httputil.JSONServerCodec{},
+			wantContentType:        "application/json; charset=utf-8",
+			wantProblemContentType: "application/problem+json; charset=utf-8",
+		},
+		"the charset can be customized": {
+			codec:                  httputil.NewJSONServerCodec(httputil.WithJSONCharset("UTF-8")),
+			wantContentType:        "application/json; charset=UTF-8",
+			wantProblemContentType: "application/problem+json; charset=UTF-8",
+		},
+		"the charset parameter is omitted when the charset is empty": {
+			codec:                  httputil.NewJSONServerCodec(httputil.WithJSONCharset("")),
+			wantContentType:        "application/json",
+			wantProblemContentType: "application/problem+json",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			err := tc.codec.Encode(w, http.StatusOK, map[string]string{"foo": "bar"})
+			assertResponse(t, w, err, false, http.StatusOK, tc.wantContentType, `{"foo":"bar"}`)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			w = httptest.NewRecorder()
+			err = tc.codec.EncodeError(w, http.StatusBadRequest, problem.BadRequest(req))
+			assertResponse(t, w, err, false, http.StatusBadRequest, tc.wantProblemContentType, problem.BadRequest(req).MustMarshalJSONString())
+		})
+	}
+}
+
+func TestJSONServerCodec_WithJSONUseNumber(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		codec    httputil.JSONServerCodec
+		wantID   any
+		wantBody string
+	}{
+		"large integers lose precision by default": {
+			codec:    httputil.NewJSONServerCodec(),
+			wantID:   float64(9007199254740992),
+			wantBody: `{"amount":1.5,"id":9007199254740992}` + "\n",
+		},
+		"large integers round trip exactly as json.Number when enabled": {
+			codec:    httputil.NewJSONServerCodec(httputil.WithJSONUseNumber()),
+			wantID:   json.Number("9007199254740993"),
+			wantBody: `{"amount":1.5,"id":9007199254740993}` + "\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var into map[string]any
+
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"id": 9007199254740993, "amount": 1.5}`))
+			if err := tc.codec.Decode(req, &into); err != nil {
+				t.Fatalf("Decode() error = %v, want: nil", err)
+			}
+
+			if into["id"] != tc.wantID {
+				t.Errorf("into[\"id\"] = %#v, want: %#v", into["id"], tc.wantID)
+			}
+
+			w := httptest.NewRecorder()
+			if err := tc.codec.Encode(w, http.StatusOK, into); err != nil {
+				t.Fatalf("Encode() error = %v, want: nil", err)
+			}
+
+			// Compare the raw body as decoding it for comparison would lose precision.
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("Body = %q, want: %q", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestJSONServerCodec_WithJSONDisallowTrailingData(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		codec   httputil.JSONServerCodec
+		body    string
+		wantErr error
+	}{
+		"trailing data is ignored by default": {
+			codec:   httputil.NewJSONServerCodec(),
+			body:    `{"name":"a"}{"name":"b"}`,
+			wantErr: nil,
+		},
+		"a second document is rejected when disallowed": {
+			codec:   httputil.NewJSONServerCodec(httputil.WithJSONDisallowTrailingData()),
+			body:    `{"name":"a"}{"name":"b"}`,
+			wantErr: httputil.ErrTrailingJSONData,
+		},
+		"trailing garbage is rejected when disallowed": {
+			codec:   httputil.NewJSONServerCodec(httputil.WithJSONDisallowTrailingData()),
+			body:    `{"name":"a"} garbage`,
+			wantErr: httputil.ErrTrailingJSONData,
+		},
+		"trailing whitespace is accepted when disallowed": {
+			codec:   httputil.NewJSONServerCodec(httputil.WithJSONDisallowTrailingData()),
+			body:    "{\"name\":\"a\"}\n\t ",
+			wantErr: nil,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var into struct {
+				Name string `json:"name"`
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tc.body))
+
+			err := tc.codec.Decode(req, &into)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Decode() error = %v, want: %v", err, tc.wantErr)
+			}
+
+			if into.Name != "a" {
+				t.Errorf("into.Name = %q, want: %q", into.Name, "a")
+			}
+		})
+	}
+
+	t.Run("handlers reject a body with two documents as a bad request", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger, httputil.WithServerCodec(httputil.NewJSONServerCodec(httputil.WithJSONDisallowTrailingData())))
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/test",
+			Handler: httputil.NewHandler(func(r httputil.RequestData[map[string]string]) (*httputil.Response, error) {
+				return httputil.OK(r.Data)
+			}),
+		})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"a"}{"name":"b"}`)))
+
+		if response.Code != http.StatusBadRequest {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+
+		want := problem.BadRequest(httptest.NewRequest(http.MethodPost, "/test", nil)).
+			WithDetail("The request body must contain a single JSON document").
+			MustMarshalJSONString()
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestJSONServerCodec_EncodeOptions(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{"url": "/search?q=a&b=<c>", "tags": []string{"x"}}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	testCases := map[string]struct {
+		codec            httputil.JSONServerCodec
+		wantBody         string
+		wantProblemStart string
+	}{
+		"the default encoding is compact with HTML escaped": {
+			codec:            httputil.NewJSONServerCodec(),
+			wantBody:         `{"tags":["x"],"url":"/search?q=a\u0026b=\u003cc\u003e"}` + "\n",
+			wantProblemStart: `{"code":`,
+		},
+		"the zero value codec uses the default encoding": {
+			codec:            httputil.JSONServerCodec{},
+			wantBody:         `{"tags":["x"],"url":"/search?q=a\u0026b=\u003cc\u003e"}` + "\n",
+			wantProblemStart: `{"code":`,
+		},
+		"the output is indented when configured": {
+			codec:            httputil.NewJSONServerCodec(httputil.WithJSONIndent("  ")),
+			wantBody:         "{\n  \"tags\": [\n    \"x\"\n  ],\n  \"url\": \"/search?q=a\\u0026b=\\u003cc\\u003e\"\n}\n",
+			wantProblemStart: "{\n  \"code\":",
+		},
+		"HTML escaping is disabled when configured": {
+			codec:            httputil.NewJSONServerCodec(httputil.WithJSONEscapeHTML(false)),
+			wantBody:         `{"tags":["x"],"url":"/search?q=a&b=<c>"}` + "\n",
+			wantProblemStart: `{"code":`,
+		},
+		"HTML escaping can be explicitly enabled": {
+			codec:            httputil.NewJSONServerCodec(httputil.WithJSONEscapeHTML(false), httputil.WithJSONEscapeHTML(true)),
+			wantBody:         `{"tags":["x"],"url":"/search?q=a\u0026b=\u003cc\u003e"}` + "\n",
+			wantProblemStart: `{"code":`,
+		},
+		"the options can be combined": {
+			codec:            httputil.NewJSONServerCodec(httputil.WithJSONIndent("\t"), httputil.WithJSONEscapeHTML(false)),
+			wantBody:         "{\n\t\"tags\": [\n\t\t\"x\"\n\t],\n\t\"url\": \"/search?q=a&b=<c>\"\n}\n",
+			wantProblemStart: "{\n\t\"code\":",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			if err := tc.codec.Encode(w, http.StatusOK, data); err != nil {
+				t.Fatalf("Encode() error = %v, want: nil", err)
+			}
+
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("Encode() body = %q, want: %q", got, tc.wantBody)
+			}
+
+			w = httptest.NewRecorder()
+			if err := tc.codec.EncodeError(w, http.StatusBadRequest, problem.BadRequest(req)); err != nil {
+				t.Fatalf("EncodeError() error = %v, want: nil", err)
+			}
+
+			if got := w.Body.String(); !strings.HasPrefix(got, tc.wantProblemStart) {
+				t.Errorf("EncodeError() body = %q, want prefix: %q", got, tc.wantProblemStart)
+			}
+		})
+	}
+}
+
+func TestServerCodec_EncodeByStatus(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		response   *httputil.Response
+		wantStatus int
+		wantBody   string
+	}{
+		"success data is wrapped in a data member": {
+			response:   httputil.NewResponse(http.StatusOK, map[string]string{"name": "alice"}),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"name":"alice"}}`,
+		},
+		"non-2xx data is wrapped in an error member": {
+			response:   httputil.NewResponse(http.StatusConflict, map[string]string{"reason": "name taken"}),
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":{"reason":"name taken"}}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, httputil.WithServerCodec(envelopeServerCodec{JSONServerCodec: httputil.NewJSONServerCodec()}))
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return testCase.response, nil
+				}),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestHTMLServerCodec_Decode(t *testing.T) {
 	t.Parallel()
 
@@ -892,6 +1258,20 @@ func newFormRequest(body string) *http.Request {
 	return req
 }
 
+// envelopeServerCodec is a ServerCodec that wraps response data in a "data"
+// member for 2xx responses and an "error" member for any other status.
+type envelopeServerCodec struct {
+	httputil.JSONServerCodec
+}
+
+func (c envelopeServerCodec) Encode(w http.ResponseWriter, statusCode int, data any) error {
+	if statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices {
+		return c.JSONServerCodec.Encode(w, statusCode, map[string]any{"data": data})
+	}
+
+	return c.JSONServerCodec.Encode(w, statusCode, map[string]any{"error": data})
+}
+
 type jsonUnsupportedError struct {
 	err any
 }
@@ -0,0 +1,127 @@
+package httputil
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// Preconditions holds the entity tags of the If-Match and If-None-Match headers
+// of a request, as defined by RFC 9110. A tag of "*" matches any current
+// representation of the resource.
+type Preconditions struct {
+	// IfMatch holds the entity tags of the If-Match header, in order.
+	IfMatch []string
+	// IfNoneMatch holds the entity tags of the If-None-Match header, in order.
+	IfNoneMatch []string
+}
+
+// ParsePreconditions parses the If-Match and If-None-Match headers of r. Each
+// header may be repeated and may hold a comma separated list of entity tags.
+func ParsePreconditions(r *http.Request) Preconditions {
+	return Preconditions{
+		IfMatch:     parseEntityTags(r.Header.Values("If-Match")),
+		IfNoneMatch: parseEntityTags(r.Header.Values("If-None-Match")),
+	}
+}
+
+// Present reports whether the request has an If-Match or If-None-Match header.
+func (p Preconditions) Present() bool {
+	return len(p.IfMatch) > 0 || len(p.IfNoneMatch) > 0
+}
+
+// CreateOnly reports whether the request has an "If-None-Match: *" header,
+// asking for the resource to be created only if it does not already exist.
+func (p Preconditions) CreateOnly() bool {
+	return slices.Contains(p.IfNoneMatch, "*")
+}
+
+// Check evaluates the preconditions against the current entity tag of the
+// resource, formatted as in an ETag header such as `"v1"` or `W/"v1"`. An
+// empty etag means that the resource does not exist. It reports false if:
+//   - If-Match is "*" and the resource does not exist.
+//   - If-Match lists tags and none of them strongly matches etag.
+//   - If-None-Match is "*" and the resource exists.
+//   - If-None-Match lists a tag that weakly matches etag.
+//
+// Check is intended for state changing requests; GET and HEAD requests that
+// fail If-None-Match should be answered with 304 Not Modified instead.
+func (p Preconditions) Check(etag string) bool {
+	if len(p.IfMatch) > 0 && !slices.ContainsFunc(p.IfMatch, func(tag string) bool {
+		return (tag == "*" && etag != "") || strongETagMatch(tag, etag)
+	}) {
+		return false
+	}
+
+	if slices.ContainsFunc(p.IfNoneMatch, func(tag string) bool {
+		return (tag == "*" && etag != "") || weakETagMatch(tag, etag)
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// CheckPreconditions evaluates the If-Match and If-None-Match headers of r
+// against etag, the current entity tag of the resource or an empty string if
+// it does not exist, as described by [Preconditions.Check]. It returns a
+// [problem.PreconditionFailed] error when a precondition fails, which an
+// Action can return as is, and nil otherwise.
+//
+// Combined with "If-None-Match: *", this gives create-if-absent semantics:
+//
+//	if err := httputil.CheckPreconditions(r.Request, store.ETag(id)); err != nil {
+//	    return nil, err
+//	}
+func CheckPreconditions(r *http.Request, etag string) error {
+	if ParsePreconditions(r).Check(etag) {
+		return nil
+	}
+
+	return problem.PreconditionFailed(r)
+}
+
+// NewPreconditionRequiredGuard creates a Guard that rejects requests without an
+// If-Match or If-None-Match header with a [problem.PreconditionRequired]
+// error. It protects state changing endpoints from lost updates by requiring
+// clients to state the version of the resource that they expect. Use
+// [GuardWhen] to apply it to specific methods only.
+func NewPreconditionRequiredGuard() GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		if !ParsePreconditions(r).Present() {
+			return nil, problem.PreconditionRequired(r)
+		}
+
+		return r, nil
+	}
+}
+
+// parseEntityTags parses the comma separated entity tags of the given header
+// values, dropping empty entries.
+func parseEntityTags(values []string) []string {
+	var tags []string
+
+	for _, value := range values {
+		for tag := range strings.SplitSeq(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// strongETagMatch reports whether a and b are the same strong entity tag.
+func strongETagMatch(a, b string) bool {
+	return a != "" && a == b && !strings.HasPrefix(a, "W/")
+}
+
+// weakETagMatch reports whether a and b are the same entity tag, ignoring
+// whether either is weak.
+func weakETagMatch(a, b string) bool {
+	a, b = strings.TrimPrefix(a, "W/"), strings.TrimPrefix(b, "W/")
+	return a != "" && a == b
+}
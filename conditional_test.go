@@ -0,0 +1,225 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestParsePreconditions(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+	request.Header.Add("If-Match", `"a", W/"b"`)
+	request.Header.Add("If-Match", `"c"`)
+	request.Header.Set("If-None-Match", "*")
+
+	got := httputil.ParsePreconditions(request)
+	want := httputil.Preconditions{IfMatch: []string{`"a"`, `W/"b"`, `"c"`}, IfNoneMatch: []string{"*"}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParsePreconditions() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !got.Present() || !got.CreateOnly() {
+		t.Errorf("Present() = %t, CreateOnly() = %t, want: true, true", got.Present(), got.CreateOnly())
+	}
+}
+
+func TestPreconditions_Check(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		header http.Header
+		etag   string
+		want   bool
+	}{
+		"no preconditions always hold": {
+			header: http.Header{},
+			etag:   `"v1"`,
+			want:   true,
+		},
+		"if-none-match any holds when the resource does not exist": {
+			header: http.Header{"If-None-Match": {"*"}},
+			etag:   "",
+			want:   true,
+		},
+		"if-none-match any fails when the resource exists": {
+			header: http.Header{"If-None-Match": {"*"}},
+			etag:   `"v1"`,
+			want:   false,
+		},
+		"if-none-match fails on a weak match": {
+			header: http.Header{"If-None-Match": {`W/"v1"`}},
+			etag:   `"v1"`,
+			want:   false,
+		},
+		"if-match holds on a strong match": {
+			header: http.Header{"If-Match": {`"v0", "v1"`}},
+			etag:   `"v1"`,
+			want:   true,
+		},
+		"if-match fails on a stale tag": {
+			header: http.Header{"If-Match": {`"v0"`}},
+			etag:   `"v1"`,
+			want:   false,
+		},
+		"if-match fails on a weak tag": {
+			header: http.Header{"If-Match": {`W/"v1"`}},
+			etag:   `W/"v1"`,
+			want:   false,
+		},
+		"if-match any holds when the resource exists": {
+			header: http.Header{"If-Match": {"*"}},
+			etag:   `"v1"`,
+			want:   true,
+		},
+		"if-match any fails when the resource does not exist": {
+			header: http.Header{"If-Match": {"*"}},
+			etag:   "",
+			want:   false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+			request.Header = testCase.header
+
+			if got := httputil.ParsePreconditions(request).Check(testCase.etag); got != testCase.want {
+				t.Errorf("Check(%q) = %t, want: %t", testCase.etag, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("create if absent only creates the resource once", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu     sync.Mutex
+			orders = map[string]string{}
+		)
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPut,
+			Path:   "/orders/{id}",
+			Handler: httputil.NewHandler(func(r httputil.RequestData[map[string]string]) (*httputil.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				id := r.PathValue("id")
+
+				var etag string
+				if _, ok := orders[id]; ok {
+					etag = `"` + orders[id] + `"`
+				}
+
+				if err := httputil.CheckPreconditions(r.Request, etag); err != nil {
+					return nil, err
+				}
+
+				orders[id] = r.Data["item"]
+
+				return httputil.CreatedResource("/orders/"+id, r.Data)
+			}),
+		})
+
+		send := func() *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPut, "/orders/1", strings.NewReader(`{"item":"book"}`))
+			request.Header.Set("If-None-Match", "*")
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			return response
+		}
+
+		if response := send(); response.Code != http.StatusCreated {
+			t.Fatalf("first response.Code = %d, want: %d", response.Code, http.StatusCreated)
+		}
+
+		response := send()
+		if response.Code != http.StatusPreconditionFailed {
+			t.Fatalf("second response.Code = %d, want: %d", response.Code, http.StatusPreconditionFailed)
+		}
+
+		want := problem.PreconditionFailed(httptest.NewRequest(http.MethodPut, "/orders/1", nil)).MustMarshalJSONString()
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestNewPreconditionRequiredGuard(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		header     http.Header
+		wantStatus int
+	}{
+		"a request without a precondition is rejected": {
+			header:     http.Header{},
+			wantStatus: http.StatusPreconditionRequired,
+		},
+		"a request with if-match is accepted": {
+			header:     http.Header{"If-Match": {`"v1"`}},
+			wantStatus: http.StatusNoContent,
+		},
+		"a request with if-none-match is accepted": {
+			header:     http.Header{"If-None-Match": {"*"}},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPut,
+				Path:   "/orders/{id}",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.NoContent()
+				}, httputil.WithHandlerGuard(httputil.NewPreconditionRequiredGuard())),
+			})
+
+			request := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if testCase.wantStatus == http.StatusPreconditionRequired {
+				want := problem.PreconditionRequired(request).MustMarshalJSONString()
+				if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package httputil
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// contentLengthMismatchDetail is the problem detail used when the request body
+// does not match its Content-Length header.
+const contentLengthMismatchDetail = "The request body length does not match the Content-Length header"
+
+// ErrContentLengthMismatch is returned when reading a request body, checked by
+// the middleware created with [NewContentLengthMiddleware], that is shorter or
+// longer than its Content-Length header. Actions and guards that return it, or
+// wrap it, produce a [problem.BadRequest] response.
+var ErrContentLengthMismatch = errors.New("request body length does not match Content-Length")
+
+// NewContentLengthMiddleware creates a MiddlewareFunc that checks the request
+// body against its declared Content-Length as it is read. Reading a body that
+// ends before the declared length, or continues past it, returns an error
+// wrapping [ErrContentLengthMismatch], which handlers created by [NewHandler]
+// or [NewFormHandler] report as a [problem.BadRequest].
+//
+// Requests without a declared length, such as chunked requests, and requests
+// with an empty body are passed through unchanged.
+func NewContentLengthMiddleware() MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > 0 && r.Body != nil && r.Body != http.NoBody {
+				r.Body = &contentLengthReader{ReadCloser: r.Body, remaining: r.ContentLength}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// contentLengthReader is an io.ReadCloser that reports an error wrapping
+// ErrContentLengthMismatch if the underlying io.ReadCloser does not hold exactly
+// remaining bytes.
+type contentLengthReader struct {
+	io.ReadCloser
+
+	remaining int64
+}
+
+// Read reads from the underlying io.ReadCloser, returning at most the remaining
+// declared bytes.
+func (rc *contentLengthReader) Read(p []byte) (int, error) {
+	n, err := rc.ReadCloser.Read(p)
+
+	if int64(n) > rc.remaining {
+		n, err = int(rc.remaining), ErrContentLengthMismatch
+	}
+
+	rc.remaining -= int64(n)
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || (errors.Is(err, io.EOF) && rc.remaining > 0) {
+		err = ErrContentLengthMismatch
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned unwrapped.
+}
@@ -0,0 +1,120 @@
+package httputil_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewContentLengthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	type requestData struct {
+		Name string `json:"name"`
+	}
+
+	decodeBody := httputil.NewHandler(func(r httputil.RequestData[requestData]) (*httputil.Response, error) {
+		return httputil.OK(r.Data)
+	})
+
+	readBody := httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("reading body: %w", err)
+		}
+
+		return httputil.OK(map[string]string{"body": string(body)})
+	})
+
+	mismatch := problem.BadRequest(httptest.NewRequest(http.MethodPost, "/test", nil)).
+		WithDetail("The request body length does not match the Content-Length header").
+		MustMarshalJSONString()
+
+	testCases := map[string]struct {
+		handler       http.Handler
+		body          string
+		contentLength int64
+		wantStatus    int
+		wantBody      string
+	}{
+		"a body matching the content length is passed through": {
+			handler:       decodeBody,
+			body:          `{"name":"test"}`,
+			contentLength: 15,
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"name":"test"}`,
+		},
+		"a truncated body is rejected": {
+			handler:       decodeBody,
+			body:          `{"name":"te`,
+			contentLength: 15,
+			wantStatus:    http.StatusBadRequest,
+			wantBody:      mismatch,
+		},
+		"a chunked body is not checked": {
+			handler:       decodeBody,
+			body:          `{"name":"test"}`,
+			contentLength: -1,
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"name":"test"}`,
+		},
+		"a body read to the end by an action is passed through": {
+			handler:       readBody,
+			body:          "hello",
+			contentLength: 5,
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"body":"hello"}`,
+		},
+		"a truncated body read by an action is rejected": {
+			handler:       readBody,
+			body:          "hel",
+			contentLength: 5,
+			wantStatus:    http.StatusBadRequest,
+			wantBody:      mismatch,
+		},
+		"a padded body read by an action is rejected": {
+			handler:       readBody,
+			body:          "hello world",
+			contentLength: 5,
+			wantStatus:    http.StatusBadRequest,
+			wantBody:      mismatch,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodPost, Path: "/test", Handler: testCase.handler},
+			}.WithMiddleware(httputil.NewContentLengthMiddleware())...)
+
+			request := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(testCase.body))
+			request.ContentLength = testCase.contentLength
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
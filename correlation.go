@@ -0,0 +1,88 @@
+package httputil
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+)
+
+// maxCorrelationIDLength is the length above which a correlation ID sent by a
+// client is replaced, so that it can not be used to bloat logs.
+const maxCorrelationIDLength = 128
+
+// correlationIDCtxKey is the context key for the correlation ID of a request.
+type correlationIDCtxKey struct{}
+
+// NewCorrelationMiddleware creates a MiddlewareFunc that reads the correlation
+// ID of each request from header and stores it in the request context, where it
+// can be read with [CorrelationIDFromContext] and is propagated to downstream
+// requests by [NewCorrelationInterceptor]. A new ID is generated for requests
+// that do not send one, or that send one longer than 128 characters or with
+// characters other than visible ASCII. The ID is also set on header of the
+// response, so that clients can refer to it.
+func NewCorrelationMiddleware(header string) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validCorrelationID(id) {
+				id = rand.Text()
+			}
+
+			w.Header().Set(header, id)
+
+			next.ServeHTTP(w, r.WithContext(WithCorrelationID(r.Context(), id)))
+		})
+	}
+}
+
+// NewCorrelationInterceptor creates an InterceptorFunc that sets header on each
+// outgoing request to the correlation ID stored in the request context, such
+// as by [NewCorrelationMiddleware] when the context of an inbound request is
+// passed to the Client. Requests whose context has no correlation ID, or that
+// already set header, are sent unchanged. The request passed to the
+// interceptor is not modified.
+func NewCorrelationInterceptor(header string) InterceptorFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			id := CorrelationIDFromContext(req.Context())
+			if id == "" || req.Header.Get(header) != "" {
+				return next.RoundTrip(req) //nolint:wrapcheck // Errors from the transport are returned as is.
+			}
+
+			correlated := req.Clone(req.Context())
+			correlated.Header.Set(header, id)
+
+			return next.RoundTrip(correlated) //nolint:wrapcheck // Errors from the transport are returned as is.
+		})
+	}
+}
+
+// WithCorrelationID returns a copy of ctx that carries the correlation ID id,
+// for example to correlate the requests made by a background job.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDCtxKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx by
+// [NewCorrelationMiddleware] or [WithCorrelationID], or an empty string if
+// there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDCtxKey{}).(string)
+	return id
+}
+
+// validCorrelationID reports whether id is a non-empty correlation ID of at
+// most maxCorrelationIDLength visible ASCII characters.
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
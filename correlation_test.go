@@ -0,0 +1,197 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestCorrelation(t *testing.T) {
+	t.Parallel()
+
+	const header = "X-Correlation-ID"
+
+	testCases := map[string]struct {
+		inboundID    string
+		wantInbound  bool
+		wantOutbound func(t *testing.T, id string)
+	}{
+		"the inbound correlation ID flows to the outbound request": {
+			inboundID:   "abc-123",
+			wantInbound: true,
+			wantOutbound: func(t *testing.T, id string) {
+				t.Helper()
+
+				if id != "abc-123" {
+					t.Errorf("outbound %s = %q, want: %q", header, id, "abc-123")
+				}
+			},
+		},
+		"a correlation ID is generated for a request without one": {
+			inboundID:   "",
+			wantInbound: false,
+			wantOutbound: func(t *testing.T, id string) {
+				t.Helper()
+
+				if id == "" {
+					t.Errorf("outbound %s is empty, want a generated ID", header)
+				}
+			},
+		},
+		"a correlation ID is generated for a request with an invalid one": {
+			inboundID:   strings.Repeat("a", 129),
+			wantInbound: false,
+			wantOutbound: func(t *testing.T, id string) {
+				t.Helper()
+
+				if id == "" || id == strings.Repeat("a", 129) {
+					t.Errorf("outbound %s = %q, want a generated ID", header, id)
+				}
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			outbound := make(chan string, 1)
+
+			downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				outbound <- r.Header.Get(header)
+
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			t.Cleanup(downstream.Close)
+
+			client := httputil.NewClient(
+				httputil.WithClientBasePath(downstream.URL),
+				httputil.WithClientInterceptor(httputil.NewCorrelationInterceptor(header)),
+			)
+
+			var handlerID string
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.EndpointGroup{
+				{
+					Method: http.MethodGet,
+					Path:   "/orders",
+					Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+						handlerID = httputil.CorrelationIDFromContext(r.Context())
+
+						resp, err := client.Get(r.Context(), "/inventory")
+						if err != nil {
+							return nil, err
+						}
+
+						_ = resp.Body.Close()
+
+						return httputil.NoContent()
+					}),
+				},
+			}.WithMiddleware(httputil.NewCorrelationMiddleware(header))...)
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if testCase.inboundID != "" {
+				request.Header.Set(header, testCase.inboundID)
+			}
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusNoContent {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusNoContent)
+			}
+
+			outboundID := <-outbound
+			testCase.wantOutbound(t, outboundID)
+
+			if handlerID != outboundID {
+				t.Errorf("CorrelationIDFromContext() = %q, want the outbound ID: %q", handlerID, outboundID)
+			}
+
+			if got := response.Header().Get(header); got != outboundID {
+				t.Errorf("response %s = %q, want the outbound ID: %q", header, got, outboundID)
+			}
+
+			if got := outboundID == testCase.inboundID; got != testCase.wantInbound {
+				t.Errorf("outbound ID equals inbound ID = %t, want: %t", got, testCase.wantInbound)
+			}
+		})
+	}
+}
+
+func TestNewCorrelationInterceptor(t *testing.T) {
+	t.Parallel()
+
+	const header = "X-Correlation-ID"
+
+	testCases := map[string]struct {
+		ctx        context.Context
+		header     string
+		wantHeader string
+	}{
+		"sets the header from the context": {
+			ctx:        httputil.WithCorrelationID(context.Background(), "abc-123"),
+			header:     "",
+			wantHeader: "abc-123",
+		},
+		"does not set the header when the context has no correlation ID": {
+			ctx:        context.Background(),
+			header:     "",
+			wantHeader: "",
+		},
+		"does not replace a header that is already set": {
+			ctx:        httputil.WithCorrelationID(context.Background(), "abc-123"),
+			header:     "explicit",
+			wantHeader: "explicit",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var gotHeader string
+
+			transport := httputil.NewCorrelationInterceptor(header)(
+				httputil.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					gotHeader = r.Header.Get(header)
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				}),
+			)
+
+			request, err := http.NewRequestWithContext(testCase.ctx, http.MethodGet, "http://example.com/inventory", nil)
+			if err != nil {
+				t.Fatalf("unexpected error creating request: %v", err)
+			}
+
+			if testCase.header != "" {
+				request.Header.Set(header, testCase.header)
+			}
+
+			resp, err := transport.RoundTrip(request)
+			if err != nil {
+				t.Fatalf("unexpected error from RoundTrip: %v", err)
+			}
+
+			_ = resp.Body.Close()
+
+			if gotHeader != testCase.wantHeader {
+				t.Errorf("outbound %s = %q, want: %q", header, gotHeader, testCase.wantHeader)
+			}
+
+			if testCase.header == "" && request.Header.Get(header) != "" {
+				t.Error("the interceptor modified the original request")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// DebugInfo is the payload served by the endpoint registered with
+// [Server.RegisterDebugInfo].
+type DebugInfo struct {
+	// Module is the path of the main module of the running binary.
+	Module string `json:"module"`
+	// Version is the version of the main module of the running binary.
+	Version string `json:"version"`
+	// Revision is the VCS revision the binary was built from, if known.
+	Revision string `json:"revision"`
+	// GoVersion is the version of Go the binary was built with.
+	GoVersion string `json:"goVersion"`
+	// Uptime is the time elapsed since the Server was created.
+	Uptime string `json:"uptime"`
+	// Goroutines is the number of goroutines that currently exist.
+	Goroutines int `json:"goroutines"`
+}
+
+// RegisterDebugInfo registers a GET /debug/info endpoint that responds with
+// [DebugInfo] describing the build and runtime of the service. The endpoint is
+// protected by guard as it exposes internal details; RegisterDebugInfo panics if
+// guard is nil. Use [PublicAccessGuard] to expose it without protection.
+func (s *Server) RegisterDebugInfo(guard Guard) {
+	if guard == nil {
+		panic("httputil: RegisterDebugInfo requires a guard")
+	}
+
+	s.Register(NewEndpointWithGuard(Endpoint{
+		Method: http.MethodGet,
+		Path:   "/debug/info",
+		Handler: NewHandler(func(_ RequestEmpty) (*Response, error) {
+			return OK(s.debugInfo())
+		}),
+		Examples: nil,
+	}, guard))
+}
+
+// debugInfo collects the DebugInfo for the running binary.
+func (s *Server) debugInfo() DebugInfo {
+	info := DebugInfo{
+		Module:     "",
+		Version:    "",
+		Revision:   "",
+		GoVersion:  runtime.Version(),
+		Uptime:     time.Since(s.startedAt).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		info.Module = buildInfo.Main.Path
+		info.Version = buildInfo.Main.Version
+
+		for _, setting := range buildInfo.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Revision = setting.Value
+			}
+		}
+	}
+
+	return info
+}
+
+// RegisterPprof registers the standard net/http/pprof handlers under
+// /debug/pprof/ through the Server's pipeline, protected by guard. Profiles
+// expose internal details and can be expensive to collect, so RegisterPprof
+// panics if guard is nil. Use [PublicAccessGuard] to expose them without
+// protection.
+//
+// The CPU profile and execution trace handlers collect data for the number of
+// seconds given by the seconds query parameter, 30 and 1 by default. They
+// extend the write deadline of their response by that duration through
+// http.ResponseController, so profiles are not cut short by the write timeout
+// of the Server.
+func (s *Server) RegisterPprof(guard Guard) {
+	if guard == nil {
+		panic("httputil: RegisterPprof requires a guard")
+	}
+
+	endpoints := EndpointGroup{
+		{Method: http.MethodGet, Path: "/debug/pprof/", Handler: WrapNetHTTPHandlerFunc(pprof.Index), Examples: nil},
+		{Method: http.MethodGet, Path: "/debug/pprof/cmdline", Handler: WrapNetHTTPHandlerFunc(pprof.Cmdline), Examples: nil},
+		{Method: http.MethodGet, Path: "/debug/pprof/profile", Handler: WrapNetHTTPHandlerFunc(pprof.Profile), Examples: nil},
+		{Method: http.MethodGet, Path: "/debug/pprof/symbol", Handler: WrapNetHTTPHandlerFunc(pprof.Symbol), Examples: nil},
+		{Method: http.MethodPost, Path: "/debug/pprof/symbol", Handler: WrapNetHTTPHandlerFunc(pprof.Symbol), Examples: nil},
+		{Method: http.MethodGet, Path: "/debug/pprof/trace", Handler: WrapNetHTTPHandlerFunc(pprof.Trace), Examples: nil},
+	}
+
+	s.Register(endpoints.WithGuard(guard)...)
+}
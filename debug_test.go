@@ -0,0 +1,207 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/problem"
+	"github.com/nickbryan/httputil/problem/problemtest"
+)
+
+func opsTokenGuard(r *http.Request) (*http.Request, error) {
+	if r.Header.Get("Authorization") != "Bearer ops" {
+		return nil, problem.Unauthorized(r)
+	}
+
+	return r, nil
+}
+
+func TestServer_RegisterDebugInfo(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(t *testing.T) *httputil.Server {
+		t.Helper()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.RegisterDebugInfo(httputil.GuardFunc(opsTokenGuard))
+
+		return server
+	}
+
+	t.Run("responds with build and runtime info", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/debug/info", nil)
+		request.Header.Set("Authorization", "Bearer ops")
+
+		response := httptest.NewRecorder()
+		newServer(t).ServeHTTP(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusOK)
+		}
+
+		var info httputil.DebugInfo
+		if err := json.Unmarshal(response.Body.Bytes(), &info); err != nil {
+			t.Fatalf("unable to decode response body: %+v", err)
+		}
+
+		if info.GoVersion != runtime.Version() {
+			t.Errorf("info.GoVersion = %s, want: %s", info.GoVersion, runtime.Version())
+		}
+
+		if info.Goroutines < 1 {
+			t.Errorf("info.Goroutines = %d, want: > 0", info.Goroutines)
+		}
+
+		if info.Uptime == "" {
+			t.Error("info.Uptime is empty")
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(response.Body.Bytes(), &fields); err != nil {
+			t.Fatalf("unable to decode response body: %+v", err)
+		}
+
+		for _, field := range []string{"module", "version", "revision", "goVersion", "uptime", "goroutines"} {
+			if _, ok := fields[field]; !ok {
+				t.Errorf("response body is missing field %q: %s", field, response.Body.String())
+			}
+		}
+	})
+
+	t.Run("enforces the guard", func(t *testing.T) {
+		t.Parallel()
+
+		response := httptest.NewRecorder()
+		newServer(t).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/debug/info", nil))
+
+		problemtest.AssertProblem(t, response.Body.Bytes(), http.StatusUnauthorized, "401-01")
+	})
+
+	t.Run("panics without a guard", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected RegisterDebugInfo to panic without a guard")
+			}
+		}()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		httputil.NewServer(logger).RegisterDebugInfo(nil)
+	})
+}
+
+func TestServer_RegisterPprof(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(t *testing.T, guard httputil.Guard) *httputil.Server {
+		t.Helper()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.RegisterPprof(guard)
+
+		return server
+	}
+
+	t.Run("the index handler responds", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		request.Header.Set("Authorization", "Bearer ops")
+
+		response := httptest.NewRecorder()
+		newServer(t, httputil.GuardFunc(opsTokenGuard)).ServeHTTP(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusOK)
+		}
+
+		if !strings.Contains(response.Body.String(), "goroutine") {
+			t.Errorf("response.Body does not list the goroutine profile: %s", response.Body.String())
+		}
+	})
+
+	t.Run("the guard blocks unauthenticated access", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, httputil.GuardFunc(opsTokenGuard))
+
+		for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/cmdline", "/debug/pprof/symbol"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+
+			problemtest.AssertProblem(t, response.Body.Bytes(), http.StatusUnauthorized, "401-01")
+		}
+	})
+
+	t.Run("profiles can be exposed publicly by explicitly opting in", func(t *testing.T) {
+		t.Parallel()
+
+		response := httptest.NewRecorder()
+		newServer(t, httputil.PublicAccessGuard()).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil))
+
+		if response.Code != http.StatusOK {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("collects a profile that is not shorter than the write timeout", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger, httputil.WithServerWriteTimeout(time.Second))
+		server.RegisterPprof(httputil.PublicAccessGuard())
+
+		netHTTPServer, ok := server.Listener.(*http.Server)
+		if !ok {
+			t.Fatal("listener is not a http.Server")
+		}
+
+		testServer := httptest.NewUnstartedServer(server)
+		testServer.Config = netHTTPServer
+		testServer.Start()
+		t.Cleanup(testServer.Close)
+
+		response, err := testServer.Client().Get(testServer.URL + "/debug/pprof/profile?seconds=1")
+		if err != nil {
+			t.Fatalf("unexpected error requesting profile: %v", err)
+		}
+
+		defer func() { _ = response.Body.Close() }()
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatalf("unexpected error reading profile: %v", err)
+		}
+
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("response.StatusCode = %d, want: %d, body: %s", response.StatusCode, http.StatusOK, body)
+		}
+	})
+
+	t.Run("panics without a guard", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected RegisterPprof to panic without a guard")
+			}
+		}()
+
+		newServer(t, nil)
+	})
+}
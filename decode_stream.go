@@ -0,0 +1,61 @@
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotJSONArray is returned by [DecodeStream] when the input is not a JSON
+// array.
+var ErrNotJSONArray = errors.New("input is not a JSON array")
+
+// DecodeStream decodes a JSON array from r one element at a time, calling fn
+// with each element in order. Only a single element is held in memory at once
+// which makes DecodeStream suitable for ingesting large arrays. Decoding stops
+// at the first error returned by fn.
+//
+// Use DecodeStream with a [Request] that has no Data so that the handler does
+// not decode the body itself:
+//
+//	httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+//		err := httputil.DecodeStream(r.Body, func(event Event) error {
+//			return store.Save(r.Context(), event)
+//		})
+//		...
+//	})
+//
+// When reading a request body served by a [Server], the max body size is
+// enforced and an error wrapping *http.MaxBytesError is returned once it has
+// been exceeded. Elements handled before the limit was reached are not
+// rolled back.
+func DecodeStream[T any](r io.Reader, fn func(T) error) error {
+	dec := json.NewDecoder(r)
+
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("reading start of JSON array: %w", err)
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w, got: %v", ErrNotJSONArray, token)
+	}
+
+	for index := 0; dec.More(); index++ {
+		var element T
+		if err = dec.Decode(&element); err != nil {
+			return fmt.Errorf("decoding JSON array element %d: %w", index, err)
+		}
+
+		if err = fn(element); err != nil {
+			return fmt.Errorf("handling JSON array element %d: %w", index, err)
+		}
+	}
+
+	if _, err = dec.Token(); err != nil {
+		return fmt.Errorf("reading end of JSON array: %w", err)
+	}
+
+	return nil
+}
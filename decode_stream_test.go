@@ -0,0 +1,193 @@
+package httputil_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+type streamedEvent struct {
+	ID int `json:"id"`
+}
+
+func TestDecodeStream(t *testing.T) {
+	t.Parallel()
+
+	errStop := errors.New("stop")
+
+	testCases := map[string]struct {
+		input     string
+		fn        func(handled *[]int) func(streamedEvent) error
+		wantIDs   []int
+		wantErr   bool
+		wantErrIs error
+	}{
+		"an empty array handles no elements": {
+			input:   `[]`,
+			wantIDs: nil,
+		},
+		"each element is handled in order": {
+			input:   `[{"id": 1}, {"id": 2}, {"id": 3}]`,
+			wantIDs: []int{1, 2, 3},
+		},
+		"an error from the callback stops decoding": {
+			input: `[{"id": 1}, {"id": 2}, {"id": 3}]`,
+			fn: func(handled *[]int) func(streamedEvent) error {
+				return func(event streamedEvent) error {
+					if event.ID == 2 {
+						return errStop
+					}
+
+					*handled = append(*handled, event.ID)
+
+					return nil
+				}
+			},
+			wantIDs:   []int{1},
+			wantErr:   true,
+			wantErrIs: errStop,
+		},
+		"input that is not an array returns an error": {
+			input:     `{"id": 1}`,
+			wantErr:   true,
+			wantErrIs: httputil.ErrNotJSONArray,
+		},
+		"empty input returns an error": {
+			input:     ``,
+			wantErr:   true,
+			wantErrIs: io.EOF,
+		},
+		"elements handled before a malformed element are kept": {
+			input:   `[{"id": 1}, {"id": "two"}]`,
+			wantIDs: []int{1},
+			wantErr: true,
+		},
+		"an unterminated array returns an error": {
+			input:   `[{"id": 1}`,
+			wantIDs: []int{1},
+			wantErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var handled []int
+
+			fn := func(event streamedEvent) error {
+				handled = append(handled, event.ID)
+				return nil
+			}
+
+			if testCase.fn != nil {
+				fn = testCase.fn(&handled)
+			}
+
+			err := httputil.DecodeStream(strings.NewReader(testCase.input), fn)
+
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("DecodeStream() error = %v, wantErr: %t", err, testCase.wantErr)
+			}
+
+			if testCase.wantErrIs != nil && !errors.Is(err, testCase.wantErrIs) {
+				t.Errorf("DecodeStream() error = %v, want: %v", err, testCase.wantErrIs)
+			}
+
+			if diff := cmp.Diff(testCase.wantIDs, handled); diff != "" {
+				t.Errorf("handled IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeStream_LargeArray(t *testing.T) {
+	t.Parallel()
+
+	const elements = 100_000
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		_, _ = io.WriteString(writer, "[")
+
+		for i := range elements {
+			if i > 0 {
+				_, _ = io.WriteString(writer, ",")
+			}
+
+			_, _ = io.WriteString(writer, `{"id":`+strconv.Itoa(i)+`}`)
+		}
+
+		_, _ = io.WriteString(writer, "]")
+		_ = writer.Close()
+	}()
+
+	next := 0
+
+	err := httputil.DecodeStream(reader, func(event streamedEvent) error {
+		if event.ID != next {
+			t.Fatalf("event.ID = %d, want: %d", event.ID, next)
+		}
+
+		next++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeStream() error = %v, want: nil", err)
+	}
+
+	if next != elements {
+		t.Errorf("handled elements = %d, want: %d", next, elements)
+	}
+}
+
+func TestDecodeStream_MaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	var (
+		handled   []int
+		streamErr error
+	)
+
+	logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+	server := httputil.NewServer(logger, httputil.WithServerMaxBodySize(30))
+	server.Register(httputil.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/events",
+		Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+			streamErr = httputil.DecodeStream(r.Body, func(event streamedEvent) error {
+				handled = append(handled, event.ID)
+				return nil
+			})
+
+			return httputil.NoContent()
+		}),
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`[{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}]`))
+	// Clear the content length so that the max body size is enforced whilst reading.
+	request.ContentLength = -1
+
+	server.ServeHTTP(httptest.NewRecorder(), request)
+
+	if _, ok := errors.AsType[*http.MaxBytesError](streamErr); !ok {
+		t.Errorf("DecodeStream() error = %v, want: *http.MaxBytesError", streamErr)
+	}
+
+	if diff := cmp.Diff([]int{1, 2}, handled); diff != "" {
+		t.Errorf("handled IDs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,97 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// Dependency is a dependency that a handler receives from the Server that it is
+// registered with, see [DependentHandler].
+type Dependency string
+
+const (
+	// DependencyCodec is the [ServerCodec] of the Server, or the codec set on
+	// the Endpoint.
+	DependencyCodec Dependency = "codec"
+	// DependencyLogger is the logger of the Server, or the logger set on the
+	// Endpoint.
+	DependencyLogger Dependency = "logger"
+)
+
+// DependentHandler is implemented by handlers that require dependencies from
+// the Server that they are registered with. [Server.Register] and
+// [Server.RegisterGroup] reject an Endpoint whose Handler requires a
+// dependency that the Server cannot provide, rather than the handler failing
+// when it serves its first request.
+//
+// Handlers created by [NewHandler], [NewFormHandler] and [NewBatchHandler]
+// implement DependentHandler, requiring a codec unless one is set with
+// [WithHandlerCodec]. Use [RequireDependencies] to mark other handlers.
+type DependentHandler interface {
+	http.Handler
+	// RequiredDependencies returns the dependencies that the handler requires
+	// from the Server.
+	RequiredDependencies() []Dependency
+}
+
+// RequireDependencies returns a [DependentHandler] that serves requests with h
+// and requires deps, in addition to any dependencies required by h.
+func RequireDependencies(h http.Handler, deps ...Dependency) DependentHandler {
+	return dependentHandler{Handler: h, deps: slices.Concat(requiredDependencies(h), deps)}
+}
+
+// dependentHandler is an http.Handler marked as requiring dependencies by
+// RequireDependencies.
+type dependentHandler struct {
+	http.Handler
+
+	deps []Dependency
+}
+
+// RequiredDependencies implements the DependentHandler interface.
+func (h dependentHandler) RequiredDependencies() []Dependency {
+	return h.deps
+}
+
+// RequiredDependencies implements the DependentHandler interface. A logger is
+// not required as handlers fall back to slog.Default without one.
+func (h *handler[D, P]) RequiredDependencies() []Dependency {
+	if h.codec == nil {
+		return []Dependency{DependencyCodec}
+	}
+
+	return nil
+}
+
+// requiredDependencies returns the dependencies required by h if it is a
+// DependentHandler.
+func requiredDependencies(h http.Handler) []Dependency {
+	if dh, ok := h.(DependentHandler); ok {
+		return dh.RequiredDependencies()
+	}
+
+	return nil
+}
+
+// validateDependencies returns an error wrapping [ErrMissingDependency] if the
+// Handler of endpoint, or a handler wrapped by its middleware, requires a
+// dependency that the Server cannot provide.
+func (s *Server) validateDependencies(endpoint Endpoint) error {
+	for _, dep := range slices.Concat(endpoint.dependencies, requiredDependencies(endpoint.Handler)) {
+		var missing bool
+
+		switch dep {
+		case DependencyCodec:
+			missing = s.endpointCodec(endpoint) == nil
+		case DependencyLogger:
+			missing = s.endpointLogger(endpoint) == nil
+		}
+
+		if missing {
+			return fmt.Errorf("%w: %s", ErrMissingDependency, dep)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// deprecation describes the deprecation of an endpoint, see
+// [WithHandlerDeprecation].
+type deprecation struct {
+	date   time.Time
+	sunset time.Time
+	link   string
+}
+
+// setHeaders sets the Deprecation header, and the Sunset and Link headers when
+// a sunset date and link are set, on header. The Deprecation header is a
+// structured field date as defined by RFC 9745 and the Sunset header is an
+// HTTP date as defined by RFC 8594.
+func (d deprecation) setHeaders(header http.Header) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(d.date.Unix(), 10))
+
+	if !d.sunset.IsZero() {
+		header.Set("Sunset", d.sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if d.link != "" {
+		header.Add("Link", "<"+d.link+`>; rel="deprecation"`)
+	}
+}
@@ -0,0 +1,103 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestWithHandlerDeprecation(t *testing.T) {
+	t.Parallel()
+
+	deprecatedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt := time.Date(2026, time.July, 1, 12, 30, 0, 0, time.FixedZone("BST", 60*60))
+
+	testCases := map[string]struct {
+		sunset     time.Time
+		link       string
+		err        error
+		wantStatus int
+		wantHeader http.Header
+	}{
+		"all headers are set on a successful response": {
+			sunset:     sunsetAt,
+			link:       "https://example.com/docs/orders-v2",
+			err:        nil,
+			wantStatus: http.StatusOK,
+			wantHeader: http.Header{
+				"Deprecation": {"@1767225600"},
+				"Sunset":      {"Wed, 01 Jul 2026 11:30:00 GMT"},
+				"Link":        {`<https://example.com/docs/orders-v2>; rel="deprecation"`},
+			},
+		},
+		"all headers are set on an error response": {
+			sunset:     sunsetAt,
+			link:       "https://example.com/docs/orders-v2",
+			err:        problem.NotFound(httptest.NewRequest(http.MethodGet, "/orders", nil)),
+			wantStatus: http.StatusNotFound,
+			wantHeader: http.Header{
+				"Deprecation": {"@1767225600"},
+				"Sunset":      {"Wed, 01 Jul 2026 11:30:00 GMT"},
+				"Link":        {`<https://example.com/docs/orders-v2>; rel="deprecation"`},
+			},
+		},
+		"sunset and link are omitted when not set": {
+			sunset:     time.Time{},
+			link:       "",
+			err:        nil,
+			wantStatus: http.StatusOK,
+			wantHeader: http.Header{
+				"Deprecation": {"@1767225600"},
+				"Sunset":      nil,
+				"Link":        nil,
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/orders",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					if testCase.err != nil {
+						return nil, testCase.err
+					}
+
+					return httputil.OK([]string{})
+				}, httputil.WithHandlerDeprecation(deprecatedAt, testCase.sunset, testCase.link)),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			for name, want := range testCase.wantHeader {
+				if diff := cmp.Diff(want, response.Header().Values(name)); diff != "" {
+					t.Errorf("response.Header[%s] mismatch (-want +got):\n%s", name, diff)
+				}
+			}
+
+			testutil.AssertLog(t, logs, slog.LevelWarn, "Request made to a deprecated endpoint", map[string]slog.Value{
+				"sunset": slog.TimeValue(testCase.sunset),
+			})
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package httputil
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // Content-MD5 is an integrity check, not a security control.
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// digestMismatchDetail is the problem detail used when the request body does
+// not match the digest sent with it.
+const digestMismatchDetail = "The request body does not match its digest"
+
+// bodyDigest is a digest of the request body sent by the client.
+type bodyDigest struct {
+	header string
+	want   string
+	hash   func() hash.Hash
+}
+
+// newVerifyDigestMiddleware creates a middleware that verifies the request body
+// against the Content-MD5 header and the sha-256 value of the Digest header,
+// see [WithServerVerifyDigest]. The body is buffered to compute the digests and
+// replaced with the buffered copy, so it can still be read, and replayed with
+// GetBody, by the handlers. The body is read through the reader installed by
+// the max body size middleware, so buffering never exceeds that limit. If
+// enabled is false, the middleware does nothing.
+func newVerifyDigestMiddleware(logger *slog.Logger, enabled bool, logAttributes LogAttributesFunc) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			digests := requestDigests(r.Header)
+			if len(digests) == 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				if maxBytesErr, ok := errors.AsType[*http.MaxBytesError](err); ok {
+					http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
+					requestLogger(logger, r, logAttributes).WarnContext(
+						r.Context(),
+						"Request body exceeds max bytes limit",
+						slog.Int64("max_bytes", maxBytesErr.Limit),
+					)
+
+					return
+				}
+
+				writeMiddlewareError(w, r, problem.BadRequest(r))
+				requestLogger(logger, r, logAttributes).WarnContext(r.Context(), "Failed to read request body to verify its digest", slog.Any("error", err))
+
+				return
+			}
+
+			for _, digest := range digests {
+				if !digest.matches(body) {
+					writeMiddlewareError(w, r, problem.BadRequest(r).WithDetail(digestMismatchDetail))
+					requestLogger(logger, r, logAttributes).WarnContext(
+						r.Context(),
+						"Request body does not match its digest",
+						slog.String("header", digest.header),
+					)
+
+					return
+				}
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestDigests returns the digests of the request body sent in the
+// Content-MD5 header and the sha-256 value of the Digest header. Values of the
+// Digest header that use another algorithm are ignored.
+func requestDigests(header http.Header) []bodyDigest {
+	var digests []bodyDigest
+
+	if value := strings.TrimSpace(header.Get("Content-MD5")); value != "" {
+		digests = append(digests, bodyDigest{header: "Content-MD5", want: value, hash: md5.New})
+	}
+
+	for _, value := range header.Values("Digest") {
+		for instance := range strings.SplitSeq(value, ",") {
+			algorithm, encoded, ok := strings.Cut(strings.TrimSpace(instance), "=")
+			if ok && strings.EqualFold(algorithm, "sha-256") {
+				digests = append(digests, bodyDigest{header: "Digest", want: strings.TrimSpace(encoded), hash: sha256.New})
+			}
+		}
+	}
+
+	return digests
+}
+
+// matches reports whether body has the digest, comparing the base64 encoded
+// values in constant time. A malformed digest never matches.
+func (d bodyDigest) matches(body []byte) bool {
+	want, err := base64.StdEncoding.DecodeString(d.want)
+	if err != nil {
+		return false
+	}
+
+	h := d.hash()
+	_, _ = h.Write(body)
+
+	return subtle.ConstantTimeCompare(h.Sum(nil), want) == 1
+}
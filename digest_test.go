@@ -0,0 +1,124 @@
+package httputil_test
+
+import (
+	"crypto/md5" //nolint:gosec // Content-MD5 is an integrity check, not a security control.
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestWithServerVerifyDigest(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"name":"alice"}`
+
+	md5Sum := md5.Sum([]byte(body)) //nolint:gosec // Content-MD5 is an integrity check, not a security control.
+	sha256Sum := sha256.Sum256([]byte(body))
+
+	validMD5 := base64.StdEncoding.EncodeToString(md5Sum[:])
+	validSHA256 := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	otherSHA256 := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	testCases := map[string]struct {
+		options    []httputil.ServerOption
+		header     http.Header
+		wantStatus int
+		wantLog    string
+	}{
+		"a matching Content-MD5 header is accepted": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Content-Md5": {validMD5}},
+			wantStatus: http.StatusOK,
+			wantLog:    "",
+		},
+		"a matching sha-256 Digest header is accepted": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Digest": {"unixsum=30637, SHA-256=" + validSHA256}},
+			wantStatus: http.StatusOK,
+			wantLog:    "",
+		},
+		"a mismatching Content-MD5 header is rejected": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Content-Md5": {base64.StdEncoding.EncodeToString(make([]byte, md5.Size))}},
+			wantStatus: http.StatusBadRequest,
+			wantLog:    "Content-MD5",
+		},
+		"a mismatching sha-256 Digest header is rejected": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Digest": {"sha-256=" + otherSHA256}},
+			wantStatus: http.StatusBadRequest,
+			wantLog:    "Digest",
+		},
+		"a malformed digest is rejected": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Digest": {"sha-256=not base64"}},
+			wantStatus: http.StatusBadRequest,
+			wantLog:    "Digest",
+		},
+		"a digest with an unsupported algorithm is not verified": {
+			options:    []httputil.ServerOption{httputil.WithServerVerifyDigest()},
+			header:     http.Header{"Digest": {"sha-512=" + otherSHA256}},
+			wantStatus: http.StatusOK,
+			wantLog:    "",
+		},
+		"a mismatching digest is not verified when the option is not set": {
+			options:    nil,
+			header:     http.Header{"Digest": {"sha-256=" + otherSHA256}},
+			wantStatus: http.StatusOK,
+			wantLog:    "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.options...)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/users",
+				Handler: httputil.NewHandler(func(r httputil.RequestData[map[string]string]) (*httputil.Response, error) {
+					return httputil.OK(r.Data)
+				}),
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d, body: %s", response.Code, testCase.wantStatus, response.Body.String())
+			}
+
+			if testCase.wantStatus == http.StatusOK {
+				if diff := testutil.DiffJSON(body, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+
+				return
+			}
+
+			want := problem.BadRequest(request).WithDetail("The request body does not match its digest").MustMarshalJSONString()
+			if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			testutil.AssertLog(t, logs, slog.LevelWarn, "Request body does not match its digest", map[string]slog.Value{
+				"header": slog.StringValue(testCase.wantLog),
+			})
+		})
+	}
+}
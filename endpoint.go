@@ -1,6 +1,9 @@
 package httputil
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"slices"
 )
@@ -16,8 +19,45 @@ type (
 		Path string
 		// Handler is the [http.Handler] that will handle requests for this endpoint.
 		Handler http.Handler
+		// Examples holds optional example request/response payloads for this
+		// endpoint, for use in generated documentation and contract tests. When the
+		// Handler was created by [NewHandler] or [NewFormHandler], each example
+		// request is decoded into the handler's data type when the endpoint is
+		// registered so that stale examples fail fast.
+		Examples []Example
 
-		guard Guard
+		codec        ServerCodec
+		dependencies []Dependency
+		guard        Guard
+		logger       *slog.Logger
+		middlewares  []MiddlewareFunc
+	}
+
+	// GroupConfig holds configuration shared by the endpoints of an
+	// EndpointGroup, see [EndpointGroup.WithConfig]. Zero fields are ignored.
+	GroupConfig struct {
+		// Logger is used by the handlers of the endpoints, and by the
+		// middleware of the Server for their requests, in place of the logger of
+		// the Server. Use it to add fixed attributes, such as the name of the
+		// group, with [slog.Logger.With].
+		Logger *slog.Logger
+		// Codec is used by the endpoints in place of the codec of the Server.
+		Codec ServerCodec
+		// Guard is added to the endpoints as with [EndpointGroup.WithGuard].
+		Guard Guard
+	}
+
+	// Example is a named example request/response payload pair for an Endpoint.
+	Example struct {
+		// Name identifies the example within the Endpoint.
+		Name string
+		// Request is the encoded request body. It must decode into the data type of
+		// the Endpoint's Handler using the codec the Handler is registered with.
+		Request []byte
+		// Response is the encoded response body. It is not validated.
+		Response []byte
+		// StatusCode is the HTTP status code expected in response to Request.
+		StatusCode int
 	}
 
 	// EndpointGroup represents a group of Endpoint definitions allowing access to
@@ -25,10 +65,75 @@ type (
 	EndpointGroup []Endpoint
 
 	// GuardStack represents multiple Guard instances that
-	// will be run in order.
+	// will be run in order. Every Guard must pass for the request to proceed;
+	// see [AnyGuard] for passing when any Guard passes.
 	GuardStack []Guard
 )
 
+// ExampleError is returned when an Example does not match the data type of the
+// Endpoint's Handler.
+type ExampleError struct {
+	Method, Path, Name string
+	Err                error
+}
+
+// Error implements the error interface.
+func (e *ExampleError) Error() string {
+	return fmt.Sprintf("example %q for endpoint %s %s is invalid: %v", e.Name, e.Method, e.Path, e.Err)
+}
+
+// Unwrap allows ExampleError to be used with errors.Is and errors.As.
+func (e *ExampleError) Unwrap() error {
+	return e.Err
+}
+
+// Errors wrapped by [EndpointError] when [Server.RegisterGroup] rejects an
+// Endpoint.
+var (
+	// ErrInvalidMethod is returned for an Endpoint whose Method is not one of
+	// the methods defined by net/http, such as http.MethodGet.
+	ErrInvalidMethod = errors.New("invalid method")
+	// ErrNilHandler is returned for an Endpoint without a Handler.
+	ErrNilHandler = errors.New("nil handler")
+	// ErrDuplicateEndpoint is returned for an Endpoint with the same Method and
+	// Path as an earlier Endpoint in the group.
+	ErrDuplicateEndpoint = errors.New("duplicate endpoint")
+	// ErrMissingDependency is returned for an Endpoint whose Handler requires a
+	// [Dependency] that the Server cannot provide, see [DependentHandler].
+	ErrMissingDependency = errors.New("missing dependency")
+)
+
+// EndpointError is returned by [Server.RegisterGroup] for each Endpoint in the
+// group that cannot be registered.
+type EndpointError struct {
+	// Index is the position of the Endpoint in the group.
+	Index        int
+	Method, Path string
+	Err          error
+}
+
+// Error implements the error interface.
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("endpoint %d (%s %s): %v", e.Index, e.Method, e.Path, e.Err)
+}
+
+// Unwrap allows EndpointError to be used with errors.Is and errors.As.
+func (e *EndpointError) Unwrap() error {
+	return e.Err
+}
+
+// Example returns the Example with the given name and true, or a zero Example
+// and false if the Endpoint has no Example with that name.
+func (e Endpoint) Example(name string) (Example, bool) {
+	for _, ex := range e.Examples {
+		if ex.Name == name {
+			return ex, true
+		}
+	}
+
+	return Example{Name: "", Request: nil, Response: nil, StatusCode: 0}, false
+}
+
 // Ensure that GuardStack implements the Guard
 // interface.
 var _ Guard = GuardStack{}
@@ -54,13 +159,39 @@ func (gs GuardStack) Guard(r *http.Request) (*http.Request, error) {
 // Guard applied. The original Endpoint remains unmodified.
 func NewEndpointWithGuard(e Endpoint, g Guard) Endpoint {
 	return Endpoint{
-		Method:  e.Method,
-		Path:    e.Path,
-		Handler: e.Handler,
-		guard:   g,
+		Method:       e.Method,
+		Path:         e.Path,
+		Handler:      e.Handler,
+		Examples:     e.Examples,
+		codec:        e.codec,
+		dependencies: e.dependencies,
+		guard:        g,
+		logger:       e.logger,
+		middlewares:  e.middlewares,
 	}
 }
 
+// WithConfig applies the shared configuration in cfg to all provided
+// endpoints. The logger and codec of cfg are used by endpoints that do not
+// already have their own, such as a codec set with [EndpointBuilder.Codec], and
+// the guard is added as with [EndpointGroup.WithGuard]. Options set on a
+// handler, such as [WithHandlerLogger] or [WithHandlerCodec], take precedence.
+// It returns a new EndpointGroup with the configuration applied. The original
+// endpoints are not modified.
+func (eg EndpointGroup) WithConfig(cfg GroupConfig) EndpointGroup {
+	configured := cloneAndUpdate(eg, func(e *Endpoint) {
+		if e.logger == nil {
+			e.logger = cfg.Logger
+		}
+
+		if e.codec == nil {
+			e.codec = cfg.Codec
+		}
+	})
+
+	return EndpointGroup(configured).WithGuard(cfg.Guard)
+}
+
 // WithGuard adds the Guard as a
 // GuardStack with the currently set Guard as the
 // second Guard in the stack. It returns a new slice of
@@ -100,13 +231,23 @@ func (eg EndpointGroup) WithGuard(g Guard) EndpointGroup {
 // from [WithClientInterceptor], whose across-call ordering is FIFO because
 // client interceptors form a single flat chain rather than a nested
 // composition.
+//
+// The middlewares are also recorded on the endpoints so that the Server can
+// apply them to the OPTIONS requests it answers automatically; see
+// [Server.Register].
 func (eg EndpointGroup) WithMiddleware(middlewares ...MiddlewareFunc) EndpointGroup {
+	applied := slices.DeleteFunc(slices.Clone(middlewares), func(m MiddlewareFunc) bool { return m == nil })
+
 	return cloneAndUpdate(eg, func(e *Endpoint) {
-		for _, m := range slices.Backward(middlewares) {
-			if m != nil {
-				e.Handler = m(e.Handler)
-			}
+		if len(applied) > 0 {
+			e.dependencies = slices.Concat(e.dependencies, requiredDependencies(e.Handler))
+		}
+
+		for _, m := range slices.Backward(applied) {
+			e.Handler = m(e.Handler)
 		}
+
+		e.middlewares = slices.Concat(applied, e.middlewares)
 	})
 }
 
@@ -126,10 +267,15 @@ func cloneAndUpdate(endpoints []Endpoint, update func(e *Endpoint)) []Endpoint {
 
 	for _, endpoint := range endpoints {
 		e := Endpoint{
-			Method:  endpoint.Method,
-			Path:    endpoint.Path,
-			Handler: endpoint.Handler,
-			guard:   endpoint.guard,
+			Method:       endpoint.Method,
+			Path:         endpoint.Path,
+			Handler:      endpoint.Handler,
+			Examples:     endpoint.Examples,
+			codec:        endpoint.codec,
+			dependencies: endpoint.dependencies,
+			guard:        endpoint.guard,
+			logger:       endpoint.logger,
+			middlewares:  endpoint.middlewares,
 		}
 
 		update(&e)
@@ -0,0 +1,98 @@
+package httputil
+
+import (
+	"net/http"
+	"slices"
+)
+
+// EndpointBuilder builds an [Endpoint] with a guard, codec and middleware
+// using a fluent API. Create one with [NewEndpoint]:
+//
+//	endpoint := httputil.NewEndpoint(http.MethodPost, "/orders").
+//		Handler(createOrderHandler).
+//		Guard(authGuard).
+//		Codec(httputil.NewJSONServerCodec()).
+//		Middleware(auditMiddleware).
+//		Build()
+//
+// An EndpointBuilder is not safe for concurrent use.
+type EndpointBuilder struct {
+	endpoint    Endpoint
+	middlewares []MiddlewareFunc
+}
+
+// NewEndpoint creates an EndpointBuilder for an Endpoint with the given method
+// and path.
+func NewEndpoint(method, path string) *EndpointBuilder {
+	return &EndpointBuilder{
+		endpoint: Endpoint{
+			Method:       method,
+			Path:         path,
+			Handler:      nil,
+			Examples:     nil,
+			codec:        nil,
+			dependencies: nil,
+			guard:        nil,
+			logger:       nil,
+			middlewares:  nil,
+		},
+		middlewares: nil,
+	}
+}
+
+// Handler sets the handler of the Endpoint.
+func (b *EndpointBuilder) Handler(handler http.Handler) *EndpointBuilder {
+	b.endpoint.Handler = handler
+	return b
+}
+
+// Guard adds a Guard to the Endpoint. Guards run in the order they are added
+// and every Guard must pass for the request to proceed, as with a
+// [GuardStack]. Nil guards are skipped.
+func (b *EndpointBuilder) Guard(guard Guard) *EndpointBuilder {
+	if guard == nil {
+		return b
+	}
+
+	if b.endpoint.guard == nil {
+		b.endpoint.guard = guard
+		return b
+	}
+
+	b.endpoint.guard = GuardStack{b.endpoint.guard, guard}
+
+	return b
+}
+
+// Codec sets the ServerCodec used by the Endpoint in place of the codec of the
+// Server it is registered with. A codec set on the handler with
+// [WithHandlerCodec] takes precedence.
+func (b *EndpointBuilder) Codec(codec ServerCodec) *EndpointBuilder {
+	b.endpoint.codec = codec
+	return b
+}
+
+// Middleware adds middlewares to the Endpoint. Middlewares run in the order
+// they are added, across calls, before the handler. Nil middlewares are
+// skipped.
+func (b *EndpointBuilder) Middleware(middlewares ...MiddlewareFunc) *EndpointBuilder {
+	b.middlewares = append(b.middlewares, middlewares...)
+	return b
+}
+
+// Examples adds example request/response payloads to the Endpoint. See
+// [Endpoint.Examples].
+func (b *EndpointBuilder) Examples(examples ...Example) *EndpointBuilder {
+	b.endpoint.Examples = append(b.endpoint.Examples, examples...)
+	return b
+}
+
+// Build returns the Endpoint with its handler wrapped by the middlewares. The
+// EndpointBuilder can be built again, so that variations of an Endpoint can
+// share a common base.
+func (b *EndpointBuilder) Build() Endpoint {
+	endpoint := b.endpoint
+	endpoint.Examples = slices.Clone(b.endpoint.Examples)
+
+	return EndpointGroup{endpoint}.WithMiddleware(b.middlewares...)[0]
+}
@@ -0,0 +1,140 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestEndpointBuilder(t *testing.T) {
+	t.Parallel()
+
+	orderMiddleware := func(name string) httputil.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Order", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	orderGuard := func(name string) httputil.Guard {
+		return httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+			r.Header.Add("X-Guards", name)
+			return r, nil
+		})
+	}
+
+	newBuilder := func() *httputil.EndpointBuilder {
+		return httputil.NewEndpoint(http.MethodGet, "/orders").
+			Handler(httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return httputil.OK(map[string][]string{"guards": r.Header.Values("X-Guards")})
+			})).
+			Guard(orderGuard("first")).
+			Guard(nil).
+			Guard(orderGuard("second")).
+			Guard(httputil.NewRequiredHeadersGuard("X-Api-Key")).
+			Codec(httputil.NewJSONServerCodec(httputil.WithJSONIndent("  "))).
+			Middleware(orderMiddleware("first"), nil).
+			Middleware(orderMiddleware("second"))
+	}
+
+	serve := func(t *testing.T, endpoint httputil.Endpoint, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(endpoint)
+
+		request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		request.Header = header
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		return response
+	}
+
+	t.Run("the built endpoint has the method and path", func(t *testing.T) {
+		t.Parallel()
+
+		endpoint := newBuilder().Build()
+
+		if endpoint.Method != http.MethodGet || endpoint.Path != "/orders" {
+			t.Errorf("endpoint = %s %s, want: GET /orders", endpoint.Method, endpoint.Path)
+		}
+	})
+
+	t.Run("the guards, codec and middleware are applied in order", func(t *testing.T) {
+		t.Parallel()
+
+		response := serve(t, newBuilder().Build(), http.Header{"X-Api-Key": {"key"}})
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("response.Code = %d, want: %d, body: %s", response.Code, http.StatusOK, response.Body.String())
+		}
+
+		if diff := cmp.Diff([]string{"first", "second"}, response.Header().Values("X-Order")); diff != "" {
+			t.Errorf("middleware order mismatch (-want +got):\n%s", diff)
+		}
+
+		want := "{\n  \"guards\": [\n    \"first\",\n    \"second\"\n  ]\n}\n"
+		if diff := cmp.Diff(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("guard errors are encoded with the codec", func(t *testing.T) {
+		t.Parallel()
+
+		response := serve(t, newBuilder().Build(), http.Header{})
+
+		if response.Code != http.StatusBadRequest {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+
+		if body := response.Body.String(); !strings.HasPrefix(body, "{\n  ") {
+			t.Errorf("response.Body = %q, want: indented JSON", body)
+		}
+	})
+
+	t.Run("the server codec is used when no codec is set", func(t *testing.T) {
+		t.Parallel()
+
+		endpoint := httputil.NewEndpoint(http.MethodGet, "/orders").
+			Handler(httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				return httputil.OK(map[string]string{"hello": "world"})
+			})).
+			Build()
+
+		response := serve(t, endpoint, http.Header{})
+
+		if diff := cmp.Diff("{\"hello\":\"world\"}\n", response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("building again does not share middleware added afterwards", func(t *testing.T) {
+		t.Parallel()
+
+		builder := newBuilder()
+		first := builder.Build()
+		second := builder.Middleware(orderMiddleware("third")).Build()
+
+		if diff := cmp.Diff([]string{"first", "second"}, serve(t, first, http.Header{"X-Api-Key": {"key"}}).Header().Values("X-Order")); diff != "" {
+			t.Errorf("first endpoint middleware mismatch (-want +got):\n%s", diff)
+		}
+
+		if diff := cmp.Diff([]string{"first", "second", "third"}, serve(t, second, http.Header{"X-Api-Key": {"key"}}).Header().Values("X-Order")); diff != "" {
+			t.Errorf("second endpoint middleware mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/nickbryan/slogutil"
 
 	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
 	"github.com/nickbryan/httputil/problem"
 )
 
@@ -233,7 +234,15 @@ func TestEndpointGroup_WithMiddleware(t *testing.T) {
 			t.Errorf("expected len(endpoints) = %d, got: %d", len(endpoints), len(endpointsWithMiddleware))
 		}
 
-		if diff := cmp.Diff(endpoints, endpointsWithMiddleware, cmpopts.IgnoreInterfaces(struct{ httputil.Guard }{})); diff != "" {
+		opts := []cmp.Option{
+			cmpopts.IgnoreInterfaces(struct {
+				httputil.Guard
+				httputil.ServerCodec
+			}{}),
+			cmpopts.IgnoreFields(httputil.Endpoint{}, "dependencies", "logger", "middlewares"),
+		}
+
+		if diff := cmp.Diff(endpoints, endpointsWithMiddleware, opts...); diff != "" {
 			t.Errorf("returned endpoints are not the same as the passed endpoints, diff: %s", diff)
 		}
 	})
@@ -437,6 +446,154 @@ func TestEndpointGroup_WithPrefix(t *testing.T) {
 	}
 }
 
+func TestEndpointGroup_WithConfig(t *testing.T) {
+	t.Parallel()
+
+	serverLogger, serverLogs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+	groupLogger, groupLogs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+
+	list := httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+		return httputil.OK([]string{"book"})
+	})
+	fail := httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+		return nil, errors.New("database unavailable")
+	})
+
+	server := httputil.NewServer(serverLogger)
+	server.Register(httputil.EndpointGroup{
+		{Method: http.MethodGet, Path: "/orders", Handler: list},
+		{Method: http.MethodGet, Path: "/orders/fail", Handler: fail},
+	}.WithConfig(httputil.GroupConfig{
+		Logger: groupLogger.With(slog.String("group", "orders")),
+		Codec:  envelopeServerCodec{JSONServerCodec: httputil.NewJSONServerCodec()},
+		Guard:  nil,
+	})...)
+	server.Register(httputil.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/users",
+		Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+			return httputil.OK([]string{"alice"})
+		}),
+	})
+
+	testCases := map[string]struct {
+		path     string
+		wantBody string
+	}{
+		"an endpoint in the group uses the group codec": {
+			path:     "/orders",
+			wantBody: `{"data":["book"]}`,
+		},
+		"an endpoint outside of the group uses the server codec": {
+			path:     "/users",
+			wantBody: `["alice"]`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+			if diff := testutil.DiffJSON(testCase.wantBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("an endpoint in the group logs with the group logger", func(t *testing.T) {
+		t.Parallel()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/orders/fail", nil))
+
+		if response.Code != http.StatusInternalServerError {
+			t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusInternalServerError)
+		}
+
+		testutil.AssertLog(t, groupLogs, slog.LevelError, "Handler received an unhandled error", map[string]slog.Value{
+			"group": slog.StringValue("orders"),
+		})
+
+		if got := serverLogs.Len(); got != 0 {
+			t.Errorf("serverLogs.Len() = %d, want: 0, logs: %+v", got, serverLogs.AsSliceOfNestedKeyValuePairs())
+		}
+	})
+
+	t.Run("the group guard is added to each endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		guarded := httputil.EndpointGroup{
+			{Method: http.MethodGet, Path: "/orders", Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				return httputil.NoContent()
+			})},
+		}.WithConfig(httputil.GroupConfig{
+			Logger: nil,
+			Codec:  nil,
+			Guard: httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+				return nil, problem.Forbidden(r)
+			}),
+		})
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		guardedServer := httputil.NewServer(logger)
+		guardedServer.Register(guarded...)
+
+		response := httptest.NewRecorder()
+		guardedServer.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestEndpoint_Example(t *testing.T) {
+	t.Parallel()
+
+	endpoint := httputil.Endpoint{
+		Method:  http.MethodPost,
+		Path:    "/users",
+		Handler: nil,
+		Examples: []httputil.Example{
+			{Name: "create", Request: []byte(`{"name":"test"}`), Response: []byte(`{"id":1}`), StatusCode: http.StatusCreated},
+		},
+	}
+
+	t.Run("returns the example when it exists", func(t *testing.T) {
+		t.Parallel()
+
+		example, ok := endpoint.Example("create")
+		if !ok {
+			t.Fatal("expected example to be found")
+		}
+
+		if example.StatusCode != http.StatusCreated {
+			t.Errorf("unexpected status code, want: %d, got: %d", http.StatusCreated, example.StatusCode)
+		}
+	})
+
+	t.Run("returns false when the example does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		if _, ok := endpoint.Example("missing"); ok {
+			t.Error("expected example not to be found")
+		}
+	})
+
+	t.Run("examples are preserved when the endpoint group is modified", func(t *testing.T) {
+		t.Parallel()
+
+		endpoints := httputil.EndpointGroup{endpoint}.WithPrefix("/api")
+
+		if _, ok := endpoints[0].Example("create"); !ok {
+			t.Error("expected example to be preserved")
+		}
+	})
+}
+
 func TestGuardStack(t *testing.T) {
 	t.Parallel()
 
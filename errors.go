@@ -0,0 +1,117 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// Sentinel errors that an [Action] or [Guard] can return, or wrap, to produce
+// the corresponding problem response without constructing a
+// [problem.DetailedError] directly. They are matched using errors.Is.
+var (
+	// ErrBadRequest results in a [problem.BadRequest] response.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized results in a [problem.Unauthorized] response.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden results in a [problem.Forbidden] response.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound results in a [problem.NotFound] response.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict results in a [problem.Conflict] response, for requests that
+	// conflict with the current state of the resource.
+	ErrConflict = errors.New("conflict")
+	// ErrResourceExists results in a [problem.ResourceExists] response, for
+	// requests that would create a duplicate of an existing resource.
+	ErrResourceExists = errors.New("resource exists")
+)
+
+// ValidationError returns a [problem.ConstraintViolation] error for r with the
+// given properties. Actions return it to report field-level validation errors
+// computed in business logic, which are written as a 422 Unprocessable Entity
+// problem response in the same shape as struct tag validation errors.
+func ValidationError(r *http.Request, properties ...problem.Property) error {
+	return problem.ConstraintViolation(r, properties...)
+}
+
+// ErrorMapper translates an error returned by an [Action] or [Guard] into a
+// problem response. It returns nil if it does not recognise err so that the
+// next ErrorMapper can be tried. Use [WithServerErrorMapper] to register
+// ErrorMappers for translating domain errors centrally.
+type ErrorMapper func(r *http.Request, err error) *problem.DetailedError
+
+// PanicMapper translates a value recovered from a panic into a problem
+// response. It returns nil if it does not recognise recovered so that the next
+// PanicMapper can be tried. Use [WithServerPanicMapper] to register
+// PanicMappers for libraries that panic with typed values.
+type PanicMapper func(r *http.Request, recovered any) *problem.DetailedError
+
+// sentinelProblems maps each sentinel error to its problem constructor.
+//
+//nolint:gochecknoglobals // Lookup table for the sentinel errors above.
+var sentinelProblems = []struct {
+	err     error
+	problem func(r *http.Request) *problem.DetailedError
+}{
+	{err: ErrBadRequest, problem: problem.BadRequest},
+	{err: ErrUnauthorized, problem: problem.Unauthorized},
+	{err: ErrForbidden, problem: problem.Forbidden},
+	{err: ErrNotFound, problem: problem.NotFound},
+	{err: ErrConflict, problem: problem.Conflict},
+	{err: ErrResourceExists, problem: problem.ResourceExists},
+	{err: ErrContentLengthMismatch, problem: func(r *http.Request) *problem.DetailedError {
+		return problem.BadRequest(r).WithDetail(contentLengthMismatchDetail)
+	}},
+	{err: ErrTrailingJSONData, problem: func(r *http.Request) *problem.DetailedError {
+		return problem.BadRequest(r).WithDetail(trailingJSONDataDetail)
+	}},
+	{err: ErrUploadTooLarge, problem: func(r *http.Request) *problem.DetailedError {
+		return problem.PayloadTooLarge(r).WithDetail(uploadTooLargeDetail)
+	}},
+	{err: ErrUploadContentType, problem: func(r *http.Request) *problem.DetailedError {
+		return problem.BadRequest(r).WithDetail(uploadContentTypeDetail)
+	}},
+	{err: ErrTooManyUploads, problem: func(r *http.Request) *problem.DetailedError {
+		return problem.BadRequest(r).WithDetail(tooManyUploadsDetail)
+	}},
+}
+
+// withDebugExtensions returns a copy of problemDetails with the message of err
+// added as the "error" extension member. See [WithServerDebugErrors].
+func withDebugExtensions(problemDetails *problem.DetailedError, err any) *problem.DetailedError {
+	return problemDetails.WithExtension("error", fmt.Sprint(err))
+}
+
+// withPanicDebugExtensions returns a copy of problemDetails with the recovered
+// panic value and stack added as the "error" and "stack" extension members. It
+// must be called while recovering so that the stack is that of the panic.
+func withPanicDebugExtensions(problemDetails *problem.DetailedError, recovered any) *problem.DetailedError {
+	return withDebugExtensions(problemDetails, recovered).WithExtension("stack", string(debug.Stack()))
+}
+
+// problemFromError returns the problem.DetailedError that err is or wraps, the
+// problem produced by the first of mappers to recognise err, or the problem for
+// a wrapped sentinel error, in that order. It returns false if err does not map
+// to a problem.
+func problemFromError(r *http.Request, err error, mappers []ErrorMapper) (*problem.DetailedError, bool) {
+	if problemDetails, ok := errors.AsType[*problem.DetailedError](err); ok {
+		return problemDetails, true
+	}
+
+	for _, mapper := range mappers {
+		if problemDetails := mapper(r, err); problemDetails != nil {
+			return problemDetails, true
+		}
+	}
+
+	for _, sentinel := range sentinelProblems {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.problem(r), true
+		}
+	}
+
+	return nil, false
+}
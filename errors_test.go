@@ -0,0 +1,471 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+	"github.com/nickbryan/httputil/problem/problemtest"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		"ErrBadRequest produces a bad request problem": {
+			err:        httputil.ErrBadRequest,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "400-01",
+		},
+		"ErrUnauthorized produces an unauthorized problem": {
+			err:        httputil.ErrUnauthorized,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "401-01",
+		},
+		"ErrForbidden produces a forbidden problem": {
+			err:        httputil.ErrForbidden,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "403-01",
+		},
+		"ErrNotFound produces a not found problem": {
+			err:        httputil.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "404-01",
+		},
+		"ErrConflict produces a conflict problem": {
+			err:        httputil.ErrConflict,
+			wantStatus: http.StatusConflict,
+			wantCode:   "409-02",
+		},
+		"ErrResourceExists produces a resource exists problem": {
+			err:        httputil.ErrResourceExists,
+			wantStatus: http.StatusConflict,
+			wantCode:   "409-01",
+		},
+		"ErrContentLengthMismatch produces a bad request problem": {
+			err:        httputil.ErrContentLengthMismatch,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "400-01",
+		},
+		"ErrUploadTooLarge produces a payload too large problem": {
+			err:        httputil.ErrUploadTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   "413-01",
+		},
+		"ErrUploadContentType produces a bad request problem": {
+			err:        httputil.ErrUploadContentType,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "400-01",
+		},
+		"a wrapped sentinel produces its problem": {
+			err:        fmt.Errorf("finding user: %w", httputil.ErrNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "404-01",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/action",
+					Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return nil, testCase.err
+					}),
+				},
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/guard",
+					Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+						w.WriteHeader(http.StatusOK)
+					}, httputil.WithHandlerGuard(httputil.GuardFunc(func(_ *http.Request) (*http.Request, error) {
+						return nil, testCase.err
+					}))),
+				},
+			)
+
+			for _, path := range []string{"/action", "/guard"} {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+
+				problemtest.AssertProblem(t, response.Body.Bytes(), testCase.wantStatus, testCase.wantCode)
+			}
+
+			if logs.Len() != 0 {
+				t.Errorf("logs.Len() = %d, want: 0, logs: %+v", logs.Len(), logs.AsSliceOfNestedKeyValuePairs())
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	type formData struct {
+		Email string `form:"email"`
+	}
+
+	properties := []problem.Property{
+		{Detail: "is already taken", Pointer: "/email"},
+		{Detail: "must be after the start date", Pointer: "/endDate"},
+	}
+
+	testCases := map[string]struct {
+		properties []problem.Property
+		handler    http.Handler
+	}{
+		"a validation error from a handler action produces a constraint violation": {
+			properties: properties,
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, httputil.ValidationError(r.Request, properties...)
+			}),
+		},
+		"a validation error from a form handler action produces a constraint violation": {
+			properties: properties,
+			handler: httputil.NewFormHandler(func(r httputil.Request[formData, struct{}]) (*httputil.Response, error) {
+				return nil, httputil.ValidationError(r.Request, properties...)
+			}),
+		},
+		"a validation error without properties has empty violations": {
+			properties: nil,
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, httputil.ValidationError(r.Request)
+			}),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodPost, Path: "/users", Handler: testCase.handler})
+
+			request := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("email=a%40example.com"))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusUnprocessableEntity {
+				t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusUnprocessableEntity)
+			}
+
+			want := problem.ConstraintViolation(request, testCase.properties...).MustMarshalJSONString()
+			if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			if logs.Len() != 0 {
+				t.Errorf("logs.Len() = %d, want: 0, logs: %+v", logs.Len(), logs.AsSliceOfNestedKeyValuePairs())
+			}
+		})
+	}
+}
+
+func TestProblemExtensionsFromActions(t *testing.T) {
+	t.Parallel()
+
+	type formData struct {
+		Email string `form:"email"`
+	}
+
+	violation := func(r *http.Request) *problem.DetailedError {
+		return problem.BusinessRuleViolation(r).WithExtension("orderId", "ord_123").WithExtension("retryable", false)
+	}
+
+	testCases := map[string]struct {
+		options []httputil.ServerOption
+		handler http.Handler
+		want    func(r *http.Request) *problem.DetailedError
+	}{
+		"extensions returned from a handler action are encoded": {
+			options: nil,
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, violation(r.Request)
+			}),
+			want: violation,
+		},
+		"extensions returned from a form handler action are encoded": {
+			options: nil,
+			handler: httputil.NewFormHandler(func(r httputil.Request[formData, struct{}]) (*httputil.Response, error) {
+				return nil, violation(r.Request)
+			}),
+			want: violation,
+		},
+		"extensions of a wrapped problem are encoded": {
+			options: nil,
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, fmt.Errorf("placing order: %w", violation(r.Request))
+			}),
+			want: violation,
+		},
+		"extensions are kept when the problem instance is replaced": {
+			options: []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, violation(r.Request)
+			}),
+			want: func(r *http.Request) *problem.DetailedError {
+				return violation(r).WithInstance("http://example.com/orders")
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.options...)
+			server.Register(httputil.Endpoint{Method: http.MethodPost, Path: "/orders", Handler: testCase.handler})
+
+			request := httptest.NewRequest(http.MethodPost, "http://example.com/orders", strings.NewReader("email=a%40example.com"))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusUnprocessableEntity {
+				t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusUnprocessableEntity)
+			}
+
+			if diff := testutil.DiffJSON(testCase.want(request).MustMarshalJSONString(), response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithServerDebugErrors(t *testing.T) {
+	t.Parallel()
+
+	// Handlers resolve their configuration from the first Server they are
+	// served by, so each Server needs its own endpoints.
+	endpoints := func() []httputil.Endpoint {
+		return []httputil.Endpoint{
+			{
+				Method: http.MethodGet,
+				Path:   "/action",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return nil, errors.New("database is down")
+				}),
+			},
+			{
+				Method: http.MethodGet,
+				Path:   "/guard",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}, httputil.WithHandlerGuard(httputil.GuardFunc(func(_ *http.Request) (*http.Request, error) {
+					return nil, errors.New("database is down")
+				}))),
+			},
+			{
+				Method: http.MethodGet,
+				Path:   "/panic",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+					panic("database is down")
+				}),
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		options   []httputil.ServerOption
+		path      string
+		wantBody  bool
+		wantError string
+	}{
+		"an action error includes the error without a stack when enabled": {
+			options:   []httputil.ServerOption{httputil.WithServerDebugErrors(true)},
+			path:      "/action",
+			wantBody:  true,
+			wantError: "calling action: database is down",
+		},
+		"a guard error includes the error without a stack when enabled": {
+			options:   []httputil.ServerOption{httputil.WithServerDebugErrors(true)},
+			path:      "/guard",
+			wantBody:  true,
+			wantError: "calling guard: database is down",
+		},
+		"a panic is written as a problem with the value and stack when enabled": {
+			options:   []httputil.ServerOption{httputil.WithServerDebugErrors(true)},
+			path:      "/panic",
+			wantBody:  true,
+			wantError: "database is down",
+		},
+		"an action error excludes the error and stack by default": {
+			options:   nil,
+			path:      "/action",
+			wantBody:  true,
+			wantError: "",
+		},
+		"a guard error excludes the error and stack when disabled": {
+			options:   []httputil.ServerOption{httputil.WithServerDebugErrors(false)},
+			path:      "/guard",
+			wantBody:  true,
+			wantError: "",
+		},
+		"a panic is written without a body by default": {
+			options:   nil,
+			path:      "/panic",
+			wantBody:  false,
+			wantError: "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.options...)
+			server.Register(endpoints()...)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+			if response.Code != http.StatusInternalServerError {
+				t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusInternalServerError)
+			}
+
+			if !testCase.wantBody {
+				if response.Body.Len() != 0 {
+					t.Errorf("response.Body = %q, want: empty", response.Body.String())
+				}
+
+				return
+			}
+
+			problemtest.AssertProblem(t, response.Body.Bytes(), http.StatusInternalServerError, "500-01")
+
+			var body map[string]any
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+				t.Fatalf("unexpected error decoding problem %q: %v", response.Body.String(), err)
+			}
+
+			if testCase.wantError == "" {
+				for _, key := range []string{"error", "stack"} {
+					if _, ok := body[key]; ok {
+						t.Errorf("problem has %q extension, want: none", key)
+					}
+				}
+
+				return
+			}
+
+			if got := body["error"]; got != testCase.wantError {
+				t.Errorf("error extension = %v, want: %q", got, testCase.wantError)
+			}
+
+			stack, hasStack := body["stack"].(string)
+			if wantStack := testCase.path == "/panic"; hasStack != wantStack {
+				t.Errorf("problem has stack extension = %t, want: %t", hasStack, wantStack)
+			}
+
+			if hasStack && !strings.Contains(stack, "panic") {
+				t.Errorf("stack extension = %q, want: the stack of the panic", stack)
+			}
+		})
+	}
+}
+
+var errDuplicate = errors.New("duplicate")
+
+func TestWithServerErrorMapper(t *testing.T) {
+	t.Parallel()
+
+	duplicateMapper := func(r *http.Request, err error) *problem.DetailedError {
+		if errors.Is(err, errDuplicate) {
+			return problem.ResourceExists(r)
+		}
+
+		return nil
+	}
+
+	testCases := map[string]struct {
+		mappers    []httputil.ErrorMapper
+		err        error
+		wantStatus int
+		wantCode   string
+		wantLogs   int
+	}{
+		"a mapped domain error produces the mapped problem": {
+			mappers:    []httputil.ErrorMapper{duplicateMapper},
+			err:        fmt.Errorf("creating user: %w", errDuplicate),
+			wantStatus: http.StatusConflict,
+			wantCode:   "409-01",
+		},
+		"mappers are tried in registration order until one returns a problem": {
+			mappers: []httputil.ErrorMapper{
+				func(_ *http.Request, _ error) *problem.DetailedError { return nil },
+				duplicateMapper,
+				func(r *http.Request, _ error) *problem.DetailedError { return problem.Forbidden(r) },
+			},
+			err:        errDuplicate,
+			wantStatus: http.StatusConflict,
+			wantCode:   "409-01",
+		},
+		"mappers take precedence over sentinel errors": {
+			mappers:    []httputil.ErrorMapper{func(r *http.Request, _ error) *problem.DetailedError { return problem.Forbidden(r) }},
+			err:        httputil.ErrNotFound,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "403-01",
+		},
+		"an unmapped error falls back to a server error": {
+			mappers:    []httputil.ErrorMapper{duplicateMapper},
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "500-01",
+			wantLogs:   1,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, httputil.WithServerErrorMapper(testCase.mappers...))
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/users",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return nil, testCase.err
+				}),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			problemtest.AssertProblem(t, response.Body.Bytes(), testCase.wantStatus, testCase.wantCode)
+
+			if logs.Len() != testCase.wantLogs {
+				t.Errorf("logs.Len() = %d, want: %d, logs: %+v", logs.Len(), testCase.wantLogs, logs.AsSliceOfNestedKeyValuePairs())
+			}
+		})
+	}
+}
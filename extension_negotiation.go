@@ -0,0 +1,55 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// extensionCodecCtxKey is the context key for the codec selected by the
+// extension suffix of the request path.
+type extensionCodecCtxKey struct{}
+
+// newExtensionNegotiationMiddleware creates a middleware that selects the codec
+// of a request by the extension suffix of its path, see
+// [WithServerExtensionNegotiation]. When the last segment of the path ends with
+// one of the extensions of codecs, the suffix is stripped before the request is
+// routed and the matching codec is stored in the request context. If codecs is
+// empty, the middleware does nothing.
+func newExtensionNegotiationMiddleware(codecs map[string]ServerCodec) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if len(codecs) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ext := path.Ext(r.URL.Path)
+
+			codec, ok := codecs[strings.TrimPrefix(ext, ".")]
+			if !ok || codec == nil || strings.HasSuffix(r.URL.Path, "/"+ext) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Like http.StripPrefix, copy the request and its URL so the
+			// original request is not modified.
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = strings.TrimSuffix(r.URL.Path, ext)
+			r2.URL.RawPath = strings.TrimSuffix(r.URL.RawPath, ext)
+
+			next.ServeHTTP(w, r2.WithContext(context.WithValue(r.Context(), extensionCodecCtxKey{}, codec)))
+		})
+	}
+}
+
+// extensionCodecFromContext returns the codec selected by
+// [newExtensionNegotiationMiddleware], or nil if there is none.
+func extensionCodecFromContext(ctx context.Context) ServerCodec {
+	codec, _ := ctx.Value(extensionCodecCtxKey{}).(ServerCodec)
+	return codec
+}
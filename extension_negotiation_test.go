@@ -0,0 +1,210 @@
+package httputil_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestWithServerExtensionNegotiation(t *testing.T) {
+	t.Parallel()
+
+	type report struct {
+		XMLName xml.Name `json:"-"    xml:"report"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	testCases := map[string]struct {
+		codecs          map[string]httputil.ServerCodec
+		method          string
+		path            string
+		body            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		"the xml extension selects the xml codec": {
+			codecs:          map[string]httputil.ServerCodec{"json": httputil.NewJSONServerCodec(), "xml": xmlServerCodec{}},
+			method:          http.MethodGet,
+			path:            "/data.xml",
+			body:            "",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        "<report><name>alice</name></report>",
+		},
+		"the json extension selects the json codec": {
+			codecs:          map[string]httputil.ServerCodec{"json": httputil.NewJSONServerCodec(), "xml": xmlServerCodec{}},
+			method:          http.MethodGet,
+			path:            "/data.json",
+			body:            "",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        `{"name":"alice"}` + "\n",
+		},
+		"extensions may be registered with a leading dot": {
+			codecs:          map[string]httputil.ServerCodec{".xml": xmlServerCodec{}},
+			method:          http.MethodGet,
+			path:            "/data.xml",
+			body:            "",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        "<report><name>alice</name></report>",
+		},
+		"the request body is decoded with the selected codec": {
+			codecs:          map[string]httputil.ServerCodec{"json": httputil.NewJSONServerCodec(), "xml": xmlServerCodec{}},
+			method:          http.MethodPost,
+			path:            "/data.xml",
+			body:            "<report><name>bob</name></report>",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        "<report><name>bob</name></report>",
+		},
+		"problems are encoded with the selected codec": {
+			codecs:          map[string]httputil.ServerCodec{"xml": xmlServerCodec{}},
+			method:          http.MethodPost,
+			path:            "/data.xml",
+			body:            "<report><name></name></report>",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+xml",
+			wantBody:        "<problem><status>400</status><title>Bad Request</title></problem>",
+		},
+		"a path without an extension uses the codec of the handler": {
+			codecs:          map[string]httputil.ServerCodec{"xml": xmlServerCodec{}},
+			method:          http.MethodGet,
+			path:            "/data",
+			body:            "",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        `{"name":"alice"}` + "\n",
+		},
+		"an unregistered extension is not stripped": {
+			codecs:          map[string]httputil.ServerCodec{"xml": xmlServerCodec{}},
+			method:          http.MethodGet,
+			path:            "/data.txt",
+			body:            "",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "404 page not found\n",
+		},
+		"extensions are not stripped when the option is not set": {
+			codecs:          nil,
+			method:          http.MethodGet,
+			path:            "/data.xml",
+			body:            "",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "404 page not found\n",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+
+			var options []httputil.ServerOption
+			if testCase.codecs != nil {
+				options = append(options, httputil.WithServerExtensionNegotiation(testCase.codecs))
+			}
+
+			server := httputil.NewServer(logger, options...)
+			server.Register(
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/data",
+					Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.OK(report{Name: "alice"})
+					}),
+				},
+				httputil.Endpoint{
+					Method: http.MethodPost,
+					Path:   "/data",
+					Handler: httputil.NewHandler(func(r httputil.RequestData[report]) (*httputil.Response, error) {
+						if r.Data.Name == "" {
+							return nil, problem.BadRequest(r.Request)
+						}
+
+						return httputil.OK(r.Data)
+					}),
+				},
+			)
+
+			request := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if got := response.Header().Get("Content-Type"); got != testCase.wantContentType {
+				t.Errorf("Content-Type = %q, want: %q", got, testCase.wantContentType)
+			}
+
+			if diff := cmp.Diff(testCase.wantBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// xmlServerCodec is a minimal ServerCodec that encodes and decodes XML.
+type xmlServerCodec struct{}
+
+func (xmlServerCodec) Decode(r *http.Request, into any) error {
+	if err := xml.NewDecoder(r.Body).Decode(into); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+
+		return fmt.Errorf("decoding xml: %w", err)
+	}
+
+	return nil
+}
+
+func (xmlServerCodec) Encode(w http.ResponseWriter, statusCode int, data any) error {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+
+	if err := xml.NewEncoder(w).Encode(data); err != nil {
+		return fmt.Errorf("encoding xml: %w", err)
+	}
+
+	return nil
+}
+
+func (xmlServerCodec) EncodeError(w http.ResponseWriter, statusCode int, err error) error {
+	type xmlProblem struct {
+		XMLName xml.Name `xml:"problem"`
+		Status  int      `xml:"status"`
+		Title   string   `xml:"title"`
+	}
+
+	title := http.StatusText(statusCode)
+	if detailedErr, ok := errors.AsType[*problem.DetailedError](err); ok {
+		title = detailedErr.Title
+	}
+
+	w.Header().Set("Content-Type", "application/problem+xml")
+	w.WriteHeader(statusCode)
+
+	if err := xml.NewEncoder(w).Encode(xmlProblem{Status: statusCode, Title: title}); err != nil {
+		return fmt.Errorf("encoding xml problem: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+)
+
+// FeatureFlagProvider returns the names of the feature flags that are enabled
+// for r, for example based on a tenant header or the user stored in its
+// context by earlier middleware. The provider runs in the middleware chain,
+// before the handler's [Guard], so values added to the request by a guard are
+// not visible to it.
+type FeatureFlagProvider func(r *http.Request) []string
+
+// featureFlagsCtxKey is the context key for the feature flags enabled for a
+// request.
+type featureFlagsCtxKey struct{}
+
+// NewFeatureFlagMiddleware creates a MiddlewareFunc that evaluates the feature
+// flags enabled for each request with provider and stores them in the request
+// context, where handlers can check them with [FeatureEnabled]. Flags are
+// evaluated once per request, so a handler sees a consistent set of flags for
+// the whole request. As with any middleware, it runs before the handler's
+// [Guard], so flags that depend on the authenticated user require the user to
+// be added to the context by middleware that runs before it.
+func NewFeatureFlagMiddleware(provider FeatureFlagProvider) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			names := provider(r)
+
+			enabled := make(map[string]struct{}, len(names))
+			for _, name := range names {
+				enabled[name] = struct{}{}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), featureFlagsCtxKey{}, enabled)))
+		})
+	}
+}
+
+// FeatureEnabled reports whether the feature flag name was enabled for the
+// request by [NewFeatureFlagMiddleware]. It returns false if the middleware did
+// not run.
+func FeatureEnabled(ctx context.Context, name string) bool {
+	enabled, _ := ctx.Value(featureFlagsCtxKey{}).(map[string]struct{})
+	_, ok := enabled[name]
+
+	return ok
+}
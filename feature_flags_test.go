@@ -0,0 +1,88 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+)
+
+func TestNewFeatureFlagMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// The provider enables the new checkout for the beta user only.
+	provider := func(r *http.Request) []string {
+		if r.Header.Get("X-User") == "beta" {
+			return []string{"new-checkout", "dark-mode"}
+		}
+
+		return []string{"dark-mode"}
+	}
+
+	testCases := map[string]struct {
+		user         string
+		wantResponse string
+	}{
+		"the flag is enabled for the request context of the beta user": {
+			user:         "beta",
+			wantResponse: `{"darkMode":true,"newCheckout":true}`,
+		},
+		"the flag is not enabled for the request context of another user": {
+			user:         "alice",
+			wantResponse: `{"darkMode":true,"newCheckout":false}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.EndpointGroup{
+				{
+					Method: http.MethodGet,
+					Path:   "/checkout",
+					Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.OK(map[string]bool{
+							"newCheckout": httputil.FeatureEnabled(r.Context(), "new-checkout"),
+							"darkMode":    httputil.FeatureEnabled(r.Context(), "dark-mode"),
+						})
+					}),
+				},
+			}.WithMiddleware(httputil.NewFeatureFlagMiddleware(provider))...)
+
+			request := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+			request.Header.Set("X-User", testCase.user)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusOK {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, http.StatusOK)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponse, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFeatureEnabled(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns false when the middleware did not run", func(t *testing.T) {
+		t.Parallel()
+
+		if httputil.FeatureEnabled(context.Background(), "new-checkout") {
+			t.Error("FeatureEnabled() = true, want: false")
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package httputil
+
+import (
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client that made r. If the immediate
+// peer is not one of trustedProxies, its address from r.RemoteAddr is returned
+// and any forwarding headers are ignored, as they are controlled by the client.
+//
+// Otherwise, the addresses in the Forwarded header, or the X-Forwarded-For
+// header when it is absent, are walked from the nearest proxy back towards the
+// client, and the first address that is not a trusted proxy is returned. When
+// neither header is present, a valid X-Real-IP header is used. If every
+// address in the chain is trusted, the address furthest from the Server is
+// returned. If an entry cannot be parsed, the walk stops at the last address
+// that was parsed, as entries beyond it cannot be trusted.
+//
+// The zero netip.Addr is returned if r.RemoteAddr cannot be parsed.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	peer, ok := peerAddr(r)
+	if !ok || !isTrustedProxy(peer, trustedProxies) {
+		return peer
+	}
+
+	chain := forwardedForChain(r)
+	if len(chain) == 0 {
+		if realIP, ok := parseForwardedAddr(r.Header.Get("X-Real-Ip")); ok {
+			return realIP
+		}
+
+		return peer
+	}
+
+	client := peer
+
+	for _, hop := range slices.Backward(chain) {
+		addr, ok := parseForwardedAddr(hop)
+		if !ok {
+			return client
+		}
+
+		client = addr
+		if !isTrustedProxy(addr, trustedProxies) {
+			return client
+		}
+	}
+
+	return client
+}
+
+// AbsoluteURL returns path as an absolute URL on the Server that r was made
+// to, for use in Location and Link headers. If the immediate peer is one of
+// trustedProxies, the scheme and host are taken from the Forwarded header, or
+// the X-Forwarded-Proto and X-Forwarded-Host headers, when present. The values
+// added by the trusted proxy that received the request from the client are
+// used, as found by walking the chain in the same way as [ClientIP], and a host
+// that is not a valid host[:port] value is ignored. Otherwise, the scheme and
+// host are taken from the request itself, as the headers are controlled by the
+// client.
+//
+// Relative paths are resolved against the path of r and may include a query,
+// as in "/users/1?expand=roles". An absolute URL is returned unchanged. If path
+// cannot be parsed, it is appended to the scheme and host as is.
+func AbsoluteURL(r *http.Request, path string, trustedProxies []netip.Prefix) string {
+	scheme, host := effectiveSchemeAndHost(r, trustedProxies)
+
+	//nolint:exhaustruct // Only the fields that make up the base URL are set.
+	base := url.URL{Scheme: scheme, Host: host, Path: r.URL.Path}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return scheme + "://" + host + path
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// peerAddr returns the address of the immediate peer that sent r.
+func peerAddr(r *http.Request) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	if addr, err := netip.ParseAddr(r.RemoteAddr); err == nil {
+		return addr.Unmap(), true
+	}
+
+	return netip.Addr{}, false
+}
+
+// isTrustedProxy reports whether addr belongs to one of trustedProxies.
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedForChain returns the for parameters of every element of the
+// Forwarded header, or the entries of the X-Forwarded-For header when the
+// Forwarded header is absent, ordered from the client to the nearest proxy.
+func forwardedForChain(r *http.Request) []string {
+	var chain []string
+
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		for element := range strings.SplitSeq(strings.Join(forwarded, ","), ",") {
+			for pair := range strings.SplitSeq(element, ";") {
+				if key, val, ok := strings.Cut(strings.TrimSpace(pair), "="); ok && strings.EqualFold(key, "for") {
+					chain = append(chain, val)
+				}
+			}
+		}
+
+		return chain
+	}
+
+	for entry := range strings.SplitSeq(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			chain = append(chain, entry)
+		}
+	}
+
+	return chain
+}
+
+// parseForwardedAddr parses an address from a forwarding header, which may be
+// quoted and may include a port, as in "192.0.2.1:8080" or "[2001:db8::1]:8080".
+func parseForwardedAddr(value string) (netip.Addr, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")); err == nil {
+		return addr.Unmap(), true
+	}
+
+	return netip.Addr{}, false
+}
+
+// requestURL reconstructs the absolute URL of r, using the scheme and host
+// returned by effectiveSchemeAndHost.
+func requestURL(r *http.Request, trustedProxies []netip.Prefix) string {
+	scheme, host := effectiveSchemeAndHost(r, trustedProxies)
+
+	//nolint:exhaustruct // Only the fields that make up the request URL are set.
+	u := url.URL{Scheme: scheme, Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+
+	return u.String()
+}
+
+// effectiveSchemeAndHost returns the scheme and host that the client used to
+// make r. If the immediate peer is one of trustedProxies, they are taken from
+// the Forwarded header, or the X-Forwarded-Proto and X-Forwarded-Host headers
+// when it is absent. Proxies append to these headers, so the values added by
+// the trusted proxy that received the request from the client are used rather
+// than the first, which the client can set. Only the http and https schemes,
+// and hosts that are valid host[:port] values, are accepted from the headers.
+func effectiveSchemeAndHost(r *http.Request, trustedProxies []netip.Prefix) (scheme, host string) {
+	scheme, host = "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	peer, ok := peerAddr(r)
+	if !ok || !isTrustedProxy(peer, trustedProxies) {
+		return scheme, host
+	}
+
+	var forwardedProto, forwardedHost string
+
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		element := trustedForwardedElement(parseForwarded(forwarded), trustedProxies)
+		forwardedProto, forwardedHost = element.proto, element.host
+	} else {
+		hop := trustedHop(forwardedForChain(r), trustedProxies)
+		forwardedProto = headerValueAtHop(r.Header.Values("X-Forwarded-Proto"), hop)
+		forwardedHost = headerValueAtHop(r.Header.Values("X-Forwarded-Host"), hop)
+	}
+
+	if proto := strings.ToLower(forwardedProto); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	if isValidHost(forwardedHost) {
+		host = forwardedHost
+	}
+
+	return scheme, host
+}
+
+// forwardedElement holds the parameters of an element of an RFC 7239 Forwarded
+// header that are used to determine the client's view of a request.
+type forwardedElement struct {
+	forAddr, proto, host string
+}
+
+// parseForwarded returns the elements of the RFC 7239 Forwarded header values,
+// ordered from the client to the nearest proxy.
+func parseForwarded(values []string) []forwardedElement {
+	var elements []forwardedElement
+
+	for value := range strings.SplitSeq(strings.Join(values, ","), ",") {
+		var element forwardedElement
+
+		for pair := range strings.SplitSeq(value, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+
+			val = strings.Trim(val, `"`)
+
+			switch strings.ToLower(key) {
+			case "for":
+				element.forAddr = val
+			case "proto":
+				element.proto = val
+			case "host":
+				element.host = val
+			}
+		}
+
+		elements = append(elements, element)
+	}
+
+	return elements
+}
+
+// trustedForwardedElement walks elements from the nearest proxy back towards
+// the client and returns the element added by the last trusted proxy in the
+// chain. The nearest element is always added by a trusted proxy, as it is only
+// called for requests from one, and each element whose for parameter is a
+// trusted proxy was sent by that proxy, which added the element before it.
+func trustedForwardedElement(elements []forwardedElement, trustedProxies []netip.Prefix) forwardedElement {
+	for i, element := range slices.Backward(elements) {
+		addr, ok := parseForwardedAddr(element.forAddr)
+		if i == 0 || !ok || !isTrustedProxy(addr, trustedProxies) {
+			return element
+		}
+	}
+
+	return forwardedElement{forAddr: "", proto: "", host: ""}
+}
+
+// trustedHop walks chain from the nearest proxy back towards the client in the
+// same way as trustedForwardedElement, and returns the number of hops from the
+// nearest proxy to the last trusted proxy in the chain.
+func trustedHop(chain []string, trustedProxies []netip.Prefix) int {
+	for i, entry := range slices.Backward(chain) {
+		addr, ok := parseForwardedAddr(entry)
+		if i == 0 || !ok || !isTrustedProxy(addr, trustedProxies) {
+			return len(chain) - 1 - i
+		}
+	}
+
+	return 0
+}
+
+// headerValueAtHop returns the entry of a comma separated header that was
+// appended hop proxies before the nearest one. Proxies that set the header
+// rather than appending to it leave fewer entries than hops, in which case the
+// first entry is returned.
+func headerValueAtHop(values []string, hop int) string {
+	var entries []string
+
+	for entry := range strings.SplitSeq(strings.Join(values, ","), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+
+	return entries[max(len(entries)-1-hop, 0)]
+}
+
+// isValidHost reports whether host is a valid host[:port] value, where the host
+// is a domain name, an IPv4 address or a bracketed IPv6 address. It guards
+// against forwarded hosts that would change the meaning of a URL built from
+// them.
+func isValidHost(host string) bool {
+	name, port := host, ""
+
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end < 0 {
+			return false
+		}
+
+		if addr, err := netip.ParseAddr(host[1:end]); err != nil || !addr.Is6() {
+			return false
+		}
+
+		name, port = "", host[end+1:]
+		if port != "" && !strings.HasPrefix(port, ":") {
+			return false
+		}
+	} else if i := strings.LastIndex(host, ":"); i >= 0 {
+		name, port = host[:i], host[i:]
+		if name == "" {
+			return false
+		}
+	} else if name == "" {
+		return false
+	}
+
+	if port != "" {
+		digits := port[1:]
+		if len(digits) == 0 || len(digits) > 5 || strings.Trim(digits, "0123456789") != "" {
+			return false
+		}
+	}
+
+	for _, c := range name {
+		if !isHostNameRune(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isHostNameRune reports whether c may appear in a domain name or IPv4 address.
+func isHostNameRune(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
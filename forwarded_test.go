@@ -0,0 +1,253 @@
+package httputil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	trustedProxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+
+	testCases := map[string]struct {
+		trustedProxies []netip.Prefix
+		remoteAddr     string
+		header         http.Header
+		want           netip.Addr
+	}{
+		"a direct connection returns the remote address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "192.0.2.1:1234",
+			header:         http.Header{},
+			want:           netip.MustParseAddr("192.0.2.1"),
+		},
+		"a direct ipv6 connection returns the remote address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "[2001:db8::1]:1234",
+			header:         http.Header{},
+			want:           netip.MustParseAddr("2001:db8::1"),
+		},
+		"a remote address without a port is parsed": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "192.0.2.1",
+			header:         http.Header{},
+			want:           netip.MustParseAddr("192.0.2.1"),
+		},
+		"an invalid remote address returns the zero address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "pipe",
+			header:         http.Header{},
+			want:           netip.Addr{},
+		},
+		"forwarded headers from an untrusted peer are ignored": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "192.0.2.1:1234",
+			header: http.Header{
+				"X-Forwarded-For": {"203.0.113.7"},
+				"Forwarded":       {"for=203.0.113.7"},
+				"X-Real-Ip":       {"203.0.113.7"},
+			},
+			want: netip.MustParseAddr("192.0.2.1"),
+		},
+		"forwarded headers are ignored when no proxies are trusted": {
+			trustedProxies: nil,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"203.0.113.7"}},
+			want:           netip.MustParseAddr("10.0.0.1"),
+		},
+		"x-forwarded-for from a trusted proxy returns the client": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"203.0.113.7"}},
+			want:           netip.MustParseAddr("203.0.113.7"),
+		},
+		"a chain of trusted proxies is walked back to the client": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"203.0.113.7, 10.0.0.3", "10.0.0.2"}},
+			want:           netip.MustParseAddr("203.0.113.7"),
+		},
+		"a spoofed entry before an untrusted hop is ignored": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7, 10.0.0.2"}},
+			want:           netip.MustParseAddr("203.0.113.7"),
+		},
+		"a chain of only trusted proxies returns the furthest address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:           netip.MustParseAddr("10.0.0.3"),
+		},
+		"an unparsable entry stops the walk at the last parsed address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Forwarded-For": {"203.0.113.7, unknown, 10.0.0.2"}},
+			want:           netip.MustParseAddr("10.0.0.2"),
+		},
+		"the forwarded header takes precedence over x-forwarded-for": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "[fd00::1]:1234",
+			header: http.Header{
+				"Forwarded":       {`for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.2`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			want: netip.MustParseAddr("2001:db8:cafe::17"),
+		},
+		"x-real-ip is used when there is no forwarding chain": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Real-Ip": {"203.0.113.7"}},
+			want:           netip.MustParseAddr("203.0.113.7"),
+		},
+		"an invalid x-real-ip falls back to the remote address": {
+			trustedProxies: trustedProxies,
+			remoteAddr:     "10.0.0.1:1234",
+			header:         http.Header{"X-Real-Ip": {"not-an-ip"}},
+			want:           netip.MustParseAddr("10.0.0.1"),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = testCase.remoteAddr
+			request.Header = testCase.header
+
+			if got := httputil.ClientIP(request, testCase.trustedProxies); got != testCase.want {
+				t.Errorf("ClientIP() = %s, want: %s", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	t.Parallel()
+
+	trustedProxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	testCases := map[string]struct {
+		target     string
+		remoteAddr string
+		header     http.Header
+		path       string
+		want       string
+	}{
+		"a direct request uses the request host": {
+			target:     "http://api.example.com/users",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{},
+			path:       "/users/1",
+			want:       "http://api.example.com/users/1",
+		},
+		"a direct tls request uses the https scheme": {
+			target:     "https://api.example.com/users",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{},
+			path:       "/users/1",
+			want:       "https://api.example.com/users/1",
+		},
+		"forwarded headers from an untrusted peer are ignored": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"evil.example.com"}},
+			path:       "/users/1",
+			want:       "http://internal:8080/users/1",
+		},
+		"x-forwarded headers from a trusted proxy are used": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"api.example.com"}},
+			path:       "/users/1",
+			want:       "https://api.example.com/users/1",
+		},
+		"the forwarded header from a trusted proxy is used": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"Forwarded": {`proto=https;host="api.example.com"`}},
+			path:       "/users/1",
+			want:       "https://api.example.com/users/1",
+		},
+		"a host set by the client ahead of a trusted proxy is ignored": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"X-Forwarded-For":   {"192.0.2.1"},
+				"X-Forwarded-Proto": {"http, https"},
+				"X-Forwarded-Host":  {"evil.example.com, api.example.com"},
+			},
+			path: "/users/1",
+			want: "https://api.example.com/users/1",
+		},
+		"a forwarded element set by the client ahead of a trusted proxy is ignored": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"Forwarded": {`host=evil.example.com, for=192.0.2.1;proto=https;host="api.example.com:8443"`}},
+			path:       "/users/1",
+			want:       "https://api.example.com:8443/users/1",
+		},
+		"a forwarded host that is not a valid host and port is ignored": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-Host": {"evil.example.com/@api.example.com"}},
+			path:       "/users/1",
+			want:       "http://internal:8080/users/1",
+		},
+		"a forwarded ipv6 host is used": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"Forwarded": {`proto=https;host="[2001:db8::1]:8443"`}},
+			path:       "/users/1",
+			want:       "https://[2001:db8::1]:8443/users/1",
+		},
+		"a trusted proxy without forwarded headers falls back to the request host": {
+			target:     "http://internal:8080/users",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{},
+			path:       "/users/1",
+			want:       "http://internal:8080/users/1",
+		},
+		"a query is kept": {
+			target:     "http://api.example.com/users",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{},
+			path:       "/users?page=2",
+			want:       "http://api.example.com/users?page=2",
+		},
+		"a relative path is resolved against the request path": {
+			target:     "http://api.example.com/users/1",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{},
+			path:       "roles",
+			want:       "http://api.example.com/users/roles",
+		},
+		"an absolute url is returned unchanged": {
+			target:     "http://api.example.com/users",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{},
+			path:       "https://cdn.example.com/avatar.png",
+			want:       "https://cdn.example.com/avatar.png",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			request.RemoteAddr = testCase.remoteAddr
+			request.Header = testCase.header
+
+			if got := httputil.AbsoluteURL(request, testCase.path, trustedProxies); got != testCase.want {
+				t.Errorf("AbsoluteURL() = %q, want: %q", got, testCase.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/google/go-cmp v0.7.0
 	github.com/google/uuid v1.6.0
 	github.com/nickbryan/slogutil v1.3.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.3
 )
 
 require (
@@ -0,0 +1,206 @@
+package httputil
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// ErrAPIKeyInvalid should be returned by an [APIKeyLookupFunc] when the given
+// key does not identify a known principal. The API-key guard responds with
+// problem.Unauthorized when it receives this error.
+var ErrAPIKeyInvalid = errors.New("api key is invalid")
+
+type (
+	// APIKeyLookupFunc resolves an API key to a principal. It returns a context,
+	// derived from ctx, that carries the resolved principal for use by the
+	// Handler. It should return [ErrAPIKeyInvalid] when the key is unknown;
+	// any other error is treated as an unhandled error.
+	//
+	// To avoid leaking whether a key exists via response timing, implementations
+	// should compare keys in constant time, for example by looking up a hash of
+	// the key or by using crypto/subtle.
+	APIKeyLookupFunc func(ctx context.Context, key string) (context.Context, error)
+
+	// APIKeyGuardOption allows default API-key guard config values to be
+	// overridden.
+	APIKeyGuardOption func(o *apiKeyGuardOptions)
+
+	apiKeyGuardOptions struct {
+		queryParam string
+	}
+)
+
+// WithAPIKeyQueryParam sets a query parameter that the API-key guard will read
+// the key from when the header is not present. Reading keys from the URL is
+// discouraged as URLs are commonly logged; prefer the header where possible.
+func WithAPIKeyQueryParam(name string) APIKeyGuardOption {
+	return func(o *apiKeyGuardOptions) {
+		o.queryParam = name
+	}
+}
+
+// NewRequiredHeadersGuard creates a Guard that ensures each of the given
+// headers is present and non-empty on the request. If any are missing, a
+// problem.BadParameters error is returned with one problem.Parameter of type
+// header per missing header, in the order the keys were given.
+func NewRequiredHeadersGuard(keys ...string) GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		var missing []problem.Parameter
+
+		for _, key := range keys {
+			if r.Header.Get(key) == "" {
+				missing = append(missing, problem.Parameter{
+					Parameter: key,
+					Detail:    "is required",
+					Type:      problem.ParameterTypeHeader,
+				})
+			}
+		}
+
+		if len(missing) > 0 {
+			return nil, problem.BadParameters(r, missing...)
+		}
+
+		return r, nil
+	}
+}
+
+// NewAPIKeyGuard creates a Guard that authenticates requests using an API key
+// read from the given header, or from a query parameter when configured with
+// [WithAPIKeyQueryParam]. The key is passed to lookup which may enrich the
+// request context with the resolved principal.
+//
+// A missing key and a key rejected with [ErrAPIKeyInvalid] both result in the
+// same problem.Unauthorized error so that clients cannot distinguish between
+// the two. Problem errors returned by lookup are passed through as is.
+func NewAPIKeyGuard(header string, lookup APIKeyLookupFunc, options ...APIKeyGuardOption) GuardFunc {
+	opts := apiKeyGuardOptions{queryParam: ""}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	return func(r *http.Request) (*http.Request, error) {
+		key := r.Header.Get(header)
+		if key == "" && opts.queryParam != "" && r.URL != nil {
+			key = r.URL.Query().Get(opts.queryParam)
+		}
+
+		if key == "" {
+			return nil, problem.Unauthorized(r)
+		}
+
+		ctx, err := lookup(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrAPIKeyInvalid) {
+				return nil, problem.Unauthorized(r)
+			}
+
+			if _, ok := errors.AsType[*problem.DetailedError](err); ok {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("looking up api key: %w", err)
+		}
+
+		if ctx == nil {
+			return r, nil
+		}
+
+		return r.WithContext(ctx), nil
+	}
+}
+
+// NewStaticAPIKeyLookup creates an APIKeyLookupFunc that accepts any of the
+// given keys. Every key is compared in constant time on each lookup so that
+// the time taken does not reveal whether, or which, key matched. The returned
+// context is ctx unchanged.
+func NewStaticAPIKeyLookup(keys ...string) APIKeyLookupFunc {
+	hashes := make([][sha256.Size]byte, 0, len(keys))
+	for _, key := range keys {
+		hashes = append(hashes, sha256.Sum256([]byte(key)))
+	}
+
+	return func(ctx context.Context, key string) (context.Context, error) {
+		// Hash the key so that every comparison is between equal length values
+		// as subtle.ConstantTimeCompare returns early on a length mismatch.
+		hash := sha256.Sum256([]byte(key))
+
+		match := 0
+		for _, h := range hashes {
+			match |= subtle.ConstantTimeCompare(hash[:], h[:])
+		}
+
+		if match != 1 {
+			return nil, ErrAPIKeyInvalid
+		}
+
+		return ctx, nil
+	}
+}
+
+// AnyGuard creates a Guard that passes if any of the given guards pass (OR
+// semantics), for example to accept either a session cookie or a bearer token.
+// Guards are tried in order and the request returned by the first guard to
+// pass is used. If every guard fails, their errors are joined with
+// errors.Join; as the first problem error in the join is used for the
+// response, order guards so that the most relevant problem comes first. If no
+// guards are given the request is passed through.
+//
+// Use [GuardStack] when every guard must pass (AND semantics).
+func AnyGuard(guards ...Guard) GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		if len(guards) == 0 {
+			return r, nil
+		}
+
+		errs := make([]error, 0, len(guards))
+
+		for _, g := range guards {
+			guardedRequest, err := g.Guard(r)
+			if err == nil {
+				if guardedRequest == nil {
+					return r, nil
+				}
+
+				return guardedRequest, nil
+			}
+
+			errs = append(errs, err)
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// GuardWhen creates a Guard that delegates to g only when predicate reports
+// true for the request, passing the request through unchanged otherwise. This
+// allows a Guard to be applied to a subset of an EndpointGroup, for example to
+// require authentication on mutating methods only:
+//
+//	endpoints.WithGuard(httputil.GuardWhen(func(r *http.Request) bool {
+//		return r.Method != http.MethodGet && r.Method != http.MethodHead
+//	}, authGuard))
+func GuardWhen(predicate func(r *http.Request) bool, g Guard) GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		if !predicate(r) {
+			return r, nil
+		}
+
+		return g.Guard(r) //nolint:wrapcheck // Allow the Guard to determine result.
+	}
+}
+
+// PublicAccessGuard creates a Guard that allows every request. It exists so
+// that exposing sensitive endpoints, such as those registered by
+// [Server.RegisterPprof], without protection is an explicit choice.
+func PublicAccessGuard() GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		return r, nil
+	}
+}
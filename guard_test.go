@@ -0,0 +1,322 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/problem"
+	"github.com/nickbryan/httputil/problem/problemtest"
+)
+
+func TestNewRequiredHeadersGuard(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		keys    []string
+		headers map[string]string
+		wantErr *problem.DetailedError
+	}{
+		"the request passes when all headers are present": {
+			keys:    []string{"X-Tenant-Id", "X-Request-Id"},
+			headers: map[string]string{"X-Tenant-Id": "tenant", "X-Request-Id": "request"},
+			wantErr: nil,
+		},
+		"the request passes when no headers are required": {
+			keys:    nil,
+			headers: nil,
+			wantErr: nil,
+		},
+		"a bad parameters problem is returned listing each missing header": {
+			keys:    []string{"X-Tenant-Id", "X-Request-Id", "X-Trace-Id"},
+			headers: map[string]string{"X-Request-Id": "request"},
+			wantErr: problem.BadParameters(
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				problem.Parameter{Parameter: "X-Tenant-Id", Detail: "is required", Type: problem.ParameterTypeHeader},
+				problem.Parameter{Parameter: "X-Trace-Id", Detail: "is required", Type: problem.ParameterTypeHeader},
+			),
+		},
+		"a header with an empty value is treated as missing": {
+			keys:    []string{"X-Tenant-Id"},
+			headers: map[string]string{"X-Tenant-Id": ""},
+			wantErr: problem.BadParameters(
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				problem.Parameter{Parameter: "X-Tenant-Id", Detail: "is required", Type: problem.ParameterTypeHeader},
+			),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, "/test", nil)
+			for k, v := range testCase.headers {
+				request.Header.Set(k, v)
+			}
+
+			guardedRequest, err := httputil.GuardStack{httputil.NewRequiredHeadersGuard(testCase.keys...)}.Guard(request)
+
+			assertGuardProblem(t, err, testCase.wantErr)
+
+			if testCase.wantErr == nil && guardedRequest != request {
+				t.Error("expected the original request to be returned")
+			}
+		})
+	}
+}
+
+func assertGuardProblem(t *testing.T, err error, want *problem.DetailedError) {
+	t.Helper()
+
+	if want == nil {
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		return
+	}
+
+	got, ok := errors.AsType[*problem.DetailedError](err)
+	if !ok {
+		t.Fatalf("expected a *problem.DetailedError, got: %+v", err)
+	}
+
+	if equal, diff := problemtest.Equal(got, want); !equal {
+		t.Errorf("problem mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewAPIKeyGuard(t *testing.T) {
+	t.Parallel()
+
+	type principalCtxKey struct{}
+
+	lookup := func(ctx context.Context, key string) (context.Context, error) {
+		switch key {
+		case "valid-key":
+			return context.WithValue(ctx, principalCtxKey{}, "service-a"), nil
+		case "broken-key":
+			return nil, errors.New("some error")
+		default:
+			return nil, httputil.ErrAPIKeyInvalid
+		}
+	}
+
+	testCases := map[string]struct {
+		options       []httputil.APIKeyGuardOption
+		target        string
+		headers       map[string]string
+		wantErr       *problem.DetailedError
+		wantPrincipal string
+	}{
+		"an unauthorized problem is returned when the key is missing": {
+			target:  "/test",
+			wantErr: problem.Unauthorized(httptest.NewRequest(http.MethodGet, "/test", nil)),
+		},
+		"an unauthorized problem is returned when the key is invalid": {
+			target:  "/test",
+			headers: map[string]string{"X-Api-Key": "invalid-key"},
+			wantErr: problem.Unauthorized(httptest.NewRequest(http.MethodGet, "/test", nil)),
+		},
+		"the request context is enriched with the principal when the key is valid": {
+			target:        "/test",
+			headers:       map[string]string{"X-Api-Key": "valid-key"},
+			wantPrincipal: "service-a",
+		},
+		"the key is read from the query parameter when configured and the header is missing": {
+			options:       []httputil.APIKeyGuardOption{httputil.WithAPIKeyQueryParam("api_key")},
+			target:        "/test?api_key=valid-key",
+			wantPrincipal: "service-a",
+		},
+		"the key is not read from the query parameter when not configured": {
+			target:  "/test?api_key=valid-key",
+			wantErr: problem.Unauthorized(httptest.NewRequest(http.MethodGet, "/test", nil)),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			for k, v := range testCase.headers {
+				request.Header.Set(k, v)
+			}
+
+			guardedRequest, err := httputil.NewAPIKeyGuard("X-Api-Key", lookup, testCase.options...).Guard(request)
+
+			assertGuardProblem(t, err, testCase.wantErr)
+
+			if testCase.wantErr != nil {
+				return
+			}
+
+			if principal, _ := guardedRequest.Context().Value(principalCtxKey{}).(string); principal != testCase.wantPrincipal {
+				t.Errorf("principal = %q, want %q", principal, testCase.wantPrincipal)
+			}
+		})
+	}
+
+	t.Run("lookup errors other than ErrAPIKeyInvalid are returned as unhandled errors", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+		request.Header.Set("X-Api-Key", "broken-key")
+
+		_, err := httputil.NewAPIKeyGuard("X-Api-Key", lookup).Guard(request)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if _, ok := errors.AsType[*problem.DetailedError](err); ok {
+			t.Errorf("expected an unhandled error, got problem: %+v", err)
+		}
+	})
+}
+
+func TestNewStaticAPIKeyLookup(t *testing.T) {
+	t.Parallel()
+
+	lookup := httputil.NewStaticAPIKeyLookup("key-one", "key-two")
+
+	for _, key := range []string{"key-one", "key-two"} {
+		if _, err := lookup(t.Context(), key); err != nil {
+			t.Errorf("lookup(%q) returned unexpected error: %+v", key, err)
+		}
+	}
+
+	for _, key := range []string{"", "key", "key-three", "key-one-extra"} {
+		if _, err := lookup(t.Context(), key); !errors.Is(err, httputil.ErrAPIKeyInvalid) {
+			t.Errorf("lookup(%q) error = %v, want %v", key, err, httputil.ErrAPIKeyInvalid)
+		}
+	}
+}
+
+func TestAnyGuard(t *testing.T) {
+	t.Parallel()
+
+	unauthorizedGuard := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+		return nil, problem.Unauthorized(r)
+	})
+	forbiddenGuard := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+		return nil, problem.Forbidden(r)
+	})
+
+	t.Run("passes when the second guard succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+		guardedRequest, err := httputil.AnyGuard(unauthorizedGuard, addToContextGuard("second")).Guard(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		if v, _ := guardedRequest.Context().Value(addToContextGuardCtxKey{}).(addToContextGuard); v != "second" {
+			t.Errorf("expected the request from the passing guard to be returned, got context value: %q", v)
+		}
+	})
+
+	t.Run("does not call later guards once a guard succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		called := false
+		tracking := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+			called = true
+			return r, nil
+		})
+
+		if _, err := httputil.AnyGuard(noopGuard{}, tracking).Guard(httptest.NewRequest(http.MethodGet, "/test", nil)); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		if called {
+			t.Error("expected the second guard not to be called")
+		}
+	})
+
+	t.Run("uses the original request when the passing guard returns nil", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+		nilGuard := httputil.GuardFunc(func(_ *http.Request) (*http.Request, error) { return nil, nil })
+
+		guardedRequest, err := httputil.AnyGuard(nilGuard).Guard(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		if guardedRequest != request {
+			t.Error("expected the original request to be returned")
+		}
+	})
+
+	t.Run("fails with all errors when every guard fails", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+		_, err := httputil.AnyGuard(unauthorizedGuard, forbiddenGuard, errorGuard{}).Guard(request)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		assertGuardProblem(t, err, problem.Unauthorized(request))
+
+		if !strings.Contains(err.Error(), problem.Forbidden(request).Error()) || !strings.Contains(err.Error(), "some error") {
+			t.Errorf("expected all guard errors to be aggregated, got: %q", err.Error())
+		}
+	})
+
+	t.Run("passes through when no guards are given", func(t *testing.T) {
+		t.Parallel()
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+		guardedRequest, err := httputil.AnyGuard().Guard(request)
+		if err != nil || guardedRequest != request {
+			t.Errorf("expected the request to pass through, got request: %p, err: %+v", guardedRequest, err)
+		}
+	})
+}
+
+func TestGuardWhen(t *testing.T) {
+	t.Parallel()
+
+	isPost := func(r *http.Request) bool { return r.Method == http.MethodPost }
+
+	testCases := map[string]struct {
+		method  string
+		wantErr *problem.DetailedError
+	}{
+		"the guard runs when the predicate matches": {
+			method:  http.MethodPost,
+			wantErr: problem.BadRequest(httptest.NewRequest(http.MethodPost, "/test", nil)),
+		},
+		"the request passes through when the predicate does not match": {
+			method:  http.MethodGet,
+			wantErr: nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(testCase.method, "/test", nil)
+
+			guardedRequest, err := httputil.GuardWhen(isPost, problemGuard{}).Guard(request)
+
+			assertGuardProblem(t, err, testCase.wantErr)
+
+			if testCase.wantErr == nil && guardedRequest != request {
+				t.Error("expected the original request to be returned")
+			}
+		})
+	}
+}
@@ -1,17 +1,23 @@
 package httputil
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"os"
 	"reflect"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 
 	"github.com/nickbryan/httputil/problem"
 )
@@ -41,6 +47,15 @@ type (
 	Transformer interface {
 		Transform(ctx context.Context) error
 	}
+
+	// PostHydrateTransformer allows for operations to be performed on the whole
+	// Request once both its data and params have been decoded, validated and
+	// transformed, but before the Action is called. This is useful for deriving
+	// fields that depend on both the request body and its params. It is checked
+	// for on pointers to the request data and params, in that order.
+	PostHydrateTransformer[D, P any] interface {
+		PostHydrate(r *Request[D, P]) error
+	}
 )
 
 // GuardFunc is a function type for modifying or inspecting an HTTP
@@ -70,13 +85,20 @@ type (
 		// ResponseWriter is an embedded HTTP response writer used to construct and send
 		// the HTTP response. When writing a response via the ResponseWriter directly, it
 		// is best practice to return a [NothingToHandle] response so that the handler
-		// does not try to encode response data or handle errors.
+		// does not try to encode response data or handle errors. A Response returned
+		// after the header has been written is not written and a warning is logged.
 		ResponseWriter http.ResponseWriter
 		// Errors provides access to any validation or binding errors that occurred
 		// during request processing. This is populated when using [NewFormHandler].
 		// Use [BindErrors.HasAny], [BindErrors.Get], and [BindErrors.All] to
 		// inspect and render errors in templates.
 		Errors BindErrors
+
+		// logger is the logger of the handler with the log attributes of the
+		// request added.
+		logger *slog.Logger
+		// startedAt is the time at which the handler started serving the request.
+		startedAt time.Time
 	}
 
 	// RequestData represents a Request that expects data but no Params.
@@ -87,28 +109,39 @@ type (
 	// RequestEmpty represents an empty Request that expects no Params or data.
 	// It's a type alias for Request with empty structs for both data and Params.
 	// Use this type when your handler doesn't need to process any request body or URL parameters.
+	// The request body is not read, so the Action can stream it, for example
+	// with io.Copy, without it being buffered.
 	RequestEmpty = Request[struct{}, struct{}]
 
 	// RequestParams represents a Request that expects Params but no data.
 	// It's a type alias for Request with an empty struct for data and a generic Params type P.
 	// Use this type when your handler needs to process URL parameters but doesn't need request body data.
+	// As with RequestEmpty, the request body is left unread for the Action to stream.
 	RequestParams[P any] = Request[struct{}, P]
 
 	// Response represents an HTTP response that holds optional data and the
 	// required information to write a response.
 	Response struct {
 		code     int
+		cookies  []*http.Cookie
 		data     any
+		header   http.Header
 		redirect string
+		// requireLocation reports whether a warning should be logged when the
+		// response is written without a Location header.
+		requireLocation bool
 	}
 )
 
 // NewResponse creates a new Response object with the given status code and data.
 func NewResponse(code int, data any) *Response {
 	return &Response{
-		code:     code,
-		data:     data,
-		redirect: "",
+		code:            code,
+		cookies:         nil,
+		data:            data,
+		header:          nil,
+		redirect:        "",
+		requireLocation: false,
 	}
 }
 
@@ -116,9 +149,12 @@ func NewResponse(code int, data any) *Response {
 // http.StatusAccepted (202 Accepted) and the given data.
 func Accepted(data any) (*Response, error) {
 	return &Response{
-		code:     http.StatusAccepted,
-		data:     data,
-		redirect: "",
+		code:            http.StatusAccepted,
+		cookies:         nil,
+		data:            data,
+		header:          nil,
+		redirect:        "",
+		requireLocation: false,
 	}, nil
 }
 
@@ -126,9 +162,34 @@ func Accepted(data any) (*Response, error) {
 // http.StatusCreated (201 Created) and the given data.
 func Created(data any) (*Response, error) {
 	return &Response{
-		code:     http.StatusCreated,
-		data:     data,
-		redirect: "",
+		code:            http.StatusCreated,
+		cookies:         nil,
+		data:            data,
+		header:          nil,
+		redirect:        "",
+		requireLocation: false,
+	}, nil
+}
+
+// CreatedResource creates a new Response object with a status code of
+// http.StatusCreated (201 Created), the given data and a Location header
+// pointing at the created resource. See [AbsoluteURL] for building location
+// behind a proxy. If location is empty, the response is still written but the
+// handler logs a warning, as a 201 response should identify the resource that
+// was created.
+func CreatedResource(location string, data any) (*Response, error) {
+	var header http.Header
+	if location != "" {
+		header = http.Header{"Location": {location}}
+	}
+
+	return &Response{
+		code:            http.StatusCreated,
+		cookies:         nil,
+		data:            data,
+		header:          header,
+		redirect:        "",
+		requireLocation: true,
 	}, nil
 }
 
@@ -136,9 +197,12 @@ func Created(data any) (*Response, error) {
 // http.StatusNoContent (204 No Content) and an empty struct as data.
 func NoContent() (*Response, error) {
 	return &Response{
-		code:     http.StatusNoContent,
-		data:     nil,
-		redirect: "",
+		code:            http.StatusNoContent,
+		cookies:         nil,
+		data:            nil,
+		header:          nil,
+		redirect:        "",
+		requireLocation: false,
 	}, nil
 }
 
@@ -154,9 +218,12 @@ func NothingToHandle() (*Response, error) {
 // provided data.
 func OK(data any) (*Response, error) {
 	return &Response{
-		code:     http.StatusOK,
-		data:     data,
-		redirect: "",
+		code:            http.StatusOK,
+		cookies:         nil,
+		data:            data,
+		header:          nil,
+		redirect:        "",
+		requireLocation: false,
 	}, nil
 }
 
@@ -165,12 +232,37 @@ func OK(data any) (*Response, error) {
 // indicate to the handler that a redirect should be written.
 func Redirect(code int, url string) (*Response, error) {
 	return &Response{
-		code:     code,
-		data:     nil,
-		redirect: url,
+		code:            code,
+		cookies:         nil,
+		data:            nil,
+		header:          nil,
+		redirect:        url,
+		requireLocation: false,
 	}, nil
 }
 
+// WithCookie adds a cookie to be set on the response. Each cookie is written as
+// its own Set-Cookie header. It returns the Response to allow chaining.
+func (r *Response) WithCookie(cookie *http.Cookie) *Response {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// DeleteCookie adds an expired cookie with the given name and a path of "/" to
+// the response, instructing the client to delete it. Use [Response.WithCookie]
+// with MaxAge set to -1 to delete a cookie with a different path or domain.
+// It returns the Response to allow chaining.
+func (r *Response) DeleteCookie(name string) *Response {
+	//nolint:exhaustruct // Only the fields required to expire the cookie are set.
+	return r.WithCookie(&http.Cookie{
+		Name:    name,
+		Value:   "",
+		Path:    "/",
+		Expires: time.Unix(0, 0),
+		MaxAge:  -1,
+	})
+}
+
 // Ensure that our handler implements the http.Handler interface.
 var _ http.Handler = &handler[any, any]{} //nolint:exhaustruct // Compile time implementation check.
 
@@ -178,10 +270,21 @@ type handler[D, P any] struct {
 	resolveOnce                 sync.Once
 	action                      Action[D, P]
 	bindErrorPassthrough        bool
+	bodyReadTimeout             time.Duration
 	codec                       ServerCodec
+	debugErrors                 bool
+	defaultContentType          string
+	deprecation                 *deprecation
+	errorMappers                []ErrorMapper
 	guard                       Guard
+	logAttributes               LogAttributesFunc
 	logger                      *slog.Logger
 	messageFunc                 MessageFunc
+	problemInstance             ProblemInstanceFunc
+	requestSchema               *jsonschema.Schema
+	responseSchema              *jsonschema.Schema
+	responseValidation          responseValidationMode
+	sparseFieldsets             bool
 	reqTypeKind, paramsTypeKind reflect.Kind
 }
 
@@ -210,6 +313,24 @@ func NewFormHandler[D, P any](action Action[D, P], options ...HandlerOption) htt
 func newHandler[D, P any](action Action[D, P], bindErrorPassthrough bool, options []HandlerOption) http.Handler {
 	opts := mapHandlerOptionsToDefaults(options)
 
+	var requestSchema *jsonschema.Schema
+
+	if opts.requestSchema != nil && !bindErrorPassthrough {
+		var err error
+		if requestSchema, err = compileSchema(opts.requestSchema); err != nil {
+			panic(fmt.Sprintf("httputil: handler %T has an invalid request schema: %v", action, err))
+		}
+	}
+
+	var responseSchema *jsonschema.Schema
+
+	if opts.responseSchema != nil {
+		var err error
+		if responseSchema, err = compileSchema(opts.responseSchema); err != nil {
+			panic(fmt.Sprintf("httputil: handler %T has an invalid response schema: %v", action, err))
+		}
+	}
+
 	return &handler[D, P]{
 		resolveOnce: sync.Once{},
 		action:      action,
@@ -218,18 +339,90 @@ func newHandler[D, P any](action Action[D, P], bindErrorPassthrough bool, option
 		paramsTypeKind: reflect.TypeFor[P]().Kind(),
 		//
 		bindErrorPassthrough: bindErrorPassthrough,
+		bodyReadTimeout:      opts.bodyReadTimeout,
+		defaultContentType:   opts.defaultContentType,
+		deprecation:          opts.deprecation,
 		messageFunc:          opts.messageFunc,
+		requestSchema:        requestSchema,
+		responseSchema:       responseSchema,
+		sparseFieldsets:      opts.sparseFieldsets,
 		// codec and logger are resolved via sync.Once on first request if not
-		// set by options. guard is read from context per-request when
+		// set by options, as are debugErrors, errorMappers, logAttributes,
+		// problemInstance and responseValidation. guard is read from context per-request when
 		// WithHandlerGuard is not used.
-		codec:  opts.codec,
-		guard:  opts.guard,
-		logger: opts.logger,
+		codec:              opts.codec,
+		debugErrors:        false,
+		errorMappers:       nil,
+		guard:              opts.guard,
+		logAttributes:      nil,
+		logger:             opts.logger,
+		problemInstance:    nil,
+		responseValidation: responseValidationOff,
 	}
 }
 
-// resolve sets codec and logger from handlerContext. Fields already set via
-// handler options are not overwritten. Guard is NOT resolved here -- it is
+// exampleValidator is implemented by handlers that can check an [Example]
+// request payload against their data type.
+type exampleValidator interface {
+	validateExample(codec ServerCodec, example Example) error
+}
+
+// errExampleUnexpectedRequest is returned when an [Example] has a request
+// payload but the handler does not expect request data.
+var errExampleUnexpectedRequest = errors.New("handler does not expect request data")
+
+// errExampleSchemaViolation is returned when an [Example] request payload does
+// not satisfy the handler's request schema.
+var errExampleSchemaViolation = errors.New("example request violates request schema")
+
+// validateExample decodes the example request into a new D and validates it.
+// The handler's own codec takes precedence over codec.
+func (h *handler[D, P]) validateExample(codec ServerCodec, example Example) error {
+	if h.codec != nil {
+		codec = h.codec
+	}
+
+	var data D
+	if isEmpty(data) {
+		if len(example.Request) > 0 {
+			return errExampleUnexpectedRequest
+		}
+
+		return nil
+	}
+
+	if h.requestSchema != nil {
+		properties, err := validateSchema(h.requestSchema, example.Request)
+		if err != nil {
+			return fmt.Errorf("validating example request schema: %w", err)
+		}
+
+		if len(properties) > 0 {
+			return fmt.Errorf("%w: %s %s", errExampleSchemaViolation, properties[0].Pointer, properties[0].Detail)
+		}
+	}
+
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewReader(example.Request))
+	if err != nil {
+		return fmt.Errorf("creating example request: %w", err)
+	}
+
+	if err = codec.Decode(r, &data); err != nil {
+		return fmt.Errorf("decoding example request: %w", err)
+	}
+
+	if h.reqTypeKind == reflect.Struct {
+		if err = validate.Struct(&data); err != nil {
+			return fmt.Errorf("validating example request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resolve sets codec, logger, error mappers, problem instance and response
+// validation from handlerContext.
+// Fields already set via handler options are not overwritten. Guard is NOT resolved here -- it is
 // read per-request in ServeHTTP so the same handler works across endpoints
 // with different guards.
 //
@@ -246,9 +439,26 @@ func (h *handler[D, P]) resolve(hc *handlerContext) {
 		if h.logger == nil {
 			h.logger = hc.logger
 		}
+
+		h.debugErrors = hc.debugErrors
+		h.errorMappers = hc.errorMappers
+		h.logAttributes = hc.logAttributes
+		h.problemInstance = hc.problemInstance
+		h.responseValidation = hc.responseValidation
 	})
 }
 
+// requestCodec returns the codec selected for r by the extension suffix of its
+// path, see [WithServerExtensionNegotiation], falling back to the codec of the
+// handler.
+func (h *handler[D, P]) requestCodec(r *http.Request) ServerCodec {
+	if codec := extensionCodecFromContext(r.Context()); codec != nil {
+		return codec
+	}
+
+	return h.codec
+}
+
 // ServeHTTP implements the http.Handler interface. It reads the request body,
 // decodes it into the request data, validates it if a validator is set, calls
 // the wrapped Action, and writes the response back in JSON format.
@@ -262,14 +472,28 @@ func (h *handler[D, P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		panic(fmt.Sprintf("httputil: handler %T served without being registered on a Server (missing codec)", h))
 	}
 
-	if h.logger == nil {
-		panic(fmt.Sprintf("httputil: handler %T served without being registered on a Server (missing logger)", h))
+	startedAt := time.Now()
+	w, r = countBodySizes(w, r)
+
+	tracker := &headerTrackingWriter{ResponseWriter: w, wroteHeader: false}
+	w = tracker
+
+	logger := requestLogger(h.logger, r, h.logAttributes)
+
+	defer closeRequestBody(r.Context(), logger, r.Body, startedAt)
+
+	if h.deprecation != nil {
+		h.deprecation.setHeaders(w.Header())
+		logger.WarnContext(r.Context(), "Request made to a deprecated endpoint", slog.Time("sunset", h.deprecation.sunset))
 	}
 
-	defer closeRequestBody(r.Context(), h.logger, r.Body)
+	if h.defaultContentType != "" {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w = &defaultContentTypeWriter{ResponseWriter: w, contentType: h.defaultContentType}
+	}
 
 	//nolint:exhaustruct // Zero value for D and P is unknown.
-	request := Request[D, P]{Request: r, ResponseWriter: w}
+	request := Request[D, P]{Request: r, ResponseWriter: w, logger: logger, startedAt: startedAt}
 
 	guard := h.guard
 	if guard == nil && hc != nil {
@@ -285,12 +509,30 @@ func (h *handler[D, P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := postHydrate(&request); err != nil {
+		request.logger.WarnContext(r.Context(), "Handler failed to post-hydrate transform request", slog.Any("error", err), durationAttr(request.startedAt))
+		h.writeErrorResponse(r.Context(), &request, problem.ServerError(request.Request))
+
+		return
+	}
+
 	response, err := h.action(request)
 	if err != nil {
 		h.writeErrorResponse(r.Context(), &request, fmt.Errorf("calling action: %w", err))
 		return
 	}
 
+	if response != nil && tracker.wroteHeader {
+		request.logger.WarnContext(
+			r.Context(),
+			"Handler returned a response after writing to the ResponseWriter directly, return NothingToHandle instead",
+			slog.Int("status", response.code),
+			durationAttr(request.startedAt),
+		)
+
+		return
+	}
+
 	h.writeSuccessfulResponse(&request, response)
 }
 
@@ -308,6 +550,9 @@ func (h *handler[D, P]) protect(req *Request[D, P], guard Guard) error {
 
 	if protectedRequest != nil {
 		req.Request = protectedRequest
+		// Guards may add values, such as the authenticated user, that the log
+		// attributes are taken from.
+		req.logger = requestLogger(h.logger, protectedRequest, h.logAttributes)
 	}
 
 	return nil
@@ -324,7 +569,17 @@ func (h *handler[D, P]) requestHydratedOK(req *Request[D, P]) bool {
 		return true
 	}
 
-	if err := h.codec.Decode(req.Request, &req.Data); err != nil {
+	clearBodyReadDeadline := h.setBodyReadDeadline(req)
+
+	if !h.requestSchemaSatisfied(req) {
+		clearBodyReadDeadline()
+		return false
+	}
+
+	err := h.requestCodec(req.Request).Decode(req.Request, &req.Data)
+	clearBodyReadDeadline()
+
+	if err != nil {
 		if h.bindErrorPassthrough {
 			req.Errors.setDataError(err, h.messageFunc)
 			return true
@@ -332,10 +587,13 @@ func (h *handler[D, P]) requestHydratedOK(req *Request[D, P]) bool {
 
 		problemErr := problem.BadRequest(req.Request)
 
-		if errors.Is(err, io.EOF) {
+		switch detail := bodyReadErrorDetail(err); {
+		case errors.Is(err, io.EOF):
 			problemErr = problem.BadRequest(req.Request).WithDetail("The server received an unexpected empty request body")
-		} else {
-			h.logger.WarnContext(req.Context(), "Handler failed to decode request data", slog.Any("error", err))
+		case detail != "":
+			problemErr = problem.BadRequest(req.Request).WithDetail(detail)
+		default:
+			req.logger.WarnContext(req.Context(), "Handler failed to decode request data", slog.Any("error", err), durationAttr(req.startedAt))
 		}
 
 		h.writeErrorResponse(req.Context(), req, problemErr)
@@ -357,7 +615,7 @@ func (h *handler[D, P]) requestHydratedOK(req *Request[D, P]) bool {
 	}
 
 	if err := transform(req.Context(), &req.Data); err != nil {
-		h.logger.WarnContext(req.Context(), "Handler failed to transform request data", slog.Any("error", err))
+		req.logger.WarnContext(req.Context(), "Handler failed to transform request data", slog.Any("error", err), durationAttr(req.startedAt))
 		h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
 
 		return false
@@ -366,6 +624,98 @@ func (h *handler[D, P]) requestHydratedOK(req *Request[D, P]) bool {
 	return true
 }
 
+// bodyReadTimeoutDetail is the problem detail used when the request body is not
+// read within the body read timeout.
+const bodyReadTimeoutDetail = "The server did not receive the request body in time"
+
+// bodyReadErrorDetail returns the problem detail for an error reading the
+// request body that was caused by the client, or an empty string if err was
+// not.
+func bodyReadErrorDetail(err error) string {
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return bodyReadTimeoutDetail
+	case errors.Is(err, ErrContentLengthMismatch):
+		return contentLengthMismatchDetail
+	case errors.Is(err, ErrTrailingJSONData):
+		return trailingJSONDataDetail
+	default:
+		return ""
+	}
+}
+
+// setBodyReadDeadline sets the read deadline of the connection to the body read
+// timeout, if one is set, and returns a function that clears it. Writers that do
+// not support read deadlines are left without one.
+func (h *handler[D, P]) setBodyReadDeadline(req *Request[D, P]) func() {
+	if h.bodyReadTimeout <= 0 {
+		return func() {}
+	}
+
+	controller := http.NewResponseController(req.ResponseWriter)
+	if err := controller.SetReadDeadline(time.Now().Add(h.bodyReadTimeout)); err != nil {
+		if !errors.Is(err, http.ErrNotSupported) {
+			req.logger.WarnContext(req.Context(), "Handler failed to set the body read deadline", slog.Any("error", err), durationAttr(req.startedAt))
+		}
+
+		return func() {}
+	}
+
+	return func() {
+		if err := controller.SetReadDeadline(time.Time{}); err != nil {
+			req.logger.WarnContext(req.Context(), "Handler failed to clear the body read deadline", slog.Any("error", err), durationAttr(req.startedAt))
+		}
+	}
+}
+
+// requestSchemaSatisfied validates the raw request body against the request
+// schema, if one is set, writing a constraint violation response on failure.
+// The body is restored so that it can be decoded afterwards. Bodies that are
+// empty or not valid JSON are left for the codec to reject.
+func (h *handler[D, P]) requestSchemaSatisfied(req *Request[D, P]) bool {
+	if h.requestSchema == nil || req.Body == nil {
+		return true
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		if detail := bodyReadErrorDetail(err); detail != "" {
+			h.writeErrorResponse(req.Context(), req, problem.BadRequest(req.Request).WithDetail(detail))
+			return false
+		}
+
+		req.logger.WarnContext(req.Context(), "Handler failed to read request body", slog.Any("error", err), durationAttr(req.startedAt))
+		h.writeErrorResponse(req.Context(), req, problem.BadRequest(req.Request))
+
+		return false
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(body) == 0 {
+		return true
+	}
+
+	properties, err := validateSchema(h.requestSchema, bytes.TrimPrefix(body, []byte(utf8BOM)))
+	if err != nil {
+		if !errors.Is(err, errSchemaUnmarshal) {
+			req.logger.ErrorContext(req.Context(), "Handler failed to validate request schema", slog.Any("error", err), durationAttr(req.startedAt))
+			h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
+
+			return false
+		}
+
+		return true
+	}
+
+	if len(properties) > 0 {
+		h.writeErrorResponse(req.Context(), req, problem.ConstraintViolation(req.Request, properties...))
+		return false
+	}
+
+	return true
+}
+
 // paramsHydratedOK checks if the request parameters are valid, hydrated, and
 // successfully transformed without errors.
 func (h *handler[D, P]) paramsHydratedOK(req *Request[D, P]) bool {
@@ -374,7 +724,7 @@ func (h *handler[D, P]) paramsHydratedOK(req *Request[D, P]) bool {
 	}
 
 	if h.paramsTypeKind != reflect.Struct {
-		h.logger.WarnContext(req.Context(), "Handler params type is not a struct", slog.String("type", h.paramsTypeKind.String()))
+		req.logger.WarnContext(req.Context(), "Handler params type is not a struct", slog.String("type", h.paramsTypeKind.String()), durationAttr(req.startedAt))
 		h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
 
 		return false
@@ -387,7 +737,7 @@ func (h *handler[D, P]) paramsHydratedOK(req *Request[D, P]) bool {
 		}
 
 		if _, ok := errors.AsType[*problem.DetailedError](err); !ok {
-			h.logger.WarnContext(req.Context(), "Handler failed to decode params data", slog.Any("error", err))
+			req.logger.WarnContext(req.Context(), "Handler failed to decode params data", slog.Any("error", err), durationAttr(req.startedAt))
 			h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
 
 			return false
@@ -399,7 +749,7 @@ func (h *handler[D, P]) paramsHydratedOK(req *Request[D, P]) bool {
 	}
 
 	if err := transform(req.Context(), &req.Params); err != nil {
-		h.logger.WarnContext(req.Context(), "Handler failed to transform params data", slog.Any("error", err))
+		req.logger.WarnContext(req.Context(), "Handler failed to transform params data", slog.Any("error", err), durationAttr(req.startedAt))
 		h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
 
 		return false
@@ -415,64 +765,250 @@ func (h *handler[D, P]) writeSuccessfulResponse(req *Request[D, P], res *Respons
 		return
 	}
 
+	if res.requireLocation && res.header.Get("Location") == "" {
+		req.logger.WarnContext(req.Context(), "Handler created a resource without a Location header", durationAttr(req.startedAt))
+	}
+
 	if res.redirect != "" {
+		setResponseHeaders(req.ResponseWriter, res)
 		http.Redirect(req.ResponseWriter, req.Request, res.redirect, res.code)
 		return
 	}
 
 	if res.data == nil {
+		setResponseHeaders(req.ResponseWriter, res)
 		req.ResponseWriter.WriteHeader(res.code)
 		return
 	}
 
 	if err := transform(req.Context(), res.data); err != nil {
-		h.logger.WarnContext(req.Context(), "Handler failed to transform response data", slog.Any("error", err))
+		req.logger.WarnContext(req.Context(), "Handler failed to transform response data", slog.Any("error", err), durationAttr(req.startedAt))
 		h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
 
 		return
 	}
 
-	if err := h.codec.Encode(req.ResponseWriter, res.code, res.data); err != nil {
-		h.logger.ErrorContext(req.Context(), "Handler failed to encode response data", slog.Any("error", err))
+	if h.responseValidation != responseValidationOff {
+		if !h.responseContractSatisfied(req, res.data) && h.responseValidation == responseValidationFail {
+			h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
+			return
+		}
+	}
+
+	data := res.data
+
+	if fields := requestedFields(req.Request); h.sparseFieldsets && len(fields) > 0 {
+		projected, err := SparseFieldset(data, fields)
+		if err != nil {
+			if errors.Is(err, ErrUnknownField) {
+				h.writeErrorResponse(req.Context(), req, unknownFieldProblem(req.Request, err))
+				return
+			}
+
+			req.logger.WarnContext(req.Context(), "Handler failed to project response data to sparse fieldset", slog.Any("error", err), durationAttr(req.startedAt))
+			h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
+
+			return
+		}
+
+		data = projected
+	}
+
+	setResponseHeaders(req.ResponseWriter, res)
+
+	if err := h.requestCodec(req.Request).Encode(req.ResponseWriter, res.code, data); err != nil {
+		req.logger.Log(req.Context(), encodeErrorLevel(err), "Handler failed to encode response data", slog.Any("error", err), durationAttr(req.startedAt))
 	}
 }
 
+// responseContractSatisfied validates response data against the response
+// schema, or its validate struct tags when no schema is set, logging any
+// violation of the contract.
+func (h *handler[D, P]) responseContractSatisfied(req *Request[D, P], data any) bool {
+	if h.responseSchema != nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			req.logger.ErrorContext(req.Context(), "Handler failed to marshal response data for validation", slog.Any("error", err), durationAttr(req.startedAt))
+			return false
+		}
+
+		properties, err := validateSchema(h.responseSchema, body)
+		if err != nil {
+			req.logger.ErrorContext(req.Context(), "Handler failed to validate response schema", slog.Any("error", err), durationAttr(req.startedAt))
+			return false
+		}
+
+		if len(properties) > 0 {
+			req.logger.ErrorContext(req.Context(), "Handler response violated its contract", slog.Any("violations", properties), durationAttr(req.startedAt))
+			return false
+		}
+
+		return true
+	}
+
+	if reflect.Indirect(reflect.ValueOf(data)).Kind() != reflect.Struct {
+		return true
+	}
+
+	if err := validate.StructCtx(req.Context(), data); err != nil {
+		req.logger.ErrorContext(req.Context(), "Handler response violated its contract", slog.Any("error", err), durationAttr(req.startedAt))
+		return false
+	}
+
+	return true
+}
+
 // writeValidationErr handles validation errors by constructing detailed problem
 // objects and writing error responses. If the error is not a validation error,
 // it logs the error and sends a generic server error response.
 func (h *handler[D, P]) writeValidationErr(req *Request[D, P], err error) {
 	if errs, ok := errors.AsType[validator.ValidationErrors](err); ok {
-		properties := make([]problem.Property, 0, len(errs))
-		for _, err := range errs {
-			msg := describeValidationError(err)
-			if h.messageFunc != nil {
-				msg = h.messageFunc(err.Tag(), err.Param())
-			}
+		h.writeErrorResponse(req.Context(), req, problem.ConstraintViolation(req.Request, validationProperties(errs, h.messageFunc)...))
+		return
+	}
 
-			properties = append(properties, problem.Property{Detail: msg, Pointer: "/" + strings.Join(strings.Split(err.Namespace(), ".")[1:], "/")})
-		}
+	req.logger.ErrorContext(req.Context(), "Handler failed to validate request data", slog.Any("error", err), durationAttr(req.startedAt))
+	h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
+}
 
-		h.writeErrorResponse(req.Context(), req, problem.ConstraintViolation(req.Request, properties...))
+// validationProperties converts validation errors to problem properties, with
+// a JSON pointer to each invalid field. Messages are generated by fn when it is
+// not nil.
+func validationProperties(errs validator.ValidationErrors, fn MessageFunc) []problem.Property {
+	properties := make([]problem.Property, 0, len(errs))
+	for _, err := range errs {
+		msg := describeValidationError(err)
+		if fn != nil {
+			msg = fn(err.Tag(), err.Param())
+		}
 
-		return
+		properties = append(properties, problem.Property{Detail: msg, Pointer: "/" + strings.Join(strings.Split(err.Namespace(), ".")[1:], "/")})
 	}
 
-	h.logger.ErrorContext(req.Context(), "Handler failed to validate request data", slog.Any("error", err))
-	h.writeErrorResponse(req.Context(), req, problem.ServerError(req.Request))
+	return properties
 }
 
 // writeErrorResponse writes an HTTP error response using the provided error and
-// request context, with support for problem details.
+// request context, with support for problem details and sentinel errors.
 func (h *handler[D, P]) writeErrorResponse(ctx context.Context, req *Request[D, P], err error) {
-	problemDetails, ok := errors.AsType[*problem.DetailedError](err)
+	problemDetails, ok := problemFromError(req.Request, err, h.errorMappers)
 	if !ok {
 		problemDetails = problem.ServerError(req.Request)
+		if h.debugErrors {
+			problemDetails = withDebugExtensions(problemDetails, err)
+		}
+
+		req.logger.ErrorContext(ctx, "Handler received an unhandled error", slog.Any("error", err), durationAttr(req.startedAt))
+	}
+
+	problemDetails = applyProblemInstance(req.Request, problemDetails, h.problemInstance)
+
+	if err = h.requestCodec(req.Request).EncodeError(req.ResponseWriter, problemDetails.Status, problemDetails); err != nil {
+		req.logger.ErrorContext(ctx, "Handler failed to encode error data", slog.Any("error", err), durationAttr(req.startedAt))
+	}
+}
+
+// headerTrackingWriter is a http.ResponseWriter that records whether the
+// response header has been written.
+type headerTrackingWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+}
+
+// WriteHeader records that the header has been written, unless statusCode is
+// informational, and writes it to the underlying http.ResponseWriter.
+func (w *headerTrackingWriter) WriteHeader(statusCode int) {
+	if statusCode >= http.StatusOK || statusCode == http.StatusSwitchingProtocols {
+		w.wroteHeader = true
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
 
-		h.logger.ErrorContext(ctx, "Handler received an unhandled error", slog.Any("error", err))
+// Write records that the header has been written, as it is written implicitly
+// with the first Write, and writes b to the underlying http.ResponseWriter.
+func (w *headerTrackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+
+	return w.ResponseWriter.Write(b) //nolint:wrapcheck // Errors are returned unchanged so that the writer is transparent.
+}
+
+// Flush records that the header has been written, as it is written implicitly
+// when the response is flushed, and flushes the underlying http.ResponseWriter
+// if it supports flushing.
+func (w *headerTrackingWriter) Flush() {
+	if err := http.NewResponseController(w.ResponseWriter).Flush(); err == nil {
+		w.wroteHeader = true
 	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter for use with
+// http.ResponseController.
+func (w *headerTrackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// defaultContentTypeWriter is a http.ResponseWriter that sets a default
+// Content-Type before the header is written if one has not been set.
+type defaultContentTypeWriter struct {
+	http.ResponseWriter
 
-	if err = h.codec.EncodeError(req.ResponseWriter, problemDetails.Status, problemDetails); err != nil {
-		h.logger.ErrorContext(ctx, "Handler failed to encode error data", slog.Any("error", err))
+	contentType string
+}
+
+// WriteHeader sets the default content type before writing the header.
+func (w *defaultContentTypeWriter) WriteHeader(statusCode int) {
+	w.setDefaultContentType()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write sets the default content type before writing b.
+func (w *defaultContentTypeWriter) Write(b []byte) (int, error) {
+	w.setDefaultContentType()
+
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil {
+		return n, fmt.Errorf("writing response: %w", err)
+	}
+
+	return n, nil
+}
+
+// Flush sets the default content type before flushing the underlying
+// http.ResponseWriter, if it supports flushing.
+func (w *defaultContentTypeWriter) Flush() {
+	w.setDefaultContentType()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying http.ResponseWriter for use with
+// http.ResponseController.
+func (w *defaultContentTypeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *defaultContentTypeWriter) setDefaultContentType() {
+	if _, ok := w.Header()["Content-Type"]; !ok {
+		w.Header().Set("Content-Type", w.contentType)
+	}
+}
+
+// setResponseHeaders copies the headers set on res to w, replacing any
+// existing values except for Vary which is merged, and writes each cookie as
+// its own Set-Cookie header.
+func setResponseHeaders(w http.ResponseWriter, res *Response) {
+	for key, values := range res.header {
+		if key == varyHeader {
+			AddVary(w.Header(), values...)
+			continue
+		}
+
+		w.Header()[key] = values
+	}
+
+	for _, cookie := range res.cookies {
+		http.SetCookie(w, cookie)
 	}
 }
 
@@ -490,18 +1026,50 @@ func transform(ctx context.Context, data any) error {
 	return nil
 }
 
+// postHydrate calls PostHydrate on the request data and params if they
+// implement the PostHydrateTransformer interface.
+func postHydrate[D, P any](req *Request[D, P]) error {
+	for _, v := range []any{&req.Data, &req.Params} {
+		if transformer, ok := v.(PostHydrateTransformer[D, P]); ok {
+			if err := transformer.PostHydrate(req); err != nil {
+				return fmt.Errorf("post-hydrate transforming request: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // closeRequestBody safely closes the request body and logs a warning if an
 // error occurs during closure.
-func closeRequestBody(ctx context.Context, logger *slog.Logger, body io.Closer) {
+func closeRequestBody(ctx context.Context, logger *slog.Logger, body io.Closer, startedAt time.Time) {
 	if body == nil {
 		return
 	}
 
 	if err := body.Close(); err != nil {
-		logger.WarnContext(ctx, "Handler failed to close request body", slog.Any("error", err))
+		logger.WarnContext(ctx, "Handler failed to close request body", slog.Any("error", err), durationAttr(startedAt))
 	}
 }
 
+// encodeErrorLevel returns the level to log a response encoding error at.
+// Errors caused by the client going away before the response was written are
+// not actionable so are logged at debug level, whereas genuine encoding
+// failures are logged at error level.
+func encodeErrorLevel(err error) slog.Level {
+	if errors.Is(err, context.Canceled) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return slog.LevelDebug
+	}
+
+	return slog.LevelError
+}
+
+// durationAttr returns the duration_ms log attribute for a request that started
+// being served at startedAt.
+func durationAttr(startedAt time.Time) slog.Attr {
+	return slog.Int64("duration_ms", time.Since(startedAt).Milliseconds())
+}
+
 func isEmpty(v any) bool {
 	if v == nil {
 		return true
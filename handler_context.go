@@ -10,9 +10,14 @@ import (
 // Both the writer (Server.Register) and readers (handler.resolve,
 // netHTTPHandler.resolve) are unexported internals in this package.
 type handlerContext struct {
-	codec  ServerCodec
-	guard  Guard
-	logger *slog.Logger
+	codec              ServerCodec
+	debugErrors        bool
+	errorMappers       []ErrorMapper
+	guard              Guard
+	logAttributes      LogAttributesFunc
+	logger             *slog.Logger
+	problemInstance    ProblemInstanceFunc
+	responseValidation responseValidationMode
 }
 
 // handlerCtxKey is the context key for handlerContext values.
@@ -212,14 +212,7 @@ func TestHandlerContextResolution(t *testing.T) {
 			t.Errorf("status = %d, want %d", res.Code, http.StatusInternalServerError)
 		}
 
-		query := slogmem.RecordQuery{
-			Message: "Unhandled error received by net/http handler",
-			Level:   slog.LevelError,
-		}
-
-		if ok, diff := logs.Contains(query); !ok {
-			t.Errorf("expected guard error to be logged:\n%s", diff)
-		}
+		testutil.AssertLog(t, logs, slog.LevelError, "Unhandled error received by net/http handler", nil)
 	})
 
 	t.Run("handler panics when served without Server due to missing codec", func(t *testing.T) {
@@ -244,48 +237,34 @@ func TestHandlerContextResolution(t *testing.T) {
 		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
 	})
 
-	t.Run("handler panics when served without Server due to missing logger", func(t *testing.T) {
+	t.Run("handler falls back to the default logger when served without Server", func(t *testing.T) {
 		t.Parallel()
 
 		handler := httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
-			return httputil.NoContent()
+			return nil, errors.New("logged with the default logger")
 		}, httputil.WithHandlerCodec(httputil.NewJSONServerCodec()))
 
-		defer func() {
-			r := recover()
-			if r == nil {
-				t.Fatal("expected panic")
-			}
-
-			want := "httputil: handler *httputil.handler[struct {},struct {}] served without being registered on a Server (missing logger)"
-			if r != want {
-				t.Errorf("panic message = %q, want %q", r, want)
-			}
-		}()
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
 
-		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if res.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", res.Code, http.StatusInternalServerError)
+		}
 	})
 
-	t.Run("netHTTPHandler panics when served without Server due to missing logger", func(t *testing.T) {
+	t.Run("netHTTPHandler falls back to the default logger when served without Server", func(t *testing.T) {
 		t.Parallel()
 
 		handler := httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 			w.WriteHeader(http.StatusNoContent)
-		})
-
-		defer func() {
-			r := recover()
-			if r == nil {
-				t.Fatal("expected panic")
-			}
+		}, httputil.WithHandlerGuard(errorGuard{}))
 
-			want := "httputil: handler *httputil.netHTTPHandler served without being registered on a Server (missing logger)"
-			if r != want {
-				t.Errorf("panic message = %q, want %q", r, want)
-			}
-		}()
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
 
-		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if res.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", res.Code, http.StatusInternalServerError)
+		}
 	})
 
 	t.Run("shared handler with different guards per endpoint uses the correct guard", func(t *testing.T) {
@@ -1,7 +1,6 @@
 package httputil
 
 import (
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -14,28 +13,56 @@ import (
 var _ http.Handler = &netHTTPHandler{} //nolint:exhaustruct // Compile time implementation check.
 
 // netHTTPHandler allows a http.Handler to be used as a [Handler]. It will call
-// a [Guard] and write errors as application/problem+text.
+// a [Guard] and write errors using the configured [ServerCodec], or as
+// application/problem+text when plain-text errors are chosen.
 type netHTTPHandler struct {
-	resolveOnce sync.Once
-	handler     http.Handler
-	logger      *slog.Logger
+	resolveOnce     sync.Once
+	handler         http.Handler
+	codec           ServerCodec
+	debugErrors     bool
+	errorMappers    []ErrorMapper
+	guard           Guard
+	logAttributes   LogAttributesFunc
+	logger          *slog.Logger
+	plainTextErrors bool
+	problemInstance ProblemInstanceFunc
 }
 
 // WrapNetHTTPHandler wraps a standard http.Handler with additional
-// functionality like optional guard and logging.
-func WrapNetHTTPHandler(h http.Handler) http.Handler {
-	return &netHTTPHandler{resolveOnce: sync.Once{}, handler: h, logger: nil}
+// functionality like optional guard and logging. It accepts options to
+// configure the handler's behavior; [WithHandlerCodec], [WithHandlerGuard],
+// [WithHandlerLogger] and [WithHandlerPlainTextErrors] are supported.
+func WrapNetHTTPHandler(h http.Handler, options ...HandlerOption) http.Handler {
+	return newNetHTTPHandler(h, options)
 }
 
 // WrapNetHTTPHandlerFunc wraps an http.HandlerFunc in a netHTTPHandler to
-// support additional features like guarding and logging.
-func WrapNetHTTPHandlerFunc(h http.HandlerFunc) http.Handler {
-	return &netHTTPHandler{resolveOnce: sync.Once{}, handler: h, logger: nil}
+// support additional features like guarding and logging. See
+// [WrapNetHTTPHandler] for the supported options.
+func WrapNetHTTPHandlerFunc(h http.HandlerFunc, options ...HandlerOption) http.Handler {
+	return newNetHTTPHandler(h, options)
+}
+
+func newNetHTTPHandler(h http.Handler, options []HandlerOption) *netHTTPHandler {
+	opts := mapHandlerOptionsToDefaults(options)
+
+	return &netHTTPHandler{
+		resolveOnce:     sync.Once{},
+		handler:         h,
+		codec:           opts.codec,
+		debugErrors:     false,
+		errorMappers:    nil,
+		guard:           opts.guard,
+		logAttributes:   nil,
+		logger:          opts.logger,
+		plainTextErrors: opts.plainTextErrors,
+		problemInstance: nil,
+	}
 }
 
 // ServeHTTP handles HTTP requests, applies the guard if present,
-// and delegates to the wrapped handler. Errors are logged and returned as
-// application/problem+text when the guard fails. It modifies the request
+// and delegates to the wrapped handler. Errors are logged and encoded with the
+// ServerCodec's EncodeError when the guard fails. It modifies the request
 // if the guard provides a new instance.
 func (h *netHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	hc := handlerContextFrom(r.Context())
@@ -43,12 +70,8 @@ func (h *netHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.resolve(hc)
 	}
 
-	if h.logger == nil {
-		panic(fmt.Sprintf("httputil: handler %T served without being registered on a Server (missing logger)", h))
-	}
-
-	var guard Guard
-	if hc != nil {
+	guard := h.guard
+	if guard == nil && hc != nil {
 		guard = hc.guard
 	}
 
@@ -64,13 +87,22 @@ func (h *netHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.handler.ServeHTTP(w, r)
 }
 
-// resolve sets logger from handlerContext. Fields already set are not
-// overwritten.
+// resolve sets codec, logger, debug errors, error mappers, log attributes and
+// problem instance from handlerContext. Fields already set are not overwritten.
 func (h *netHTTPHandler) resolve(hc *handlerContext) {
 	h.resolveOnce.Do(func() {
+		if h.codec == nil {
+			h.codec = hc.codec
+		}
+
 		if h.logger == nil {
 			h.logger = hc.logger
 		}
+
+		h.debugErrors = hc.debugErrors
+		h.errorMappers = hc.errorMappers
+		h.logAttributes = hc.logAttributes
+		h.problemInstance = hc.problemInstance
 	})
 }
 
@@ -92,22 +124,42 @@ func (h *netHTTPHandler) applyGuard(w http.ResponseWriter, r *http.Request, guar
 }
 
 // writeGuardError writes a problem response for a guard failure, logging
-// unhandled errors.
+// unhandled errors. The problem is encoded with the codec unless plain-text
+// errors were chosen or no codec is available.
 func (h *netHTTPHandler) writeGuardError(w http.ResponseWriter, r *http.Request, err error) {
-	w.Header().Set("Content-Type", "application/problem+text")
-
-	problemDetails, ok := errors.AsType[*problem.DetailedError](err)
+	problemDetails, ok := problemFromError(r, err, h.errorMappers)
 	if !ok {
 		problemDetails = problem.ServerError(r)
 		err = fmt.Errorf("calling guard: %w", err)
-		h.logger.ErrorContext(r.Context(), "Unhandled error received by net/http handler", slog.Any("error", err))
+
+		if h.debugErrors {
+			problemDetails = withDebugExtensions(problemDetails, err)
+		}
+
+		requestLogger(h.logger, r, h.logAttributes).ErrorContext(r.Context(), "Unhandled error received by net/http handler", slog.Any("error", err))
+	}
+
+	problemDetails = applyProblemInstance(r, problemDetails, h.problemInstance)
+
+	if h.plainTextErrors || h.codec == nil {
+		h.writePlainTextError(w, r, problemDetails)
+		return
 	}
 
+	if err = h.codec.EncodeError(w, problemDetails.Status, problemDetails); err != nil {
+		err = fmt.Errorf("writing guard error: %w", err)
+		requestLogger(h.logger, r, h.logAttributes).ErrorContext(r.Context(), "Failed to write error in net/http handler", slog.Any("error", err))
+	}
+}
+
+// writePlainTextError writes the problem as application/problem+text.
+func (h *netHTTPHandler) writePlainTextError(w http.ResponseWriter, r *http.Request, problemDetails *problem.DetailedError) {
+	w.Header().Set("Content-Type", "application/problem+text")
 	w.WriteHeader(problemDetails.Status)
 
-	_, err = w.Write([]byte(problemDetails.Error())) //nolint:gosec // G705: writes structured problem error, not user input.
+	_, err := w.Write([]byte(problemDetails.Error())) //nolint:gosec // G705: writes structured problem error, not user input.
 	if err != nil {
 		err = fmt.Errorf("writing guard error: %w", err)
-		h.logger.ErrorContext(r.Context(), "Failed to write error in net/http handler", slog.Any("error", err))
+		requestLogger(h.logger, r, h.logAttributes).ErrorContext(r.Context(), "Failed to write error in net/http handler", slog.Any("error", err))
 	}
 }
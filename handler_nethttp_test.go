@@ -22,6 +22,7 @@ func TestNewNetHTTPHandler(t *testing.T) {
 		request                *http.Request
 		endpoint               httputil.Endpoint
 		wantLogs               []slogmem.RecordQuery
+		wantContentType        string
 		wantResponseBody       string
 		wantResponseStatusCode int
 	}{
@@ -60,7 +61,8 @@ func TestNewNetHTTPHandler(t *testing.T) {
 					"error": slog.AnyValue("calling guard: some error"),
 				},
 			}},
-			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).Error(),
+			wantContentType:        "application/problem+json; charset=utf-8",
+			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
 			wantResponseStatusCode: http.StatusInternalServerError,
 		},
 		"returns a problem error when the guard blocks the handler by returning a problem error type": {
@@ -71,9 +73,34 @@ func TestNewNetHTTPHandler(t *testing.T) {
 					w.WriteHeader(http.StatusNoContent)
 				}),
 			}, problemGuard{}),
+			wantContentType:        "application/problem+json; charset=utf-8",
+			wantResponseBody:       problem.BadRequest(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusBadRequest,
+		},
+		"returns a plain text problem error when plain text errors are chosen": {
+			endpoint: httputil.NewEndpointWithGuard(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				}, httputil.WithHandlerPlainTextErrors()),
+			}, problemGuard{}),
+			wantContentType:        "application/problem+text",
 			wantResponseBody:       problem.BadRequest(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).Error(),
 			wantResponseStatusCode: http.StatusBadRequest,
 		},
+		"returns a problem error encoded by the handler codec when one is set": {
+			endpoint: httputil.NewEndpointWithGuard(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				}, httputil.WithHandlerCodec(httputil.NewJSONServerCodec(httputil.WithJSONCharset("")))),
+			}, problemGuard{}),
+			wantContentType:        "application/problem+json",
+			wantResponseBody:       problem.BadRequest(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusBadRequest,
+		},
 		"allows the guard to add to the request context which is passed to the handler for consumption": {
 			endpoint: httputil.NewEndpointWithGuard(httputil.Endpoint{
 				Method: http.MethodGet,
@@ -155,6 +182,10 @@ func TestNewNetHTTPHandler(t *testing.T) {
 				t.Errorf("response.Code = %d, want %d", response.Result().StatusCode, testCase.wantResponseStatusCode)
 			}
 
+			if got := response.Header().Get("Content-Type"); testCase.wantContentType != "" && got != testCase.wantContentType {
+				t.Errorf("response Content-Type = %q, want %q", got, testCase.wantContentType)
+			}
+
 			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
 				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
 			}
@@ -164,9 +195,7 @@ func TestNewNetHTTPHandler(t *testing.T) {
 			}
 
 			for _, query := range testCase.wantLogs {
-				if ok, diff := logs.Contains(query); !ok {
-					t.Errorf("logs do not contain query (-want +got): \n%s", diff)
-				}
+				testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
 			}
 		})
 	}
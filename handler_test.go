@@ -2,13 +2,21 @@ package httputil_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"mime"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -179,6 +187,7 @@ func TestNewHandler(t *testing.T) {
 					UUID4    string `json:"uuid4"    validate:"uuid4"`
 					Phone    string `json:"phone"    validate:"e164"`
 					Field    string `json:"field"    validate:"min=3"`
+					Color    string `json:"color"    validate:"oneof=red green 'light blue'"`
 				}
 
 				return httputil.Endpoint{
@@ -198,7 +207,86 @@ func TestNewHandler(t *testing.T) {
 				problem.Property{Detail: "should be a valid UUID", Pointer: "/uuid"},
 				problem.Property{Detail: "should be a valid UUID4", Pointer: "/uuid4"},
 				problem.Property{Detail: "should be a valid international phone number (e.g. +33 6 06 06 06 06)", Pointer: "/phone"},
-				problem.Property{Detail: "should be min=3", Pointer: "/field"},
+				problem.Property{Detail: "must be at least 3 characters", Pointer: "/field"},
+				problem.Property{Detail: "must be one of: red, green, light blue", Pointer: "/color"},
+			).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusUnprocessableEntity,
+		},
+		"describes numeric and length constraints by the kind of the field": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Name     string    `json:"name"     validate:"min=3"`
+					Code     string    `json:"code"     validate:"len=1"`
+					Nickname string    `json:"nickname" validate:"max=4"`
+					Tags     []string  `json:"tags"     validate:"min=2"`
+					Roles    []string  `json:"roles"    validate:"max=1"`
+					Age      int       `json:"age"      validate:"gte=18"`
+					Quantity uint      `json:"quantity" validate:"lte=10"`
+					Price    float64   `json:"price"    validate:"gt=0"`
+					Discount float64   `json:"discount" validate:"lt=1"`
+					Digits   int       `json:"digits"   validate:"len=6"`
+					StartsAt time.Time `json:"startsAt" validate:"gt"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/test",
+					Handler: httputil.NewHandler(func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+						return httputil.NoContent()
+					}),
+				}
+			}(),
+			request: httptest.NewRequest(http.MethodGet, "/test", strings.NewReader(
+				`{"name":"ab","code":"ab","nickname":"abcde","tags":["a"],"roles":["a","b"],"age":17,"quantity":11,"price":0,"discount":1,"digits":12345}`,
+			)),
+			wantHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			wantResponseBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodGet, "/test", http.NoBody),
+				problem.Property{Detail: "must be at least 3 characters", Pointer: "/name"},
+				problem.Property{Detail: "must be exactly 1 character", Pointer: "/code"},
+				problem.Property{Detail: "must be at most 4 characters", Pointer: "/nickname"},
+				problem.Property{Detail: "must contain at least 2 items", Pointer: "/tags"},
+				problem.Property{Detail: "must contain at most 1 item", Pointer: "/roles"},
+				problem.Property{Detail: "must be at least 18", Pointer: "/age"},
+				problem.Property{Detail: "must be at most 10", Pointer: "/quantity"},
+				problem.Property{Detail: "must be greater than 0", Pointer: "/price"},
+				problem.Property{Detail: "must be less than 1", Pointer: "/discount"},
+				problem.Property{Detail: "must be exactly 6", Pointer: "/digits"},
+				problem.Property{Detail: "should be gt", Pointer: "/startsAt"},
+			).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusUnprocessableEntity,
+		},
+		"describes cross-field comparisons with the name of the other field": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Password        string    `json:"password"`
+					ConfirmPassword string    `json:"confirmPassword" validate:"eqfield=Password"`
+					StartDate       time.Time `json:"startDate"`
+					EndDate         time.Time `json:"endDate"         validate:"gtfield=StartDate"`
+					MinPrice        int       `json:"minPrice"`
+					MaxPrice        int       `json:"maxPrice"        validate:"gtefield=MinPrice"`
+					Username        string    `json:"username"        validate:"nefield=Password"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/test",
+					Handler: httputil.NewHandler(func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+						return httputil.NoContent()
+					}),
+				}
+			}(),
+			request: httptest.NewRequest(http.MethodGet, "/test", strings.NewReader(
+				`{"password":"secret","confirmPassword":"secrets","startDate":"2024-02-01T00:00:00Z",`+
+					`"endDate":"2024-01-01T00:00:00Z","minPrice":10,"maxPrice":5,"username":"secret"}`,
+			)),
+			wantHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			wantResponseBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodGet, "/test", http.NoBody),
+				problem.Property{Detail: "must be equal to Password", Pointer: "/confirmPassword"},
+				problem.Property{Detail: "must be greater than StartDate", Pointer: "/endDate"},
+				problem.Property{Detail: "must be greater than or equal to MinPrice", Pointer: "/maxPrice"},
+				problem.Property{Detail: "must not be equal to Password", Pointer: "/username"},
 			).MustMarshalJSONString(),
 			wantResponseStatusCode: http.StatusUnprocessableEntity,
 		},
@@ -773,6 +861,158 @@ func TestNewHandler(t *testing.T) {
 			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
 			wantResponseStatusCode: http.StatusInternalServerError,
 		},
+		"returns a constraint violation when the request body does not satisfy the request schema": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Name string `json:"name"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodPost,
+					Path:   "/test",
+					Handler: httputil.NewHandler(
+						func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+							return httputil.NoContent()
+						},
+						httputil.WithHandlerRequestSchema([]byte(`{
+							"type": "object",
+							"properties": {"name": {"type": "string"}},
+							"additionalProperties": false
+						}`)),
+					),
+				}
+			}(),
+			request:    httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"test","extra":true}`)),
+			wantHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			wantResponseBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodPost, "/test", http.NoBody),
+				problem.Property{Detail: "is not allowed", Pointer: "/extra"},
+			).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusUnprocessableEntity,
+		},
+		"decodes the request body when it satisfies the request schema": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Name string `json:"name"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodPost,
+					Path:   "/test",
+					Handler: httputil.NewHandler(
+						func(r httputil.RequestData[request]) (*httputil.Response, error) {
+							return httputil.OK(map[string]string{"name": r.Data.Name})
+						},
+						httputil.WithHandlerRequestSchema([]byte(`{
+							"type": "object",
+							"properties": {"name": {"type": "string"}},
+							"additionalProperties": false
+						}`)),
+					),
+				}
+			}(),
+			request:                httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"test"}`)),
+			wantHeader:             http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			wantResponseBody:       `{"name":"test"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"validates a request body with a leading byte order mark against the request schema": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Name string `json:"name"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodPost,
+					Path:   "/test",
+					Handler: httputil.NewHandler(
+						func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+							return httputil.NoContent()
+						},
+						httputil.WithHandlerRequestSchema([]byte(`{
+							"type": "object",
+							"properties": {"name": {"type": "string"}},
+							"additionalProperties": false
+						}`)),
+					),
+				}
+			}(),
+			request:    httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("\ufeff"+`{"name":"test","extra":true}`)),
+			wantHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			wantResponseBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodPost, "/test", http.NoBody),
+				problem.Property{Detail: "is not allowed", Pointer: "/extra"},
+			).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusUnprocessableEntity,
+		},
+		"reports each request schema violation with a pointer to the offending property": {
+			endpoint: func() httputil.Endpoint {
+				type request struct {
+					Name string `json:"name"`
+					Age  int    `json:"age"`
+				}
+
+				return httputil.Endpoint{
+					Method: http.MethodPost,
+					Path:   "/test",
+					Handler: httputil.NewHandler(
+						func(_ httputil.RequestData[request]) (*httputil.Response, error) {
+							return httputil.NoContent()
+						},
+						httputil.WithHandlerRequestSchema([]byte(`{
+							"type": "object",
+							"properties": {"name": {"type": "string"}, "age": {"type": "integer"}},
+							"required": ["name"]
+						}`)),
+					),
+				}
+			}(),
+			request:    httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"age":"ten"}`)),
+			wantHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			wantResponseBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodPost, "/test", http.NoBody),
+				problem.Property{Detail: "is required", Pointer: "/name"},
+				problem.Property{Detail: "got string, want integer", Pointer: "/age"},
+			).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusUnprocessableEntity,
+		},
+		"the default content type is set when the action writes to the response writer directly": {
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(
+					func(r httputil.RequestEmpty) (*httputil.Response, error) {
+						_, _ = r.ResponseWriter.Write([]byte(`<p>not html</p>`))
+						return httputil.NothingToHandle()
+					},
+					httputil.WithHandlerDefaultContentType("application/json; charset=utf-8"),
+				),
+			},
+			wantHeader: http.Header{
+				"Content-Type":           {"application/json; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantResponseBody:       `<p>not html</p>`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"the default content type does not override a content type set by the codec": {
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(
+					func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.OK(map[string]string{"hello": "world"})
+					},
+					httputil.WithHandlerDefaultContentType("text/plain"),
+				),
+			},
+			wantHeader: http.Header{
+				"Content-Type":           {"application/json; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantResponseBody:       `{"hello":"world"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
 		"handles request types being set to any,any": {
 			endpoint: httputil.Endpoint{
 				Method: http.MethodGet,
@@ -827,9 +1067,7 @@ func TestNewHandler(t *testing.T) {
 			}
 
 			for _, query := range testCase.wantLogs {
-				if ok, diff := logs.Contains(query); !ok {
-					t.Errorf("logs do not contain query (-want +got): \n%s", diff)
-				}
+				testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
 			}
 		})
 	}
@@ -918,92 +1156,996 @@ func (ri addToContextGuard) Guard(r *http.Request) (*http.Request, error) {
 	return r.WithContext(context.WithValue(r.Context(), addToContextGuardCtxKey{}, ri)), nil
 }
 
-func TestNewFormHandler(t *testing.T) {
+func TestNewHandler_ContentTypeCharset(t *testing.T) {
 	t.Parallel()
 
-	t.Run("passes data validation errors to the action", func(t *testing.T) {
-		t.Parallel()
-
-		type request struct {
-			Name  string `json:"name"  validate:"required"`
-			Email string `json:"email" validate:"required,email"`
-		}
+	actions := map[string]httputil.Action[struct{}, struct{}]{
+		"success": func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+			return httputil.OK(map[string]string{"hello": "world"})
+		},
+		"error": func(r httputil.RequestEmpty) (*httputil.Response, error) {
+			return nil, problem.NotFound(r.Request)
+		},
+		"redirect": func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+			return httputil.Redirect(http.StatusFound, "http://example.com")
+		},
+	}
 
-		var capturedErrors httputil.BindErrors
+	charsets := make(map[string]string, len(actions))
 
+	for name, action := range actions {
 		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
 		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(action)})
 
-		server.Register(httputil.Endpoint{
-			Method: http.MethodPost,
-			Path:   "/test",
-			Handler: httputil.NewFormHandler(
-				func(r httputil.RequestData[request]) (*httputil.Response, error) {
-					capturedErrors = r.Errors
-					return httputil.OK(map[string]string{"errors": "captured"})
-				},
-			),
-		})
-
-		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{}`))
-		res := httptest.NewRecorder()
-
-		server.ServeHTTP(res, req)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
 
-		if res.Code != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, res.Code)
+		_, params, err := mime.ParseMediaType(response.Header().Get("Content-Type"))
+		if err != nil {
+			t.Fatalf("unable to parse %s response content type: %+v", name, err)
 		}
 
-		if !capturedErrors.HasAny() {
-			t.Fatal("expected errors to be present")
-		}
+		charsets[name] = params["charset"]
+	}
 
-		if capturedErrors.Data == nil {
-			t.Fatal("expected Data error to be set")
-		}
+	want := map[string]string{"success": "utf-8", "error": "utf-8", "redirect": "utf-8"}
+	if diff := cmp.Diff(want, charsets); diff != "" {
+		t.Errorf("response charsets differ by response path (-want +got):\n%s", diff)
+	}
+}
 
-		if capturedErrors.Params != nil {
-			t.Error("expected Params error to be nil")
-		}
+func TestNewHandler_ResponseValidation(t *testing.T) {
+	t.Parallel()
 
-		// Check translated messages via Get.
-		if got := capturedErrors.Get("name"); got != "is required" {
-			t.Errorf("Get(\"name\") = %q, want %q", got, "is required")
-		}
+	type response struct {
+		Name string `json:"name" validate:"required"`
+	}
 
-		if got := capturedErrors.Get("email"); got != "is required" {
-			t.Errorf("Get(\"email\") = %q, want %q", got, "is required")
-		}
+	const schema = `{
+		"type": "object",
+		"properties": {"name": {"type": "string", "minLength": 1}},
+		"required": ["name"]
+	}`
 
-		// Check All() returns all errors.
-		all := capturedErrors.All()
-		if len(all) != 2 {
-			t.Errorf("All() returned %d entries, want 2", len(all))
-		}
-	})
+	testCases := map[string]struct {
+		responseValidation     httputil.ServerOption
+		handlerOptions         []httputil.HandlerOption
+		responseData           any
+		wantLogs               []slogmem.RecordQuery
+		wantResponseBody       string
+		wantResponseStatusCode int
+	}{
+		"a non-conforming response is written when response validation is disabled": {
+			responseValidation:     httputil.WithServerResponseValidation(false),
+			responseData:           response{Name: ""},
+			wantResponseBody:       `{"name":""}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a conforming response is written when response validation is enabled": {
+			responseValidation:     httputil.WithServerResponseValidation(true),
+			responseData:           response{Name: "test"},
+			wantResponseBody:       `{"name":"test"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a response violating its validate tags fails when response validation is enabled": {
+			responseValidation: httputil.WithServerResponseValidation(true),
+			responseData:       &response{Name: ""},
+			wantLogs: []slogmem.RecordQuery{{
+				Message: "Handler response violated its contract",
+				Level:   slog.LevelError,
+				Attrs: map[string]slog.Value{
+					"error": slog.AnyValue("Key: 'response.name' Error:Field validation for 'name' failed on the 'required' tag"),
+				},
+			}},
+			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusInternalServerError,
+		},
+		"a response violating its schema fails when response validation is enabled": {
+			responseValidation: httputil.WithServerResponseValidation(true),
+			handlerOptions:     []httputil.HandlerOption{httputil.WithHandlerResponseSchema([]byte(schema))},
+			responseData:       map[string]string{"name": ""},
+			wantLogs: []slogmem.RecordQuery{{
+				Message: "Handler response violated its contract",
+				Level:   slog.LevelError,
+				Attrs: map[string]slog.Value{
+					"violations": slog.AnyValue([]problem.Property{{Detail: "minLength: got 0, want 1", Pointer: "/name"}}),
+				},
+			}},
+			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", http.NoBody)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusInternalServerError,
+		},
+		"a conforming response is written when response validation is log only": {
+			responseValidation:     httputil.WithServerResponseValidationLogOnly(),
+			responseData:           response{Name: "test"},
+			wantResponseBody:       `{"name":"test"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a response violating its validate tags is logged and written when response validation is log only": {
+			responseValidation: httputil.WithServerResponseValidationLogOnly(),
+			responseData:       &response{Name: ""},
+			wantLogs: []slogmem.RecordQuery{{
+				Message: "Handler response violated its contract",
+				Level:   slog.LevelError,
+				Attrs: map[string]slog.Value{
+					"error": slog.AnyValue("Key: 'response.name' Error:Field validation for 'name' failed on the 'required' tag"),
+				},
+			}},
+			wantResponseBody:       `{"name":""}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a response violating its schema is logged and written when response validation is log only": {
+			responseValidation: httputil.WithServerResponseValidationLogOnly(),
+			handlerOptions:     []httputil.HandlerOption{httputil.WithHandlerResponseSchema([]byte(schema))},
+			responseData:       map[string]string{"name": ""},
+			wantLogs: []slogmem.RecordQuery{{
+				Message: "Handler response violated its contract",
+				Level:   slog.LevelError,
+				Attrs: map[string]slog.Value{
+					"violations": slog.AnyValue([]problem.Property{{Detail: "minLength: got 0, want 1", Pointer: "/name"}}),
+				},
+			}},
+			wantResponseBody:       `{"name":""}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+	}
 
-	t.Run("passes decode errors to the action", func(t *testing.T) {
-		t.Parallel()
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
 
-		type request struct {
-			Name string `json:"name"`
-		}
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.responseValidation)
 
-		var capturedErrors httputil.BindErrors
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.OK(testCase.responseData)
+				}, testCase.handlerOptions...),
+			})
 
-		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
-		server := httputil.NewServer(logger)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
 
-		server.Register(httputil.Endpoint{
-			Method: http.MethodPost,
-			Path:   "/test",
-			Handler: httputil.NewFormHandler(
-				func(r httputil.RequestData[request]) (*httputil.Response, error) {
-					capturedErrors = r.Errors
-					return httputil.OK(map[string]string{"status": "action called"})
-				},
-			),
-		})
+			if response.Result().StatusCode != testCase.wantResponseStatusCode {
+				t.Errorf("response.Code = %d, want %d", response.Result().StatusCode, testCase.wantResponseStatusCode)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			if len(testCase.wantLogs) != logs.Len() {
+				t.Errorf("logs.Len() = %d, want: %d, logs: %+v", logs.Len(), len(testCase.wantLogs), logs.AsSliceOfNestedKeyValuePairs())
+			}
+
+			for _, query := range testCase.wantLogs {
+				testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
+			}
+		})
+	}
+}
+
+func TestNewHandler_Cookies(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		action httputil.Action[struct{}, struct{}]
+	}{
+		"with data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+				return res.WithCookie(&http.Cookie{Name: "a", Value: "1"}).WithCookie(&http.Cookie{Name: "b", Value: "2"}).DeleteCookie("session"), nil
+			},
+		},
+		"without data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.NoContent()
+				return res.WithCookie(&http.Cookie{Name: "a", Value: "1"}).WithCookie(&http.Cookie{Name: "b", Value: "2"}).DeleteCookie("session"), nil
+			},
+		},
+		"with a redirect": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.Redirect(http.StatusFound, "/elsewhere")
+				return res.WithCookie(&http.Cookie{Name: "a", Value: "1"}).WithCookie(&http.Cookie{Name: "b", Value: "2"}).DeleteCookie("session"), nil
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			want := []string{"a=1", "b=2", "session=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0"}
+			if diff := cmp.Diff(want, response.Header().Values("Set-Cookie")); diff != "" {
+				t.Errorf("Set-Cookie headers mismatch (-want +got):\n%s", diff)
+			}
+
+			cookies := response.Result().Cookies()
+			if len(cookies) != 3 {
+				t.Fatalf("len(cookies) = %d, want: 3", len(cookies))
+			}
+
+			if deleted := cookies[2]; deleted.MaxAge >= 0 || !deleted.Expires.Before(time.Now()) {
+				t.Errorf("deleted cookie MaxAge = %d, Expires = %s, want an expired cookie", deleted.MaxAge, deleted.Expires)
+			}
+		})
+	}
+}
+
+func TestNewHandler_CreatedResource(t *testing.T) {
+	t.Parallel()
+
+	const warning = "Handler created a resource without a Location header"
+
+	testCases := map[string]struct {
+		location     string
+		wantLocation string
+		wantWarning  bool
+	}{
+		"a location is set on the response": {
+			location:     "/users/1",
+			wantLocation: "/users/1",
+			wantWarning:  false,
+		},
+		"an empty location is warned about": {
+			location:     "",
+			wantLocation: "",
+			wantWarning:  true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/users",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.CreatedResource(testCase.location, map[string]string{"id": "1"})
+				}),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+			if response.Code != http.StatusCreated {
+				t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusCreated)
+			}
+
+			if got := response.Header().Get("Location"); got != testCase.wantLocation {
+				t.Errorf("Location = %q, want: %q", got, testCase.wantLocation)
+			}
+
+			if diff := testutil.DiffJSON(`{"id":"1"}`, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			if !testCase.wantWarning {
+				if !logs.IsEmpty() {
+					t.Errorf("logs = %+v, want: none", logs.AsSliceOfNestedKeyValuePairs())
+				}
+
+				return
+			}
+
+			testutil.AssertLog(t, logs, slog.LevelWarn, warning, nil)
+		})
+	}
+}
+
+func TestNewHandler_RawJSON(t *testing.T) {
+	t.Parallel()
+
+	const cached = `{"name": "test",  "id": 1}`
+
+	testCases := map[string]struct {
+		options                []httputil.HandlerOption
+		serverOptions          []httputil.ServerOption
+		wantResponseBody       string
+		wantResponseStatusCode int
+	}{
+		"a raw message is written byte for byte": {
+			wantResponseBody:       cached,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a raw message that satisfies the response schema is written byte for byte": {
+			options:                []httputil.HandlerOption{httputil.WithHandlerResponseSchema([]byte(`{"type": "object", "required": ["id"]}`))},
+			serverOptions:          []httputil.ServerOption{httputil.WithServerResponseValidation(true)},
+			wantResponseBody:       cached,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a raw message that violates the response schema is not written": {
+			options:                []httputil.HandlerOption{httputil.WithHandlerResponseSchema([]byte(`{"type": "object", "required": ["email"]}`))},
+			serverOptions:          []httputil.ServerOption{httputil.WithServerResponseValidation(true)},
+			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodGet, "/test", nil)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.serverOptions...)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.OK(json.RawMessage(cached))
+				}, testCase.options...),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if response.Code != testCase.wantResponseStatusCode {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantResponseStatusCode)
+			}
+
+			if response.Code == http.StatusOK && response.Body.String() != testCase.wantResponseBody {
+				t.Errorf("response.Body = %q, want: %q", response.Body.String(), testCase.wantResponseBody)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type transformErrorResponse struct{}
+
+func (*transformErrorResponse) Transform(context.Context) error {
+	return errors.New("transform failed")
+}
+
+func TestNewHandler_LogDuration(t *testing.T) {
+	t.Parallel()
+
+	const actionDuration = 10 * time.Millisecond
+
+	testCases := map[string]struct {
+		action      httputil.Action[struct{}, struct{}]
+		wantLevel   slog.Level
+		wantMessage string
+	}{
+		"an unhandled action error is logged with the request duration": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				time.Sleep(actionDuration)
+				return nil, errors.New("boom")
+			},
+			wantLevel:   slog.LevelError,
+			wantMessage: "Handler received an unhandled error",
+		},
+		"a response that fails to transform is logged with the request duration": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				time.Sleep(actionDuration)
+				return httputil.OK(&transformErrorResponse{})
+			},
+			wantLevel:   slog.LevelWarn,
+			wantMessage: "Handler failed to transform response data",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)})
+
+			server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			records := logs.AsSliceOfNestedKeyValuePairs()
+			if len(records) != 1 {
+				t.Fatalf("logs.Len() = %d, want: 1, logs: %+v", len(records), records)
+			}
+
+			if records[0][slog.LevelKey] != testCase.wantLevel || records[0][slog.MessageKey] != testCase.wantMessage {
+				t.Errorf("log = %s %q, want: %s %q", records[0][slog.LevelKey], records[0][slog.MessageKey], testCase.wantLevel, testCase.wantMessage)
+			}
+
+			duration, ok := records[0]["duration_ms"].(int64)
+			if !ok {
+				t.Fatalf("log duration_ms = %#v, want: int64, logs: %+v", records[0]["duration_ms"], records)
+			}
+
+			if duration < actionDuration.Milliseconds() {
+				t.Errorf("log duration_ms = %d, want: >= %d", duration, actionDuration.Milliseconds())
+			}
+		})
+	}
+}
+
+// failingWriter is a http.ResponseWriter whose Write always fails with err.
+type failingWriter struct {
+	http.ResponseWriter
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestNewHandler_EncodeErrorLogLevel(t *testing.T) {
+	t.Parallel()
+
+	okAction := func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+		return httputil.OK(map[string]string{"hello": "world"})
+	}
+
+	testCases := map[string]struct {
+		action    httputil.Action[struct{}, struct{}]
+		writeErr  error
+		wantLevel slog.Level
+	}{
+		"an unsupported type is logged at error level": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				return httputil.OK(map[string]any{"channel": make(chan int)})
+			},
+			wantLevel: slog.LevelError,
+		},
+		"a broken pipe is logged at debug level": {
+			action:    okAction,
+			writeErr:  &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)},
+			wantLevel: slog.LevelDebug,
+		},
+		"a connection reset is logged at debug level": {
+			action:    okAction,
+			writeErr:  &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.ECONNRESET)},
+			wantLevel: slog.LevelDebug,
+		},
+		"a canceled context is logged at debug level": {
+			action:    okAction,
+			writeErr:  fmt.Errorf("writing response: %w", context.Canceled),
+			wantLevel: slog.LevelDebug,
+		},
+		"any other write error is logged at error level": {
+			action:    okAction,
+			writeErr:  errors.New("disk full"),
+			wantLevel: slog.LevelError,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)})
+
+			var response http.ResponseWriter = httptest.NewRecorder()
+			if testCase.writeErr != nil {
+				response = failingWriter{ResponseWriter: response, err: testCase.writeErr}
+			}
+
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if logs.Len() != 1 {
+				t.Errorf("logs.Len() = %d, want: 1, logs: %+v", logs.Len(), logs.AsSliceOfNestedKeyValuePairs())
+			}
+
+			testutil.AssertLog(t, logs, testCase.wantLevel, "Handler failed to encode response data", nil)
+		})
+	}
+}
+
+// writeHeaderCounter is a http.ResponseWriter that counts the calls to
+// WriteHeader.
+type writeHeaderCounter struct {
+	*httptest.ResponseRecorder
+
+	calls int
+}
+
+func (w *writeHeaderCounter) WriteHeader(statusCode int) {
+	w.calls++
+	w.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func TestNewHandler_DirectWrite(t *testing.T) {
+	t.Parallel()
+
+	const warning = "Handler returned a response after writing to the ResponseWriter directly, return NothingToHandle instead"
+
+	testCases := map[string]struct {
+		action           httputil.Action[struct{}, struct{}]
+		wantStatus       int
+		wantBody         string
+		wantHeaderWrites int
+		wantWarning      map[string]slog.Value
+	}{
+		"a response returned after writing the header is not written": {
+			action: func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				r.ResponseWriter.WriteHeader(http.StatusAccepted)
+				_, _ = r.ResponseWriter.Write([]byte("direct"))
+
+				return httputil.OK(map[string]string{"hello": "world"})
+			},
+			wantStatus:       http.StatusAccepted,
+			wantBody:         "direct",
+			wantHeaderWrites: 1,
+			wantWarning:      map[string]slog.Value{"status": slog.IntValue(http.StatusOK)},
+		},
+		"a response returned after writing the body is not written": {
+			action: func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				_, _ = r.ResponseWriter.Write([]byte("direct"))
+
+				return httputil.NoContent()
+			},
+			wantStatus:       http.StatusOK,
+			wantBody:         "direct",
+			wantHeaderWrites: 0,
+			wantWarning:      map[string]slog.Value{"status": slog.IntValue(http.StatusNoContent)},
+		},
+		"a redirect returned after writing the header is not written": {
+			action: func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				r.ResponseWriter.WriteHeader(http.StatusOK)
+
+				return httputil.Redirect(http.StatusFound, "/elsewhere")
+			},
+			wantStatus:       http.StatusOK,
+			wantBody:         "",
+			wantHeaderWrites: 1,
+			wantWarning:      map[string]slog.Value{"status": slog.IntValue(http.StatusFound)},
+		},
+		"nothing to handle after writing directly is not warned about": {
+			action: func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				r.ResponseWriter.WriteHeader(http.StatusAccepted)
+
+				return httputil.NothingToHandle()
+			},
+			wantStatus:       http.StatusAccepted,
+			wantBody:         "",
+			wantHeaderWrites: 1,
+			wantWarning:      nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)})
+
+			response := &writeHeaderCounter{ResponseRecorder: httptest.NewRecorder(), calls: 0}
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if got := response.Body.String(); got != testCase.wantBody {
+				t.Errorf("response.Body = %q, want: %q", got, testCase.wantBody)
+			}
+
+			if response.calls != testCase.wantHeaderWrites {
+				t.Errorf("WriteHeader calls = %d, want: %d", response.calls, testCase.wantHeaderWrites)
+			}
+
+			if testCase.wantWarning == nil {
+				if !logs.IsEmpty() {
+					t.Errorf("logs = %+v, want: none", logs.AsSliceOfNestedKeyValuePairs())
+				}
+
+				return
+			}
+
+			if logs.Len() != 1 {
+				t.Errorf("logs.Len() = %d, want: 1, logs: %+v", logs.Len(), logs.AsSliceOfNestedKeyValuePairs())
+			}
+
+			testutil.AssertLog(t, logs, slog.LevelWarn, warning, testCase.wantWarning)
+		})
+	}
+}
+
+func TestNewHandler_Flush(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		options         []httputil.HandlerOption
+		wantContentType string
+	}{
+		"an action can flush the response writer": {
+			options:         nil,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		"an action can flush the response writer with a default content type": {
+			options:         []httputil.HandlerOption{httputil.WithHandlerDefaultContentType("text/event-stream")},
+			wantContentType: "text/event-stream",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+					flusher, ok := r.ResponseWriter.(http.Flusher)
+					if !ok {
+						return nil, errors.New("response writer does not implement http.Flusher")
+					}
+
+					_, _ = r.ResponseWriter.Write([]byte("data: hello\n\n"))
+					flusher.Flush()
+
+					return httputil.NothingToHandle()
+				}, testCase.options...),
+			})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if !response.Flushed {
+				t.Errorf("response.Flushed = false, want: true, logs: %+v", logs.AsSliceOfNestedKeyValuePairs())
+			}
+
+			if got := response.Header().Get("Content-Type"); got != testCase.wantContentType {
+				t.Errorf("Content-Type = %q, want: %q", got, testCase.wantContentType)
+			}
+
+			if got, want := response.Body.String(), "data: hello\n\n"; got != want {
+				t.Errorf("response.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewHandler_StreamsRequestBody(t *testing.T) {
+	t.Parallel()
+
+	t.Run("the action reads the body before the client has finished sending it", func(t *testing.T) {
+		t.Parallel()
+
+		firstChunkRead := make(chan struct{})
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/proxy",
+			Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				first := make([]byte, len("first "))
+				if _, err := io.ReadFull(r.Body, first); err != nil {
+					return nil, err
+				}
+
+				close(firstChunkRead)
+
+				r.ResponseWriter.WriteHeader(http.StatusOK)
+
+				if _, err := r.ResponseWriter.Write(first); err != nil {
+					return nil, err
+				}
+
+				if _, err := io.Copy(r.ResponseWriter, r.Body); err != nil {
+					return nil, err
+				}
+
+				return httputil.NothingToHandle()
+			}),
+		})
+
+		body, client := io.Pipe()
+		response := httptest.NewRecorder()
+		served := make(chan struct{})
+
+		go func() {
+			defer close(served)
+
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/proxy", body))
+		}()
+
+		if _, err := client.Write([]byte("first ")); err != nil {
+			t.Fatalf("writing first chunk: %v", err)
+		}
+
+		select {
+		case <-firstChunkRead:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the action did not read the first chunk before the body was complete")
+		}
+
+		if _, err := client.Write([]byte("second")); err != nil {
+			t.Fatalf("writing second chunk: %v", err)
+		}
+
+		_ = client.Close()
+
+		<-served
+
+		if got, want := response.Body.String(), "first second"; got != want {
+			t.Errorf("response.Body = %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("the max body size applies to a streamed body", func(t *testing.T) {
+		t.Parallel()
+
+		var copyErr error
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger, httputil.WithServerMaxBodySize(4))
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/proxy",
+			Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				_, copyErr = io.Copy(io.Discard, r.Body)
+				return httputil.NoContent()
+			}),
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/proxy", strings.NewReader("too long"))
+		request.ContentLength = -1
+
+		server.ServeHTTP(httptest.NewRecorder(), request)
+
+		if _, ok := errors.AsType[*http.MaxBytesError](copyErr); !ok {
+			t.Errorf("io.Copy() error = %v, want: *http.MaxBytesError", copyErr)
+		}
+	})
+}
+
+func TestNewHandler_BodyReadTimeout(t *testing.T) {
+	t.Parallel()
+
+	const bodyReadTimeout = 100 * time.Millisecond
+
+	type requestData struct {
+		Name string `json:"name"`
+	}
+
+	testCases := map[string]struct {
+		action     httputil.Action[requestData, struct{}]
+		writeBody  func(w *io.PipeWriter, responded <-chan struct{})
+		wantStatus int
+		wantBody   string
+	}{
+		"a body that is not read in time is rejected": {
+			action: func(_ httputil.Request[requestData, struct{}]) (*httputil.Response, error) {
+				return nil, errors.New("action called, want: body read timeout")
+			},
+			writeBody: func(w *io.PipeWriter, responded <-chan struct{}) {
+				// Stall part way through the body until the response has been
+				// received to simulate a slow client.
+				_, _ = w.Write([]byte(`{"name":`))
+				<-responded
+				_ = w.Close()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody: problem.BadRequest(httptest.NewRequest(http.MethodPost, "/test", nil)).
+				WithDetail("The server did not receive the request body in time").
+				MustMarshalJSONString(),
+		},
+		"a slow action after the body is decoded is unaffected": {
+			action: func(r httputil.Request[requestData, struct{}]) (*httputil.Response, error) {
+				// The rest of the body arrives after the body read timeout, so
+				// reading it fails if the read deadline has not been cleared.
+				if _, err := io.ReadAll(r.Body); err != nil {
+					return nil, fmt.Errorf("reading rest of body: %w", err)
+				}
+
+				return httputil.OK(r.Data)
+			},
+			writeBody: func(w *io.PipeWriter, _ <-chan struct{}) {
+				_, _ = w.Write([]byte(`{"name":"test"}`))
+				time.Sleep(3 * bodyReadTimeout)
+				_, _ = w.Write([]byte("\n"))
+				_ = w.Close()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"name":"test"}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method:  http.MethodPost,
+				Path:    "/test",
+				Handler: httputil.NewHandler(testCase.action, httputil.WithHandlerBodyReadTimeout(bodyReadTimeout)),
+			})
+
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+
+			bodyReader, bodyWriter := io.Pipe()
+			responded := make(chan struct{})
+
+			go testCase.writeBody(bodyWriter, responded)
+
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodPost, httpServer.URL+"/test", bodyReader)
+			if err != nil {
+				t.Fatalf("unexpected error creating request: %v", err)
+			}
+
+			response, err := httpServer.Client().Do(request)
+
+			close(responded)
+
+			if err != nil {
+				t.Fatalf("unexpected error sending request: %v", err)
+			}
+
+			defer response.Body.Close()
+
+			if response.StatusCode != testCase.wantStatus {
+				t.Errorf("response.StatusCode = %d, want: %d", response.StatusCode, testCase.wantStatus)
+			}
+
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatalf("unexpected error reading response body: %v", err)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantBody, string(body)); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewFormHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("binds form values into params alongside the data", func(t *testing.T) {
+		t.Parallel()
+
+		type data struct {
+			Name string `form:"name"`
+		}
+
+		type params struct {
+			Redirect string `param:"form=redirect"`
+		}
+
+		var (
+			gotName     string
+			gotRedirect string
+		)
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/test",
+			Handler: httputil.NewFormHandler(
+				func(r httputil.Request[data, params]) (*httputil.Response, error) {
+					gotName, gotRedirect = r.Data.Name, r.Params.Redirect
+					return httputil.NoContent()
+				},
+				httputil.WithHandlerCodec(httputil.NewHTMLServerCodec(nil)),
+			),
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("name=alice&redirect=%2Fhome"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, req)
+
+		if res.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, res.Code, res.Body.String())
+		}
+
+		if gotName != "alice" {
+			t.Errorf("Data.Name = %q, want %q", gotName, "alice")
+		}
+
+		if gotRedirect != "/home" {
+			t.Errorf("Params.Redirect = %q, want %q", gotRedirect, "/home")
+		}
+	})
+
+	t.Run("passes data validation errors to the action", func(t *testing.T) {
+		t.Parallel()
+
+		type request struct {
+			Name  string `json:"name"  validate:"required"`
+			Email string `json:"email" validate:"required,email"`
+		}
+
+		var capturedErrors httputil.BindErrors
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/test",
+			Handler: httputil.NewFormHandler(
+				func(r httputil.RequestData[request]) (*httputil.Response, error) {
+					capturedErrors = r.Errors
+					return httputil.OK(map[string]string{"errors": "captured"})
+				},
+			),
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{}`))
+		res := httptest.NewRecorder()
+
+		server.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, res.Code)
+		}
+
+		if !capturedErrors.HasAny() {
+			t.Fatal("expected errors to be present")
+		}
+
+		if capturedErrors.Data == nil {
+			t.Fatal("expected Data error to be set")
+		}
+
+		if capturedErrors.Params != nil {
+			t.Error("expected Params error to be nil")
+		}
+
+		// Check translated messages via Get.
+		if got := capturedErrors.Get("name"); got != "is required" {
+			t.Errorf("Get(\"name\") = %q, want %q", got, "is required")
+		}
+
+		if got := capturedErrors.Get("email"); got != "is required" {
+			t.Errorf("Get(\"email\") = %q, want %q", got, "is required")
+		}
+
+		// Check All() returns all errors.
+		all := capturedErrors.All()
+		if len(all) != 2 {
+			t.Errorf("All() returned %d entries, want 2", len(all))
+		}
+	})
+
+	t.Run("passes decode errors to the action", func(t *testing.T) {
+		t.Parallel()
+
+		type request struct {
+			Name string `json:"name"`
+		}
+
+		var capturedErrors httputil.BindErrors
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+
+		server.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/test",
+			Handler: httputil.NewFormHandler(
+				func(r httputil.RequestData[request]) (*httputil.Response, error) {
+					capturedErrors = r.Errors
+					return httputil.OK(map[string]string{"status": "action called"})
+				},
+			),
+		})
 
 		// Invalid JSON.
 		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{invalid`))
@@ -1405,3 +2547,97 @@ func TestNewFormHandler(t *testing.T) {
 		}
 	})
 }
+
+type postHydrateParams struct {
+	Currency string `param:"header=X-Currency"`
+}
+
+type postHydrateRequest struct {
+	Amount int    `json:"amount"`
+	Price  string `json:"-"`
+}
+
+func (phr *postHydrateRequest) PostHydrate(r *httputil.Request[postHydrateRequest, postHydrateParams]) error {
+	if r.Params.Currency == "" {
+		return errors.New("missing currency")
+	}
+
+	phr.Price = strconv.Itoa(phr.Amount) + " " + r.Params.Currency
+
+	return nil
+}
+
+func TestPostHydrateTransformer(t *testing.T) {
+	t.Parallel()
+
+	action := func(r httputil.Request[postHydrateRequest, postHydrateParams]) (*httputil.Response, error) {
+		return httputil.OK(map[string]string{"price": r.Data.Price})
+	}
+
+	testCases := map[string]struct {
+		handler                http.Handler
+		currency               string
+		wantLogs               []slogmem.RecordQuery
+		wantResponseBody       string
+		wantResponseStatusCode int
+	}{
+		"a field is computed from a header param and a body field by NewHandler": {
+			handler:                httputil.NewHandler(action),
+			currency:               "GBP",
+			wantResponseBody:       `{"price":"100 GBP"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a field is computed from a header param and a body field by NewFormHandler": {
+			handler:                httputil.NewFormHandler(action),
+			currency:               "GBP",
+			wantResponseBody:       `{"price":"100 GBP"}`,
+			wantResponseStatusCode: http.StatusOK,
+		},
+		"a post-hydrate error is logged and returns a server error": {
+			handler: httputil.NewHandler(action),
+			wantLogs: []slogmem.RecordQuery{{
+				Message: "Handler failed to post-hydrate transform request",
+				Level:   slog.LevelWarn,
+				Attrs: map[string]slog.Value{
+					"error": slog.AnyValue("post-hydrate transforming request: missing currency"),
+				},
+			}},
+			wantResponseBody:       problem.ServerError(httptest.NewRequest(http.MethodPost, "/test", http.NoBody)).MustMarshalJSONString(),
+			wantResponseStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodPost, Path: "/test", Handler: testCase.handler})
+
+			request := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"amount":100}`))
+			if testCase.currency != "" {
+				request.Header.Set("X-Currency", testCase.currency)
+			}
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantResponseStatusCode {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantResponseStatusCode)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+
+			if len(testCase.wantLogs) != logs.Len() {
+				t.Errorf("logs.Len() = %d, want: %d, logs: %+v", logs.Len(), len(testCase.wantLogs), logs.AsSliceOfNestedKeyValuePairs())
+			}
+
+			for _, query := range testCase.wantLogs {
+				testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
+			}
+		})
+	}
+}
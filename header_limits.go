@@ -0,0 +1,90 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+const (
+	// defaultMaxHeaderCount is the default maximum number of header fields
+	// allowed by [NewHeaderLimitsMiddleware].
+	defaultMaxHeaderCount = 100
+	// defaultMaxHeaderValueBytes is the default maximum size of a header field
+	// value allowed by [NewHeaderLimitsMiddleware].
+	defaultMaxHeaderValueBytes = 8 << 10
+)
+
+type (
+	// HeaderLimitsOption allows default [NewHeaderLimitsMiddleware] config
+	// values to be overridden.
+	HeaderLimitsOption func(o *headerLimitsOptions)
+
+	headerLimitsOptions struct {
+		maxCount      int
+		maxValueBytes int
+	}
+)
+
+// WithHeaderLimitsMaxCount sets the maximum number of header fields that a
+// request may have. Each value of a repeated header counts as a field. The
+// default is 100.
+func WithHeaderLimitsMaxCount(count int) HeaderLimitsOption {
+	return func(o *headerLimitsOptions) {
+		o.maxCount = count
+	}
+}
+
+// WithHeaderLimitsMaxValueBytes sets the maximum size, in bytes, of the value
+// of each header field of a request. The default is 8KB.
+func WithHeaderLimitsMaxValueBytes(size int) HeaderLimitsOption {
+	return func(o *headerLimitsOptions) {
+		o.maxValueBytes = size
+	}
+}
+
+// NewHeaderLimitsMiddleware creates a MiddlewareFunc that rejects requests with
+// too many header fields, or a header field value that is too large, with a
+// [problem.RequestHeaderFieldsTooLarge] error. It complements the
+// MaxHeaderBytes limit of the http.Server, which only bounds the total size of
+// the request header.
+func NewHeaderLimitsMiddleware(options ...HeaderLimitsOption) MiddlewareFunc {
+	opts := headerLimitsOptions{maxCount: defaultMaxHeaderCount, maxValueBytes: defaultMaxHeaderValueBytes}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if detail := opts.violation(r.Header); detail != "" {
+				writeMiddlewareError(w, r, problem.RequestHeaderFieldsTooLarge(r).WithDetail(detail))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// violation returns the problem detail describing the first limit that header
+// exceeds, or an empty string if it is within the limits.
+func (o headerLimitsOptions) violation(header http.Header) string {
+	count := 0
+
+	for name, values := range header {
+		count += len(values)
+
+		for _, value := range values {
+			if len(value) > o.maxValueBytes {
+				return fmt.Sprintf("The value of the %s header exceeds %d bytes", name, o.maxValueBytes)
+			}
+		}
+	}
+
+	if count > o.maxCount {
+		return fmt.Sprintf("The request has more than %d header fields", o.maxCount)
+	}
+
+	return ""
+}
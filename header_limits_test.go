@@ -0,0 +1,113 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewHeaderLimitsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	headers := func(count int) http.Header {
+		header := make(http.Header, count)
+		for i := range count {
+			header.Set("X-Header-"+strconv.Itoa(i), "value")
+		}
+
+		return header
+	}
+
+	testCases := map[string]struct {
+		options    []httputil.HeaderLimitsOption
+		header     http.Header
+		wantStatus int
+		wantDetail string
+	}{
+		"a request within the default limits is passed through": {
+			options:    nil,
+			header:     headers(100),
+			wantStatus: http.StatusOK,
+			wantDetail: "",
+		},
+		"a request with too many headers for the default limit is rejected": {
+			options:    nil,
+			header:     headers(101),
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+			wantDetail: "The request has more than 100 header fields",
+		},
+		"a request with too many headers is rejected": {
+			options:    []httputil.HeaderLimitsOption{httputil.WithHeaderLimitsMaxCount(3)},
+			header:     headers(4),
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+			wantDetail: "The request has more than 3 header fields",
+		},
+		"each value of a repeated header is counted": {
+			options:    []httputil.HeaderLimitsOption{httputil.WithHeaderLimitsMaxCount(3)},
+			header:     http.Header{"X-Repeated": {"a", "b", "c", "d"}},
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+			wantDetail: "The request has more than 3 header fields",
+		},
+		"a request with a header value within the default limit is passed through": {
+			options:    nil,
+			header:     http.Header{"Cookie": {strings.Repeat("a", 8<<10)}},
+			wantStatus: http.StatusOK,
+			wantDetail: "",
+		},
+		"a request with a header value over the default limit is rejected": {
+			options:    nil,
+			header:     http.Header{"Cookie": {strings.Repeat("a", 8<<10+1)}},
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+			wantDetail: "The value of the Cookie header exceeds 8192 bytes",
+		},
+		"a request with an oversized header value is rejected": {
+			options:    []httputil.HeaderLimitsOption{httputil.WithHeaderLimitsMaxValueBytes(10)},
+			header:     http.Header{"X-Small": {"ok"}, "X-Large": {"ok", "this value is too long"}},
+			wantStatus: http.StatusRequestHeaderFieldsTooLarge,
+			wantDetail: "The value of the X-Large header exceeds 10 bytes",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodGet, Path: "/orders", Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})},
+			}.WithMiddleware(httputil.NewHeaderLimitsMiddleware(testCase.options...))...)
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if testCase.wantDetail == "" {
+				return
+			}
+
+			want := problem.RequestHeaderFieldsTooLarge(request).WithDetail(testCase.wantDetail).MustMarshalJSONString()
+			if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
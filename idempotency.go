@@ -0,0 +1,261 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// idempotencyKeyHeader is the request header that carries the idempotency key.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// ErrIdempotencyKeyInFlight is returned by an [IdempotencyStore] when a request
+// with the same key is still being processed.
+var ErrIdempotencyKeyInFlight = errors.New("idempotency key is in flight")
+
+type (
+	// IdempotentResponse is a response stored against an idempotency key so that
+	// it can be replayed for repeated requests.
+	IdempotentResponse struct {
+		StatusCode int
+		Header     http.Header
+		Body       []byte
+	}
+
+	// IdempotencyStore persists the responses of requests made with an
+	// idempotency key. Implementations must be safe for concurrent use.
+	IdempotencyStore interface {
+		// Reserve marks key as in flight. It returns the stored response if a
+		// request with key has already completed, or [ErrIdempotencyKeyInFlight]
+		// if a request with key is still being processed. A nil response and error
+		// indicate that the caller now holds the key.
+		Reserve(ctx context.Context, key string) (*IdempotentResponse, error)
+		// Complete stores response against key, releasing it from being in
+		// flight. A nil response releases key without storing anything so that
+		// the request can be retried.
+		Complete(ctx context.Context, key string, response *IdempotentResponse) error
+	}
+)
+
+// NewIdempotencyMiddleware creates a MiddlewareFunc that makes requests carrying
+// an Idempotency-Key header safe to retry. The first request with a key is
+// executed and, if it succeeds, its response is stored. Repeated requests with
+// the same key replay the stored response instead of executing the handler
+// again, while repeats that arrive before the first request has completed are
+// rejected with a [problem.RequestInProgress] error. Responses with a non-2xx
+// status are not stored so that failed requests can be retried.
+//
+// Keys are scoped to the request method and path. Requests without the header
+// are passed through unchanged. If store is nil, an in-memory store that keeps
+// responses for 24 hours is used.
+func NewIdempotencyMiddleware(store IdempotencyStore) MiddlewareFunc {
+	const defaultTTL = 24 * time.Hour
+
+	if store == nil {
+		store = NewInMemoryIdempotencyStore(defaultTTL)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(idempotencyKeyHeader)
+			if idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Method + " " + r.URL.Path + " " + idempotencyKey
+
+			stored, err := store.Reserve(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, ErrIdempotencyKeyInFlight) {
+					writeMiddlewareError(w, r, problem.RequestInProgress(r))
+					return
+				}
+
+				middlewareLogger(r).ErrorContext(r.Context(), "Idempotency store failed to reserve key", slog.Any("error", err))
+				writeMiddlewareError(w, r, problem.ServerError(r))
+
+				return
+			}
+
+			if stored != nil {
+				replayIdempotentResponse(w, r, stored)
+				return
+			}
+
+			recorder := &idempotencyRecorder{ResponseWriter: w, statusCode: 0, body: bytes.Buffer{}}
+
+			var response *IdempotentResponse
+
+			// Release the key even if next panics so that the request can be retried.
+			defer func() {
+				if err := store.Complete(r.Context(), key, response); err != nil {
+					middlewareLogger(r).ErrorContext(r.Context(), "Idempotency store failed to complete key", slog.Any("error", err))
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.succeeded() {
+				response = &IdempotentResponse{
+					StatusCode: recorder.statusCode,
+					Header:     w.Header().Clone(),
+					Body:       recorder.body.Bytes(),
+				}
+			}
+		})
+	}
+}
+
+// replayIdempotentResponse writes a stored response to w.
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, response *IdempotentResponse) {
+	for k, v := range response.Header {
+		w.Header()[k] = v
+	}
+
+	w.WriteHeader(response.StatusCode)
+
+	if _, err := w.Write(response.Body); err != nil {
+		middlewareLogger(r).ErrorContext(r.Context(), "Idempotency middleware failed to replay response", slog.Any("error", err))
+	}
+}
+
+// idempotencyRecorder is a http.ResponseWriter that records the status code and
+// body written through it so that they can be stored.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+
+	statusCode int
+	body       bytes.Buffer
+}
+
+// WriteHeader records the status code before writing it.
+func (ir *idempotencyRecorder) WriteHeader(statusCode int) {
+	if ir.statusCode == 0 {
+		ir.statusCode = statusCode
+	}
+
+	ir.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records b before writing it.
+func (ir *idempotencyRecorder) Write(b []byte) (int, error) {
+	if ir.statusCode == 0 {
+		ir.statusCode = http.StatusOK
+	}
+
+	ir.body.Write(b)
+
+	n, err := ir.ResponseWriter.Write(b)
+	if err != nil {
+		return n, fmt.Errorf("writing recorded response: %w", err)
+	}
+
+	return n, nil
+}
+
+// Unwrap returns the underlying http.ResponseWriter for use with
+// http.ResponseController.
+func (ir *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return ir.ResponseWriter
+}
+
+// succeeded reports whether a 2xx response was written.
+func (ir *idempotencyRecorder) succeeded() bool {
+	return ir.statusCode >= http.StatusOK && ir.statusCode < http.StatusMultipleChoices
+}
+
+// Ensure that our InMemoryIdempotencyStore implements the IdempotencyStore interface.
+var _ IdempotencyStore = &InMemoryIdempotencyStore{} //nolint:exhaustruct // Compile time implementation check.
+
+// InMemoryIdempotencyStore is an [IdempotencyStore] that keeps responses in
+// memory for a fixed TTL. It is suitable for single instance deployments and
+// tests; use a shared store when running multiple instances.
+type InMemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	ttl       time.Duration
+	nextSweep time.Time
+}
+
+// idempotencyEntry is a key held by the InMemoryIdempotencyStore. A nil
+// response indicates that the key is in flight.
+type idempotencyEntry struct {
+	response  *IdempotentResponse
+	expiresAt time.Time
+}
+
+// NewInMemoryIdempotencyStore creates an InMemoryIdempotencyStore that keeps
+// completed responses for ttl.
+func NewInMemoryIdempotencyStore(ttl time.Duration) *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		mu:        sync.Mutex{},
+		entries:   make(map[string]idempotencyEntry),
+		ttl:       ttl,
+		nextSweep: time.Time{},
+	}
+}
+
+// Reserve implements [IdempotencyStore]. Expired responses are evicted at
+// most once per ttl so that the store does not grow without bound when keys
+// are not reused.
+func (s *InMemoryIdempotencyStore) Reserve(_ context.Context, key string) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.After(s.nextSweep) {
+		for k, entry := range s.entries {
+			if entry.response != nil && !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+
+		s.nextSweep = now.Add(s.ttl)
+	}
+
+	entry, ok := s.entries[key]
+	if ok && entry.response == nil {
+		return nil, ErrIdempotencyKeyInFlight
+	}
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.response, nil
+	}
+
+	s.entries[key] = idempotencyEntry{response: nil, expiresAt: time.Time{}}
+
+	return nil, nil //nolint:nilnil // A nil response and error indicate the key was reserved.
+}
+
+// Len returns the number of keys held by the store, including keys that are
+// in flight and expired keys that have not yet been evicted.
+func (s *InMemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// Complete implements [IdempotencyStore].
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, key string, response *IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if response == nil {
+		delete(s.entries, key)
+		return nil
+	}
+
+	s.entries[key] = idempotencyEntry{response: response, expiresAt: time.Now().Add(s.ttl)}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewIdempotencyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(t *testing.T, handler http.Handler) *httputil.Server {
+		t.Helper()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+
+		server.Register(httputil.EndpointGroup{
+			{Method: http.MethodPost, Path: "/orders", Handler: handler},
+		}.WithMiddleware(httputil.NewIdempotencyMiddleware(httputil.NewInMemoryIdempotencyStore(time.Minute)))...)
+
+		return server
+	}
+
+	newRequest := func(key string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+
+		return request
+	}
+
+	countingHandler := func(calls *atomic.Int64, statusCode int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Call", strconv.FormatInt(calls.Add(1), 10))
+			w.WriteHeader(statusCode)
+			_, _ = w.Write([]byte(`{"id":"order-1"}`))
+		})
+	}
+
+	t.Run("executes the handler on the first call with a key", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+
+		server := newServer(t, countingHandler(&calls, http.StatusCreated))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest("key-1"))
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusCreated)
+		}
+
+		if got := calls.Load(); got != 1 {
+			t.Errorf("handler calls = %d, want: 1", got)
+		}
+	})
+
+	t.Run("replays the stored response on a duplicate key", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+
+		server := newServer(t, countingHandler(&calls, http.StatusCreated))
+
+		first := httptest.NewRecorder()
+		server.ServeHTTP(first, newRequest("key-1"))
+
+		replayed := httptest.NewRecorder()
+		server.ServeHTTP(replayed, newRequest("key-1"))
+
+		if got := calls.Load(); got != 1 {
+			t.Errorf("handler calls = %d, want: 1", got)
+		}
+
+		if replayed.Code != http.StatusCreated {
+			t.Errorf("replayed.Code = %d, want: %d", replayed.Code, http.StatusCreated)
+		}
+
+		if got, want := replayed.Body.String(), first.Body.String(); got != want {
+			t.Errorf("replayed.Body = %s, want: %s", got, want)
+		}
+
+		if got := replayed.Header().Get("X-Call"); got != "1" {
+			t.Errorf("replayed X-Call header = %s, want: 1", got)
+		}
+	})
+
+	t.Run("executes the handler for each distinct key and for requests without a key", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+
+		server := newServer(t, countingHandler(&calls, http.StatusCreated))
+
+		for _, key := range []string{"key-1", "key-2", "", ""} {
+			server.ServeHTTP(httptest.NewRecorder(), newRequest(key))
+		}
+
+		if got := calls.Load(); got != 4 {
+			t.Errorf("handler calls = %d, want: 4", got)
+		}
+	})
+
+	t.Run("does not store unsuccessful responses", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+
+		server := newServer(t, countingHandler(&calls, http.StatusInternalServerError))
+
+		server.ServeHTTP(httptest.NewRecorder(), newRequest("key-1"))
+		server.ServeHTTP(httptest.NewRecorder(), newRequest("key-1"))
+
+		if got := calls.Load(); got != 2 {
+			t.Errorf("handler calls = %d, want: 2", got)
+		}
+	})
+
+	t.Run("rejects a concurrent duplicate with a conflict", func(t *testing.T) {
+		t.Parallel()
+
+		started, release := make(chan struct{}), make(chan struct{})
+
+		server := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			server.ServeHTTP(httptest.NewRecorder(), newRequest("key-1"))
+		}()
+
+		<-started
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest("key-1"))
+
+		close(release)
+		<-done
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusConflict)
+		}
+
+		want := problem.RequestInProgress(newRequest("key-1")).MustMarshalJSONString()
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evicts expired keys that are not reserved again", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			store := httputil.NewInMemoryIdempotencyStore(time.Minute)
+
+			if _, err := store.Reserve(t.Context(), "key-1"); err != nil {
+				t.Fatalf("store.Reserve() error = %v, want: nil", err)
+			}
+
+			response := &httputil.IdempotentResponse{StatusCode: http.StatusCreated, Header: http.Header{}, Body: nil}
+			if err := store.Complete(t.Context(), "key-1", response); err != nil {
+				t.Fatalf("store.Complete() error = %v, want: nil", err)
+			}
+
+			time.Sleep(2 * time.Minute)
+
+			if _, err := store.Reserve(t.Context(), "key-2"); err != nil {
+				t.Fatalf("store.Reserve() error = %v, want: nil", err)
+			}
+
+			if got := store.Len(); got != 1 {
+				t.Errorf("store.Len() = %d, want: 1", got)
+			}
+		})
+	})
+}
@@ -0,0 +1,24 @@
+package testutil
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/nickbryan/slogutil/slogmem"
+)
+
+// AssertLog reports an error if logs does not contain a record with the given
+// level and message. Only the given attrs are compared so that attributes with
+// unpredictable values, such as durations, can be left out. Attribute keys are
+// dot separated paths to grouped attributes, as per [slogmem.RecordQuery]. It
+// returns whether a matching record was found.
+func AssertLog(t testing.TB, logs *slogmem.LoggedRecords, level slog.Level, message string, attrs map[string]slog.Value) bool {
+	t.Helper()
+
+	ok, diff := logs.Contains(slogmem.RecordQuery{Level: level, Message: message, Attrs: attrs})
+	if !ok {
+		t.Errorf("logs do not contain %s log %q (-want +got):\n%s", level, message, diff)
+	}
+
+	return ok
+}
@@ -0,0 +1,107 @@
+package testutil_test
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil/internal/testutil"
+)
+
+// recordingTB records the errors reported to it instead of failing the test.
+type recordingTB struct {
+	testing.TB
+
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLog(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		level       slog.Level
+		message     string
+		attrs       map[string]slog.Value
+		wantOK      bool
+		wantErrorIn []string
+	}{
+		"a matching record with all attributes is found": {
+			level:   slog.LevelError,
+			message: "Something failed",
+			attrs:   map[string]slog.Value{"error": slog.StringValue("boom"), "request.id": slog.StringValue("abc")},
+			wantOK:  true,
+		},
+		"a matching record is found when only some attributes are given": {
+			level:   slog.LevelError,
+			message: "Something failed",
+			attrs:   map[string]slog.Value{"error": slog.StringValue("boom")},
+			wantOK:  true,
+		},
+		"a matching record is found when no attributes are given": {
+			level:   slog.LevelError,
+			message: "Something failed",
+			attrs:   nil,
+			wantOK:  true,
+		},
+		"a mismatched attribute value reports a diff": {
+			level:       slog.LevelError,
+			message:     "Something failed",
+			attrs:       map[string]slog.Value{"error": slog.StringValue("bang")},
+			wantOK:      false,
+			wantErrorIn: []string{`logs do not contain ERROR log "Something failed"`, "bang", "boom"},
+		},
+		"a mismatched level reports a diff": {
+			level:       slog.LevelWarn,
+			message:     "Something failed",
+			attrs:       nil,
+			wantOK:      false,
+			wantErrorIn: []string{`logs do not contain WARN log "Something failed"`},
+		},
+		"a missing message reports a diff": {
+			level:       slog.LevelError,
+			message:     "Something else failed",
+			attrs:       nil,
+			wantOK:      false,
+			wantErrorIn: []string{`logs do not contain ERROR log "Something else failed"`},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			logger.Info("Something happened")
+			logger.Error("Something failed", slog.String("error", "boom"), slog.Group("request", slog.String("id", "abc")))
+
+			tb := &recordingTB{TB: t, errors: nil}
+
+			if ok := testutil.AssertLog(tb, logs, testCase.level, testCase.message, testCase.attrs); ok != testCase.wantOK {
+				t.Errorf("AssertLog() = %t, want: %t", ok, testCase.wantOK)
+			}
+
+			if testCase.wantOK && len(tb.errors) != 0 {
+				t.Errorf("AssertLog() reported errors = %q, want: none", tb.errors)
+			}
+
+			if !testCase.wantOK && len(tb.errors) != 1 {
+				t.Fatalf("AssertLog() reported %d errors, want: 1", len(tb.errors))
+			}
+
+			for _, want := range testCase.wantErrorIn {
+				if !strings.Contains(tb.errors[0], want) {
+					t.Errorf("AssertLog() error = %q, want it to contain: %q", tb.errors[0], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package httputil
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// LogAttributesFunc returns attributes that are added to the records logged
+// while serving r. See [WithServerLogAttributes].
+type LogAttributesFunc func(r *http.Request) []slog.Attr
+
+// requestLogger returns logger with the attributes returned by attributes for
+// r added. logger is returned unchanged if attributes is nil or returns no
+// attributes. A nil logger is replaced by slog.Default, so that handlers served
+// without a Server still log rather than panic.
+func requestLogger(logger *slog.Logger, r *http.Request, attributes LogAttributesFunc) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if attributes == nil {
+		return logger
+	}
+
+	attrs := attributes(r)
+	if len(attrs) == 0 {
+		return logger
+	}
+
+	return slog.New(logger.Handler().WithAttrs(attrs))
+}
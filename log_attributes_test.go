@@ -0,0 +1,172 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+	"github.com/nickbryan/slogutil/slogmem"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+)
+
+type userIDCtxKey struct{}
+
+func TestWithServerLogAttributes(t *testing.T) {
+	t.Parallel()
+
+	logAttributes := func(r *http.Request) []slog.Attr {
+		attrs := []slog.Attr{slog.String("tenant_id", r.Header.Get("X-Tenant-Id"))}
+
+		if userID, ok := r.Context().Value(userIDCtxKey{}).(string); ok {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+
+		return attrs
+	}
+
+	authGuard := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+		return r.WithContext(context.WithValue(r.Context(), userIDCtxKey{}, "user-1")), nil
+	})
+
+	failingGuard := httputil.GuardFunc(func(_ *http.Request) (*http.Request, error) {
+		return nil, errors.New("guard failed")
+	})
+
+	testCases := map[string]struct {
+		logAttributes httputil.LogAttributesFunc
+		endpoint      httputil.Endpoint
+		header        http.Header
+		wantLevel     slog.Level
+		wantMessage   string
+		wantAttrs     map[string]slog.Value
+	}{
+		"attributes are added to handler logs": {
+			logAttributes: logAttributes,
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return nil, errors.New("boom")
+				}),
+			},
+			header:      http.Header{"X-Tenant-Id": {"tenant-1"}},
+			wantLevel:   slog.LevelError,
+			wantMessage: "Handler received an unhandled error",
+			wantAttrs:   map[string]slog.Value{"tenant_id": slog.StringValue("tenant-1")},
+		},
+		"attributes from values added by a guard are added to handler logs": {
+			logAttributes: logAttributes,
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return nil, errors.New("boom")
+				}, httputil.WithHandlerGuard(authGuard)),
+			},
+			header:      http.Header{"X-Tenant-Id": {"tenant-1"}},
+			wantLevel:   slog.LevelError,
+			wantMessage: "Handler received an unhandled error",
+			wantAttrs: map[string]slog.Value{
+				"tenant_id": slog.StringValue("tenant-1"),
+				"user_id":   slog.StringValue("user-1"),
+			},
+		},
+		"attributes are added to request data logs": {
+			logAttributes: logAttributes,
+			endpoint: httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestData[struct {
+					Name string `json:"name"`
+				}]) (*httputil.Response, error) {
+					return httputil.NoContent()
+				}),
+			},
+			header:      http.Header{"X-Tenant-Id": {"tenant-1"}},
+			wantLevel:   slog.LevelWarn,
+			wantMessage: "Handler failed to decode request data",
+			wantAttrs:   map[string]slog.Value{"tenant_id": slog.StringValue("tenant-1")},
+		},
+		"attributes are added to net/http handler logs": {
+			logAttributes: logAttributes,
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}, httputil.WithHandlerGuard(failingGuard)),
+			},
+			header:      http.Header{"X-Tenant-Id": {"tenant-1"}},
+			wantLevel:   slog.LevelError,
+			wantMessage: "Unhandled error received by net/http handler",
+			wantAttrs:   map[string]slog.Value{"tenant_id": slog.StringValue("tenant-1")},
+		},
+		"attributes are added to panic logs": {
+			logAttributes: logAttributes,
+			endpoint: httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/test",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+					panic("boom")
+				}),
+			},
+			header:      http.Header{"X-Tenant-Id": {"tenant-1"}},
+			wantLevel:   slog.LevelError,
+			wantMessage: "Handler panicked",
+			wantAttrs:   map[string]slog.Value{"tenant_id": slog.StringValue("tenant-1")},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, httputil.WithServerLogAttributes(testCase.logAttributes))
+			server.Register(testCase.endpoint)
+
+			request := httptest.NewRequest(testCase.endpoint.Method, "/test", strings.NewReader("{"))
+			request.Header = testCase.header
+
+			server.ServeHTTP(httptest.NewRecorder(), request)
+
+			testutil.AssertLog(t, logs, testCase.wantLevel, testCase.wantMessage, testCase.wantAttrs)
+		})
+	}
+
+	t.Run("logs are unchanged without log attributes", func(t *testing.T) {
+		t.Parallel()
+
+		logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/test",
+			Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				return nil, errors.New("boom")
+			}),
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/test", nil)
+		request.Header.Set("X-Tenant-Id", "tenant-1")
+
+		server.ServeHTTP(httptest.NewRecorder(), request)
+
+		query := slogmem.RecordQuery{
+			Level:   slog.LevelError,
+			Message: "Handler received an unhandled error",
+			Attrs:   map[string]slog.Value{"tenant_id": slog.StringValue("tenant-1")},
+		}
+
+		if ok, _ := logs.Contains(query); ok {
+			t.Errorf("logs contain tenant_id attribute, want: none")
+		}
+	})
+}
@@ -3,8 +3,14 @@ package httputil
 import (
 	"context"
 	"log/slog"
+	"math"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nickbryan/httputil/problem"
 )
 
 // MiddlewareFunc defines a function type for HTTP server middleware. A MiddlewareFunc
@@ -14,28 +20,115 @@ type MiddlewareFunc func(next http.Handler) http.Handler
 
 // newPanicRecoveryMiddleware creates a MiddlewareFunc that recovers from panics
 // within handlers. It logs the panic using the provided logger and returns a 500
-// Internal Server Error to the client. It is important to note that any data
-// written to the ResponseWriter before the panic will be sent to the client.
-func newPanicRecoveryMiddleware(logger *slog.Logger) MiddlewareFunc {
+// Internal Server Error to the client, unless one of mappers translates the
+// recovered value into a problem. Records are logged with the attributes
+// returned by logAttributes, if not nil. When debugErrors is true, unmapped
+// panics are written as a server error problem carrying the recovered value
+// and stack. Problems are written with [writeMiddlewareError] for the request
+// that was being served by the endpoint that panicked, so that they use its
+// codec, the codec selected by [WithServerExtensionNegotiation] and the problem
+// instance of the Server. Panics outside an endpoint fall back to codec and
+// problemInstance. It is important to note that any data written to the
+// ResponseWriter before the panic will be sent to the client.
+func newPanicRecoveryMiddleware(
+	logger *slog.Logger,
+	codec ServerCodec,
+	mappers []PanicMapper,
+	problemInstance ProblemInstanceFunc,
+	logAttributes LogAttributesFunc,
+	debugErrors bool,
+) MiddlewareFunc {
+	// fallback is used to write problems for panics that happen before a
+	// request is routed to an endpoint.
+	fallback := &handlerContext{
+		codec:              codec,
+		debugErrors:        debugErrors,
+		errorMappers:       nil,
+		guard:              nil,
+		logAttributes:      logAttributes,
+		logger:             logger,
+		problemInstance:    problemInstance,
+		responseValidation: responseValidationOff,
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served := &servedRequest{request: r}
+
 			defer func(ctx context.Context) {
 				if err := recover(); err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-					logger.ErrorContext(
+					panicLogger := requestLogger(logger, r, logAttributes)
+					servedReq := served.withFallback(fallback)
+
+					if problemDetails := mapPanic(servedReq, err, mappers); problemDetails != nil {
+						panicLogger.WarnContext(ctx, "Handler panicked with a mapped value", slog.Any("error", err))
+						writeMiddlewareError(w, servedReq, problemDetails)
+
+						return
+					}
+
+					panicLogger.ErrorContext(
 						ctx,
 						"Handler panicked",
 						slog.Any("error", err),
 						slog.String("stack", string(debug.Stack())),
 					)
+
+					if !debugErrors {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+
+					writeMiddlewareError(w, servedReq, withPanicDebugExtensions(problem.ServerError(servedReq), err))
 				}
 			}(r.Context())
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), servedRequestCtxKey{}, served)))
 		})
 	}
 }
 
+// servedRequestCtxKey is the context key for the servedRequest of a request.
+type servedRequestCtxKey struct{}
+
+// servedRequest records the request passed to the endpoint that is serving a
+// request, which carries the handlerContext of the endpoint and the codec
+// selected by extension negotiation, so that a panic can be written in the
+// same way as other errors.
+type servedRequest struct {
+	request *http.Request
+}
+
+// recordServedRequest records r as the request being served by an endpoint, if
+// it is being served beneath the panic recovery middleware.
+func recordServedRequest(r *http.Request) {
+	if served, ok := r.Context().Value(servedRequestCtxKey{}).(*servedRequest); ok {
+		served.request = r
+	}
+}
+
+// withFallback returns the recorded request, carrying fallback as its
+// handlerContext if it was not routed to an endpoint.
+func (s *servedRequest) withFallback(fallback *handlerContext) *http.Request {
+	if handlerContextFrom(s.request.Context()) != nil {
+		return s.request
+	}
+
+	return s.request.WithContext(context.WithValue(s.request.Context(), handlerCtxKey{}, fallback))
+}
+
+// mapPanic returns the problem produced by the first of mappers to recognise
+// recovered, or nil if none do.
+func mapPanic(r *http.Request, recovered any, mappers []PanicMapper) *problem.DetailedError {
+	for _, mapper := range mappers {
+		if problemDetails := mapper(r, recovered); problemDetails != nil {
+			return problemDetails
+		}
+	}
+
+	return nil
+}
+
 // newMaxBodySizeMiddleware creates a middleware that enforces an upper limit on
 // the size of request bodies. This is important to:
 //   - Protect the server from being overwhelmed by excessively large requests.
@@ -43,15 +136,33 @@ func newPanicRecoveryMiddleware(logger *slog.Logger) MiddlewareFunc {
 //     malicious clients send extremely large payloads to consume server resources.
 //   - Ensure efficient use of server memory and processing resources.
 //
-// If the ContentLength exceeds maxBytes, it responds with a 413 status code. It
-// also wraps the request body with http.MaxBytesReader to enforce the limit
+// If the ContentLength exceeds maxBytes, it responds with a 413 status code,
+// logging a warning with the attributes returned by logAttributes, if not nil.
+// It also wraps the request body with http.MaxBytesReader to enforce the limit
 // during reading.
-func newMaxBodySizeMiddleware(logger *slog.Logger, maxBytes int64) func(http.Handler) http.Handler {
+//
+// The check happens before the body is read, so a client that sends
+// "Expect: 100-continue" with an oversized Content-Length is rejected without
+// being told to continue and never streams the body. Requests with any other
+// expectation are rejected with a 417 status code. net/http already does this
+// for HTTP/1.1, but not for HTTP/2 or when the Server is called directly.
+func newMaxBodySizeMiddleware(logger *slog.Logger, maxBytes int64, logAttributes LogAttributesFunc) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if expect := r.Header.Get("Expect"); expect != "" && !strings.EqualFold(expect, "100-continue") {
+				http.Error(w, "Expectation failed", http.StatusExpectationFailed)
+				requestLogger(logger, r, logAttributes).WarnContext(
+					r.Context(),
+					"Request has an unsupported expectation",
+					slog.String("expect", expect),
+				)
+
+				return
+			}
+
 			if r.ContentLength > maxBytes {
 				http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
-				logger.WarnContext(
+				requestLogger(logger, r, logAttributes).WarnContext(
 					r.Context(),
 					"Request body exceeds max bytes limit",
 					slog.Int64("max_bytes", maxBytes),
@@ -68,3 +179,116 @@ func newMaxBodySizeMiddleware(logger *slog.Logger, maxBytes int64) func(http.Han
 		})
 	}
 }
+
+// newRequestDeadlineMiddleware creates a middleware that bounds the request
+// context by the timeout sent in the header named name, so that work done by
+// handlers is abandoned once the client has stopped waiting. The header value
+// is parsed with parseRequestTimeout. Requests without the header, or with an
+// invalid or non-positive value, are passed through unchanged. If name is
+// empty, the middleware does nothing.
+func newRequestDeadlineMiddleware(name string) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if name == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := parseRequestTimeout(name, r.Header.Get(name))
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// parseRequestTimeout parses the value of the request timeout header named
+// name. The grpc-timeout header uses the gRPC format of up to 8 digits followed
+// by a unit of H, M, S, m, u or n. Other headers accept a number of seconds or
+// a duration string as parsed by time.ParseDuration, such as "1.5s" or "500ms".
+// It reports false if value is empty, invalid, or not positive.
+func parseRequestTimeout(name, value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if strings.EqualFold(name, "grpc-timeout") {
+		return parseGRPCTimeout(value)
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0 && seconds <= int64(math.MaxInt64/time.Second)
+	}
+
+	timeout, err := time.ParseDuration(value)
+
+	return timeout, err == nil && timeout > 0
+}
+
+// parseGRPCTimeout parses a timeout in the format of the grpc-timeout header.
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	const maxDigits = 8
+
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+
+	digits, unitByte := value[:len(value)-1], value[len(value)-1]
+
+	unit, ok := units[unitByte]
+	if !ok || digits == "" || len(digits) > maxDigits {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(amount) * unit, true
+}
+
+// middlewareLogger returns the logger of the Server that the request is being
+// served by, with the log attributes of the request added, falling back to
+// slog.Default when served outside a Server.
+func middlewareLogger(r *http.Request) *slog.Logger {
+	if hc := handlerContextFrom(r.Context()); hc != nil && hc.logger != nil {
+		return requestLogger(hc.logger, r, hc.logAttributes)
+	}
+
+	return slog.Default()
+}
+
+// writeMiddlewareError encodes problemDetails with the codec selected by the
+// extension suffix of the request path, see [WithServerExtensionNegotiation],
+// or the codec of the Server that the request is being served by, falling
+// back to JSON when served outside a Server. The instance of problemDetails is
+// set as configured on the Server.
+func writeMiddlewareError(w http.ResponseWriter, r *http.Request, problemDetails *problem.DetailedError) {
+	var codec ServerCodec = NewJSONServerCodec()
+	if hc := handlerContextFrom(r.Context()); hc != nil {
+		if hc.codec != nil {
+			codec = hc.codec
+		}
+
+		problemDetails = applyProblemInstance(r, problemDetails, hc.problemInstance)
+	}
+
+	if extensionCodec := extensionCodecFromContext(r.Context()); extensionCodec != nil {
+		codec = extensionCodec
+	}
+
+	if err := codec.EncodeError(w, problemDetails.Status, problemDetails); err != nil {
+		middlewareLogger(r).ErrorContext(r.Context(), "Middleware failed to encode error data", slog.Any("error", err))
+	}
+}
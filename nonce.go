@@ -0,0 +1,107 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// NonceStore records the nonces of requests accepted by [NewNonceGuard].
+// Implementations must be safe for concurrent use.
+type NonceStore interface {
+	// Use marks nonce as used for ttl. It reports false if nonce has already
+	// been used and has not yet expired, in which case the request is a replay.
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// NewNonceGuard creates a Guard that protects against replayed requests by
+// requiring each request to carry a unique nonce in the given header. The first
+// request with a nonce is accepted and the nonce is recorded in store for ttl.
+// Any other request with the same nonce within ttl is rejected with a
+// [problem.ResourceExists] error, without executing the handler.
+//
+// Unlike [NewIdempotencyMiddleware], repeated requests are rejected rather than
+// replayed, and nonces are not scoped to the request method and path. Requests
+// without the header are rejected with a [problem.BadParameters] error. If
+// store is nil, an [InMemoryNonceStore] is used. Errors returned by store are
+// treated as unhandled errors.
+func NewNonceGuard(store NonceStore, header string, ttl time.Duration) GuardFunc {
+	if store == nil {
+		store = NewInMemoryNonceStore()
+	}
+
+	return func(r *http.Request) (*http.Request, error) {
+		nonce := r.Header.Get(header)
+		if nonce == "" {
+			return nil, problem.BadParameters(r, problem.Parameter{
+				Parameter: header,
+				Detail:    "is required",
+				Type:      problem.ParameterTypeHeader,
+			})
+		}
+
+		accepted, err := store.Use(r.Context(), nonce, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("using nonce: %w", err)
+		}
+
+		if !accepted {
+			return nil, problem.ResourceExists(r).WithDetail("A request has already been made with the specified nonce")
+		}
+
+		return r, nil
+	}
+}
+
+// Ensure that our InMemoryNonceStore implements the NonceStore interface.
+var _ NonceStore = &InMemoryNonceStore{} //nolint:exhaustruct // Compile time implementation check.
+
+// InMemoryNonceStore is a [NonceStore] that keeps nonces in memory until they
+// expire. It is suitable for single instance deployments and tests; use a
+// shared store when running multiple instances, otherwise a request can be
+// replayed against another instance.
+type InMemoryNonceStore struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewInMemoryNonceStore creates an empty InMemoryNonceStore.
+func NewInMemoryNonceStore() *InMemoryNonceStore {
+	return &InMemoryNonceStore{
+		mu:        sync.Mutex{},
+		expiresAt: make(map[string]time.Time),
+		nextSweep: time.Time{},
+	}
+}
+
+// Use implements [NonceStore]. Expired nonces are evicted at most once per ttl
+// so that the store does not grow without bound.
+func (s *InMemoryNonceStore) Use(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.After(s.nextSweep) {
+		for n, expiresAt := range s.expiresAt {
+			if !now.Before(expiresAt) {
+				delete(s.expiresAt, n)
+			}
+		}
+
+		s.nextSweep = now.Add(ttl)
+	}
+
+	if expiresAt, ok := s.expiresAt[nonce]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	s.expiresAt[nonce] = now.Add(ttl)
+
+	return true, nil
+}
@@ -0,0 +1,160 @@
+package httputil_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+// failingNonceStore is a NonceStore that always fails.
+type failingNonceStore struct{}
+
+func (failingNonceStore) Use(_ context.Context, _ string, _ time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestNewNonceGuard(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(nonce string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+		if nonce != "" {
+			request.Header.Set("X-Nonce", nonce)
+		}
+
+		return request
+	}
+
+	t.Run("the first request with a nonce is accepted", func(t *testing.T) {
+		t.Parallel()
+
+		request := newRequest("abc")
+
+		guardedRequest, err := httputil.NewNonceGuard(nil, "X-Nonce", time.Minute).Guard(request)
+		assertGuardProblem(t, err, nil)
+
+		if guardedRequest != request {
+			t.Errorf("guardedRequest = %p, want the original request %p", guardedRequest, request)
+		}
+	})
+
+	t.Run("an immediate replay of a nonce is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		guard := httputil.NewNonceGuard(httputil.NewInMemoryNonceStore(), "X-Nonce", time.Minute)
+
+		_, err := guard.Guard(newRequest("abc"))
+		assertGuardProblem(t, err, nil)
+
+		_, err = guard.Guard(newRequest("abc"))
+		assertGuardProblem(t, err, problem.ResourceExists(newRequest("abc")).WithDetail("A request has already been made with the specified nonce"))
+	})
+
+	t.Run("requests with different nonces are accepted", func(t *testing.T) {
+		t.Parallel()
+
+		guard := httputil.NewNonceGuard(nil, "X-Nonce", time.Minute)
+
+		for _, nonce := range []string{"abc", "def"} {
+			_, err := guard.Guard(newRequest(nonce))
+			assertGuardProblem(t, err, nil)
+		}
+	})
+
+	t.Run("a nonce is accepted again once it has expired", func(t *testing.T) {
+		t.Parallel()
+
+		synctest.Test(t, func(t *testing.T) {
+			guard := httputil.NewNonceGuard(httputil.NewInMemoryNonceStore(), "X-Nonce", time.Minute)
+
+			_, err := guard.Guard(newRequest("abc"))
+			assertGuardProblem(t, err, nil)
+
+			time.Sleep(59 * time.Second)
+
+			_, err = guard.Guard(newRequest("abc"))
+			assertGuardProblem(t, err, problem.ResourceExists(newRequest("abc")).WithDetail("A request has already been made with the specified nonce"))
+
+			time.Sleep(time.Second)
+
+			_, err = guard.Guard(newRequest("abc"))
+			assertGuardProblem(t, err, nil)
+		})
+	})
+
+	t.Run("a request without a nonce is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := httputil.NewNonceGuard(nil, "X-Nonce", time.Minute).Guard(newRequest(""))
+		assertGuardProblem(t, err, problem.BadParameters(newRequest(""), problem.Parameter{
+			Parameter: "X-Nonce",
+			Detail:    "is required",
+			Type:      problem.ParameterTypeHeader,
+		}))
+	})
+
+	t.Run("store errors are returned as unhandled errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := httputil.NewNonceGuard(failingNonceStore{}, "X-Nonce", time.Minute).Guard(newRequest("abc"))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if _, ok := errors.AsType[*problem.DetailedError](err); ok {
+			t.Errorf("expected an unhandled error, got problem: %+v", err)
+		}
+	})
+
+	t.Run("a replayed request is rejected by the server without calling the handler", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+
+		calls := 0
+
+		server.Register(httputil.EndpointGroup{{
+			Method: http.MethodPost,
+			Path:   "/transfers",
+			Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				calls++
+				return httputil.NoContent()
+			}),
+		}}.WithGuard(httputil.NewNonceGuard(nil, "X-Nonce", time.Minute))...)
+
+		first := httptest.NewRecorder()
+		server.ServeHTTP(first, newRequest("abc"))
+
+		if first.Code != http.StatusNoContent {
+			t.Fatalf("first response.Code = %d, want: %d", first.Code, http.StatusNoContent)
+		}
+
+		replay := httptest.NewRecorder()
+		server.ServeHTTP(replay, newRequest("abc"))
+
+		if replay.Code != http.StatusConflict {
+			t.Fatalf("replay response.Code = %d, want: %d", replay.Code, http.StatusConflict)
+		}
+
+		want := problem.ResourceExists(newRequest("abc")).WithDetail("A request has already been made with the specified nonce").MustMarshalJSONString()
+		if diff := testutil.DiffJSON(want, replay.Body.String()); diff != "" {
+			t.Errorf("replay response.Body mismatch (-want +got):\n%s", diff)
+		}
+
+		if calls != 1 {
+			t.Errorf("handler calls = %d, want: 1", calls)
+		}
+	})
+}
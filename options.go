@@ -1,9 +1,12 @@
 package httputil
 
 import (
+	"crypto/tls"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -14,14 +17,36 @@ type (
 	// RedirectPolicy defines the policy for handling HTTP redirects.
 	RedirectPolicy func(req *http.Request, via []*http.Request) error
 
+	// TransportConfig holds the connection pool settings applied to the Client's
+	// *http.Transport by [WithClientTransportConfig]. Zero fields leave the
+	// transport's existing value unchanged; see http.Transport for the meaning of
+	// each field.
+	TransportConfig struct {
+		// MaxIdleConns is the maximum number of idle connections across all hosts.
+		MaxIdleConns int
+		// MaxIdleConnsPerHost is the maximum number of idle connections to keep
+		// per host.
+		MaxIdleConnsPerHost int
+		// MaxConnsPerHost limits the total number of connections per host,
+		// including those dialing, active and idle.
+		MaxConnsPerHost int
+		// IdleConnTimeout is how long an idle connection remains in the pool
+		// before it is closed.
+		IdleConnTimeout time.Duration
+	}
+
 	clientOptions struct {
-		basePath      string
-		checkRedirect RedirectPolicy
-		encoder       ClientEncoder
-		interceptors  []InterceptorFunc
-		jar           http.CookieJar
-		rootTransport http.RoundTripper
-		timeout       time.Duration
+		basePath           string
+		budget             bool
+		checkRedirect      RedirectPolicy
+		disableCompression bool
+		encoder            ClientEncoder
+		h2c                bool
+		interceptors       []InterceptorFunc
+		jar                http.CookieJar
+		rootTransport      http.RoundTripper
+		timeout            time.Duration
+		transportConfig    TransportConfig
 	}
 )
 
@@ -33,6 +58,41 @@ func WithClientBasePath(basePath string) ClientOption {
 	}
 }
 
+// WithClientBudget makes the Client bound requests by the [RequestBudget]
+// carried by their context, see [NewRequestBudgetContext], and retry them
+// within it. Each attempt is given the time remaining in the budget as its
+// deadline, and attempts that fail with a transport error or a 502, 503 or 504
+// status are retried while the budget has retries and time left. Retries are
+// delayed by a capped exponential backoff with jitter, or by the Retry-After
+// header of a 503 response, and are not made if the delay would outlast the
+// budget. Only idempotent requests are retried, which are those with the GET,
+// HEAD, OPTIONS, TRACE, PUT or DELETE method or an Idempotency-Key header, as
+// the upstream may already have applied a failed attempt. Requests with a body
+// are only retried if it can be replayed, which is the case for bodies encoded
+// by the Client's ClientEncoder. Requests made once the time in the budget has
+// run out fail with [ErrRequestBudgetExhausted] without being sent. Requests
+// without a budget are not retried.
+//
+// Retries run outside of the interceptors added with [WithClientInterceptor],
+// so that each attempt passes through them.
+func WithClientBudget() ClientOption {
+	return func(co *clientOptions) {
+		co.budget = true
+	}
+}
+
+// WithClientDisableCompression stops the Client from requesting gzip encoding
+// and transparently decompressing responses, so that the response body and its
+// Content-Encoding and Content-Length headers are returned as sent on the wire.
+// The option applies to an *http.Transport root transport, including the
+// default, by cloning it with DisableCompression set; other transports set via
+// [WithClientTransport] are used unchanged.
+func WithClientDisableCompression() ClientOption {
+	return func(co *clientOptions) {
+		co.disableCompression = true
+	}
+}
+
 // WithClientEncoder sets the ClientEncoder that the Client will use for
 // encoding request bodies and setting the Content-Type header.
 func WithClientEncoder(encoder ClientEncoder) ClientOption {
@@ -41,6 +101,19 @@ func WithClientEncoder(encoder ClientEncoder) ClientOption {
 	}
 }
 
+// WithClientH2C makes the Client use unencrypted HTTP/2 (h2c) with prior
+// knowledge for http:// URLs, for example for traffic within a service mesh.
+// The server must accept h2c, see [WithServerH2C]; HTTP/1.1 is not used as a
+// fallback. Requests to https:// URLs continue to negotiate HTTP/2 over TLS.
+// The option applies to an *http.Transport root transport, including the
+// default, by cloning it; other transports set via [WithClientTransport] are
+// used unchanged.
+func WithClientH2C() ClientOption {
+	return func(co *clientOptions) {
+		co.h2c = true
+	}
+}
+
 // WithClientCookieJar sets the CookieJar that the Client will use when making requests.
 func WithClientCookieJar(jar http.CookieJar) ClientOption {
 	return func(co *clientOptions) {
@@ -49,7 +122,7 @@ func WithClientCookieJar(jar http.CookieJar) ClientOption {
 }
 
 // WithClientTransport sets the base transport for the Client. By default, the
-// Client uses http.DefaultTransport. Interceptors added via
+// Client uses its own clone of http.DefaultTransport. Interceptors added via
 // WithClientInterceptor will wrap this transport.
 func WithClientTransport(transport http.RoundTripper) ClientOption {
 	return func(co *clientOptions) {
@@ -57,6 +130,17 @@ func WithClientTransport(transport http.RoundTripper) ClientOption {
 	}
 }
 
+// WithClientTransportConfig applies the connection pool settings in config to
+// an *http.Transport root transport, including the default, by cloning it.
+// Other transports set via [WithClientTransport] are used unchanged. The
+// settings apply to the root transport, so they are unaffected by interceptors
+// added via WithClientInterceptor.
+func WithClientTransportConfig(config TransportConfig) ClientOption {
+	return func(co *clientOptions) {
+		co.transportConfig = config
+	}
+}
+
 // WithClientInterceptor adds InterceptorFuncs to the Client. Nil interceptors
 // are skipped.
 //
@@ -98,6 +182,23 @@ func WithClientRedirectPolicy(policy RedirectPolicy) ClientOption {
 	}
 }
 
+// WithClientSafeRedirects sets a RedirectPolicy that removes the Authorization,
+// Cookie and Proxy-Authorization headers when a redirect leaves the origin,
+// scheme and host including port, of the original request. Unlike the default
+// policy of http.Client, credentials are not forwarded to subdomains or other
+// ports of the same host. Request bodies are replayed on 307 and 308 redirects
+// if the request has GetBody set, which is the case for bodies encoded by the
+// Client's ClientEncoder. At most 10 redirects are followed.
+//
+// WithClientSafeRedirects replaces any policy set by
+// [WithClientRedirectPolicy], and vice versa, with the last option applied
+// taking effect.
+func WithClientSafeRedirects() ClientOption {
+	return func(co *clientOptions) {
+		co.checkRedirect = safeRedirectPolicy
+	}
+}
+
 // mapClientOptionsToDefaults applies the provided ClientOption to a default
 // clientOptions struct.
 func mapClientOptionsToDefaults(opts []ClientOption) clientOptions {
@@ -109,38 +210,99 @@ func mapClientOptionsToDefaults(opts []ClientOption) clientOptions {
 	)
 
 	defaultOpts := clientOptions{
-		basePath:      "",
-		checkRedirect: nil,
-		encoder:       NewJSONClientEncoder(),
-		interceptors:  nil,
-		jar:           nil,
-		rootTransport: nil,
-		timeout:       defaultTimeout,
+		basePath:           "",
+		budget:             false,
+		checkRedirect:      nil,
+		disableCompression: false,
+		encoder:            NewJSONClientEncoder(),
+		h2c:                false,
+		interceptors:       nil,
+		jar:                nil,
+		rootTransport:      nil,
+		timeout:            defaultTimeout,
+		transportConfig:    TransportConfig{MaxIdleConns: 0, MaxIdleConnsPerHost: 0, MaxConnsPerHost: 0, IdleConnTimeout: 0},
 	}
 
 	for _, opt := range opts {
 		opt(&defaultOpts)
 	}
 
-	// Coerce a nil root transport to http.DefaultTransport so that interceptors
-	// always receive a non-nil next RoundTripper to call. This handles both the
+	// Coerce a nil root transport to a clone of http.DefaultTransport so that
+	// interceptors always receive a non-nil next RoundTripper to call and so that
+	// per-client settings never modify the shared default. This handles both the
 	// zero-value default and an explicit WithClientTransport(nil).
 	if defaultOpts.rootTransport == nil {
-		defaultOpts.rootTransport = http.DefaultTransport
+		defaultOpts.rootTransport = cloneDefaultTransport()
+	}
+
+	// Clone rather than mutate the transport as one supplied via
+	// WithClientTransport may be shared.
+	configured := defaultOpts.disableCompression || defaultOpts.h2c || defaultOpts.transportConfig != (TransportConfig{}) //nolint:exhaustruct // Comparing against the zero value.
+	if transport, ok := defaultOpts.rootTransport.(*http.Transport); ok && configured {
+		transport = transport.Clone()
+		transport.DisableCompression = transport.DisableCompression || defaultOpts.disableCompression
+		defaultOpts.transportConfig.apply(transport)
+
+		if defaultOpts.h2c {
+			// Omitting HTTP1 makes the transport use h2c with prior knowledge for
+			// http:// URLs rather than HTTP/1.1.
+			transport.Protocols = new(http.Protocols)
+			transport.Protocols.SetHTTP2(true)
+			transport.Protocols.SetUnencryptedHTTP2(true)
+		}
+
+		defaultOpts.rootTransport = transport
 	}
 
 	return defaultOpts
 }
 
+// apply sets the non-zero fields of the TransportConfig on transport.
+func (tc TransportConfig) apply(transport *http.Transport) {
+	if tc.MaxIdleConns != 0 {
+		transport.MaxIdleConns = tc.MaxIdleConns
+	}
+
+	if tc.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = tc.MaxIdleConnsPerHost
+	}
+
+	if tc.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = tc.MaxConnsPerHost
+	}
+
+	if tc.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = tc.IdleConnTimeout
+	}
+}
+
+// cloneDefaultTransport returns a clone of http.DefaultTransport. If
+// http.DefaultTransport has been replaced with a RoundTripper that is not an
+// *http.Transport, it is returned as is.
+func cloneDefaultTransport() http.RoundTripper {
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		return transport.Clone()
+	}
+
+	return http.DefaultTransport
+}
+
 type (
 	// HandlerOption allows default handler config values to be overridden.
 	HandlerOption func(ho *handlerOptions)
 
 	handlerOptions struct {
-		codec       ServerCodec
-		guard       Guard
-		logger      *slog.Logger
-		messageFunc MessageFunc
+		bodyReadTimeout    time.Duration
+		codec              ServerCodec
+		defaultContentType string
+		deprecation        *deprecation
+		guard              Guard
+		logger             *slog.Logger
+		messageFunc        MessageFunc
+		plainTextErrors    bool
+		requestSchema      []byte
+		responseSchema     []byte
+		sparseFieldsets    bool
 	}
 )
 
@@ -157,6 +319,21 @@ func WithHandlerMessages(fn MessageFunc) HandlerOption {
 	}
 }
 
+// WithHandlerBodyReadTimeout bounds the time that handlers created by
+// [NewHandler] or [NewFormHandler] wait for the request body to be read and
+// decoded. The read deadline of the connection is set before the body is read
+// and cleared afterwards, so that the Action is not limited by it. A body that
+// is not read in time results in a [problem.BadRequest] response.
+//
+// Unlike [WithServerReadTimeout], which covers the headers and body of every
+// request, this only applies to the body of requests served by the handler. It
+// has no effect when the http.ResponseWriter does not support read deadlines.
+func WithHandlerBodyReadTimeout(timeout time.Duration) HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.bodyReadTimeout = timeout
+	}
+}
+
 // WithHandlerCodec sets the ServerCodec that the Handler will use when [NewHandler] is called.
 func WithHandlerCodec(codec ServerCodec) HandlerOption {
 	return func(ho *handlerOptions) {
@@ -164,6 +341,29 @@ func WithHandlerCodec(codec ServerCodec) HandlerOption {
 	}
 }
 
+// WithHandlerDefaultContentType sets the Content-Type used when an Action writes
+// to [Request.ResponseWriter] directly without setting one, preventing Go from
+// sniffing the content type from the written bytes. It also sets the
+// X-Content-Type-Options: nosniff header so that clients do not sniff either.
+// Responses encoded by the [ServerCodec] keep the codec's content type.
+func WithHandlerDefaultContentType(contentType string) HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.defaultContentType = contentType
+	}
+}
+
+// WithHandlerDeprecation marks the endpoints served by the handler as
+// deprecated since date. Every response, including error responses, carries a
+// Deprecation header as defined by RFC 9745, a Sunset header as defined by RFC
+// 8594 when sunset is not zero, and a Link header with a rel of "deprecation"
+// pointing to link when it is not empty. Each request is also logged as a
+// warning so that remaining clients can be identified before the sunset.
+func WithHandlerDeprecation(date, sunset time.Time, link string) HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.deprecation = &deprecation{date: date, sunset: sunset, link: link}
+	}
+}
+
 // WithHandlerGuard sets the Guard that the Handler will use when [NewHandler] is called.
 func WithHandlerGuard(guard Guard) HandlerOption {
 	return func(ho *handlerOptions) {
@@ -178,14 +378,68 @@ func WithHandlerLogger(logger *slog.Logger) HandlerOption {
 	}
 }
 
+// WithHandlerPlainTextErrors makes a handler created by [WrapNetHTTPHandler]
+// write guard errors as application/problem+text instead of encoding them with
+// the [ServerCodec]. It has no effect on handlers created by [NewHandler].
+func WithHandlerPlainTextErrors() HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.plainTextErrors = true
+	}
+}
+
+// WithHandlerRequestSchema sets a JSON Schema that the raw request body is
+// validated against before it is decoded into the request data. Violations are
+// reported as a [problem.ConstraintViolation] with a JSON pointer to each
+// offending property. This allows contracts that cannot be expressed with
+// struct tags, such as oneOf or additionalProperties, to be enforced.
+//
+// [NewHandler] panics if the schema cannot be compiled. The option has no effect
+// on handlers created by [NewFormHandler] or [WrapNetHTTPHandler].
+func WithHandlerRequestSchema(schema []byte) HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.requestSchema = schema
+	}
+}
+
+// WithHandlerResponseSchema sets a JSON Schema that successful response data is
+// validated against when response validation is enabled on the Server via
+// [WithServerResponseValidation]. Without a schema, response data is validated
+// using its validate struct tags instead.
+//
+// [NewHandler] panics if the schema cannot be compiled.
+func WithHandlerResponseSchema(schema []byte) HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.responseSchema = schema
+	}
+}
+
+// WithHandlerSparseFieldsets projects the response data of the handler to the
+// fields listed in the "fields" query parameter, such as ?fields=id,name, using
+// [SparseFieldset]. Requests without the parameter receive the full response.
+// A request that names a field that is not a member of the response data is
+// rejected with a [problem.BadParameters] error. Problem responses are never
+// projected.
+func WithHandlerSparseFieldsets() HandlerOption {
+	return func(ho *handlerOptions) {
+		ho.sparseFieldsets = true
+	}
+}
+
 // mapHandlerOptionsToDefaults applies the provided HandlerOption to a default
 // handlerOptions struct.
 func mapHandlerOptionsToDefaults(opts []HandlerOption) handlerOptions {
 	defaultOpts := handlerOptions{
-		codec:       nil,
-		guard:       nil,
-		logger:      nil,
-		messageFunc: nil,
+		bodyReadTimeout:    0,
+		codec:              nil,
+		defaultContentType: "",
+		deprecation:        nil,
+		guard:              nil,
+		logger:             nil,
+		messageFunc:        nil,
+		plainTextErrors:    false,
+		requestSchema:      nil,
+		responseSchema:     nil,
+		sparseFieldsets:    false,
 	}
 
 	for _, opt := range opts {
@@ -257,15 +511,40 @@ type (
 	ServerOption func(so *serverOptions)
 
 	serverOptions struct {
-		address           string
-		codec             ServerCodec
-		idleTimeout       time.Duration
-		maxBodySize       int64
-		readHeaderTimeout time.Duration
-		readTimeout       time.Duration
-		shutdownTimeout   time.Duration
-		writeTimeout      time.Duration
-	}
+		address            string
+		codec              ServerCodec
+		debugErrors        bool
+		errorMappers       []ErrorMapper
+		extensionCodecs    map[string]ServerCodec
+		h2c                bool
+		idleTimeout        time.Duration
+		logAttributes      LogAttributesFunc
+		maxBodySize        int64
+		panicMappers       []PanicMapper
+		problemInstance    ProblemInstanceFunc
+		readHeaderTimeout  time.Duration
+		readTimeout        time.Duration
+		requestDeadline    string
+		responseValidation responseValidationMode
+		shutdownTimeout    time.Duration
+		tlsConfig          *tls.Config
+		verifyDigest       bool
+		writeTimeout       time.Duration
+	}
+
+	// responseValidationMode determines whether response data is validated
+	// against its contract and what happens when it is violated.
+	responseValidationMode int
+)
+
+const (
+	// responseValidationOff disables response validation.
+	responseValidationOff responseValidationMode = iota
+	// responseValidationFail logs a violation and replaces the response with a
+	// server error.
+	responseValidationFail
+	// responseValidationLog logs a violation and writes the response unchanged.
+	responseValidationLog
 )
 
 // WithServerAddress sets the address that the Server will listen to and serve on.
@@ -282,6 +561,66 @@ func WithServerCodec(codec ServerCodec) ServerOption {
 	}
 }
 
+// WithServerDebugErrors sets whether server error responses include the
+// underlying error, or recovered panic value, as the "error" extension member.
+// Responses to unmapped panics also include the stack of the panic as the
+// "stack" extension member, and are then written as a [problem.ServerError]
+// instead of an empty 500 response. The stack is not included for errors, as
+// it would show where the response was written rather than where the error
+// came from.
+//
+// Debug errors are disabled by default as they expose internal details to the
+// client. They are intended for local development only and must never be
+// enabled in production.
+func WithServerDebugErrors(enabled bool) ServerOption {
+	return func(so *serverOptions) {
+		so.debugErrors = enabled
+	}
+}
+
+// WithServerErrorMapper adds ErrorMappers that handlers registered with the
+// Server use to translate errors into problem responses before falling back to
+// a server error. Mappers are tried in the order they are added until one
+// returns a non-nil problem. Nil mappers are skipped.
+func WithServerErrorMapper(mappers ...ErrorMapper) ServerOption {
+	return func(so *serverOptions) {
+		for _, mapper := range mappers {
+			if mapper != nil {
+				so.errorMappers = append(so.errorMappers, mapper)
+			}
+		}
+	}
+}
+
+// WithServerExtensionNegotiation makes the Server select the codec of a request
+// by the extension suffix of its path, such as /report.json or /report.xml, so
+// one endpoint can serve several formats without a route per format. The keys
+// of codecs are extensions, with or without the leading dot. When the last
+// segment of a request path ends with one of them, the suffix is stripped
+// before the request is routed and the matching codec replaces the codec of
+// the handler for that request. Requests without a registered extension use
+// the codec of the handler as usual.
+func WithServerExtensionNegotiation(codecs map[string]ServerCodec) ServerOption {
+	return func(so *serverOptions) {
+		if so.extensionCodecs == nil {
+			so.extensionCodecs = make(map[string]ServerCodec, len(codecs))
+		}
+
+		for ext, codec := range codecs {
+			so.extensionCodecs[strings.TrimPrefix(ext, ".")] = codec
+		}
+	}
+}
+
+// WithServerH2C makes the Server accept unencrypted HTTP/2 (h2c) connections
+// that use prior knowledge, in addition to HTTP/1.1, for example for traffic
+// within a service mesh. Use [WithClientH2C] to make requests over h2c.
+func WithServerH2C() ServerOption {
+	return func(so *serverOptions) {
+		so.h2c = true
+	}
+}
+
 // WithServerIdleTimeout sets the idle timeout for the server. This determines how
 // long the server will keep an idle connection alive.
 func WithServerIdleTimeout(timeout time.Duration) ServerOption {
@@ -290,6 +629,18 @@ func WithServerIdleTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithServerLogAttributes sets a LogAttributesFunc whose attributes, such as a
+// tenant ID taken from a header, are added to every record logged by the
+// Server's handlers and middleware while serving a request. For handlers
+// created by [NewHandler] or [NewFormHandler], the attributes are taken again
+// after a guard replaces the request, so that values added by the guard, such
+// as the authenticated user, can be included.
+func WithServerLogAttributes(fn LogAttributesFunc) ServerOption {
+	return func(so *serverOptions) {
+		so.logAttributes = fn
+	}
+}
+
 // WithServerMaxBodySize sets the maximum allowed size for the request body.
 // This limit helps prevent excessive memory usage or abuse from clients
 // sending extremely large payloads.
@@ -299,6 +650,46 @@ func WithServerMaxBodySize(size int64) ServerOption {
 	}
 }
 
+// WithServerPanicMapper adds PanicMappers that the Server uses to translate
+// values recovered from handler panics into problem responses. Mappers are tried
+// in the order they are added until one returns a non-nil problem. Panics that
+// are not recognised by any mapper result in a 500 Internal Server Error. Nil
+// mappers are skipped.
+func WithServerPanicMapper(mappers ...PanicMapper) ServerOption {
+	return func(so *serverOptions) {
+		for _, mapper := range mappers {
+			if mapper != nil {
+				so.panicMappers = append(so.panicMappers, mapper)
+			}
+		}
+	}
+}
+
+// WithServerProblemInstance sets the ProblemInstanceFunc used to compute the
+// instance member of problem responses written by the Server, its handlers and
+// middleware. Only problems whose instance is the request path, as set by the
+// constructors in the problem package, are changed. By default, the instance is
+// left as the request path.
+func WithServerProblemInstance(fn ProblemInstanceFunc) ServerOption {
+	return func(so *serverOptions) {
+		so.problemInstance = fn
+	}
+}
+
+// WithServerProblemInstanceFullURL sets the instance member of problem
+// responses to the absolute request URL, including the scheme, host, path and
+// query. See [WithServerProblemInstance] for the problems that are changed.
+//
+// When the immediate peer is one of trustedProxies, the scheme and host are
+// taken from the Forwarded header, or the X-Forwarded-Proto and
+// X-Forwarded-Host headers, when present. Otherwise, they are taken from the
+// request itself, as the headers are controlled by the client.
+func WithServerProblemInstanceFullURL(trustedProxies ...netip.Prefix) ServerOption {
+	return WithServerProblemInstance(func(r *http.Request) string {
+		return requestURL(r, trustedProxies)
+	})
+}
+
 // WithServerReadHeaderTimeout sets the timeout for reading the request header. This
 // is the maximum amount of time the server will wait to receive the request
 // headers.
@@ -317,6 +708,50 @@ func WithServerReadTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithServerRequestDeadlineHeader makes the Server bound the context of each
+// request by the timeout sent by the client in the header named name, such as
+// X-Request-Timeout or grpc-timeout, so that downstream work stops once the
+// client has stopped waiting. The grpc-timeout header is parsed in the gRPC
+// format, for example "100m" for 100 milliseconds. Other headers accept a
+// number of seconds or a Go duration string, for example "30" or "1.5s".
+// Requests without the header, or with an invalid value, are not given a
+// deadline.
+func WithServerRequestDeadlineHeader(name string) ServerOption {
+	return func(so *serverOptions) {
+		so.requestDeadline = name
+	}
+}
+
+// WithServerResponseValidation enables or disables validation of successful
+// response data against its contract before it is encoded. Responses are
+// validated against the schema set with [WithHandlerResponseSchema], or the
+// validate struct tags of the response data when no schema is set. A response
+// that violates its contract is logged and replaced with a server error. Use
+// [WithServerResponseValidationLogOnly] to write the response regardless.
+//
+// Response validation is disabled by default as it adds overhead to every
+// response. It is intended for development and CI environments to catch
+// contract drift.
+func WithServerResponseValidation(enabled bool) ServerOption {
+	return func(so *serverOptions) {
+		so.responseValidation = responseValidationOff
+		if enabled {
+			so.responseValidation = responseValidationFail
+		}
+	}
+}
+
+// WithServerResponseValidationLogOnly enables validation of successful
+// response data as with [WithServerResponseValidation], but a response that
+// violates its contract is only logged and is written unchanged. This allows
+// contract drift to be observed in environments where failing requests is not
+// acceptable. Whichever of the two options is applied last takes effect.
+func WithServerResponseValidationLogOnly() ServerOption {
+	return func(so *serverOptions) {
+		so.responseValidation = responseValidationLog
+	}
+}
+
 // WithServerShutdownTimeout sets the timeout for gracefully shutting down the server.
 // This is the amount of time the server will wait for existing connections to
 // complete before shutting down.
@@ -326,6 +761,31 @@ func WithServerShutdownTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithServerTLSConfig makes the Server serve HTTPS using config, which must
+// hold the certificates of the Server, for example in Certificates or
+// GetCertificate. To authenticate clients with mutual TLS, set ClientAuth to
+// tls.RequireAndVerifyClientCert, or tls.VerifyClientCertIfGiven, and ClientCAs
+// to the pool of trusted certificate authorities; see [NewClientCertGuard].
+func WithServerTLSConfig(config *tls.Config) ServerOption {
+	return func(so *serverOptions) {
+		so.tlsConfig = config
+	}
+}
+
+// WithServerVerifyDigest enables verification of request bodies against the
+// Content-MD5 header and the sha-256 value of the Digest header, when the
+// client sends either. The body is buffered, within the limit set by
+// [WithServerMaxBodySize], before it reaches the handler, and a body that does
+// not match its digest is rejected with a [problem.BadRequest] error.
+//
+// Digest verification is disabled by default as buffering adds latency and
+// memory use to requests that send a digest.
+func WithServerVerifyDigest() ServerOption {
+	return func(so *serverOptions) {
+		so.verifyDigest = true
+	}
+}
+
 // WithServerWriteTimeout sets the timeout for writing the response. This is the
 // maximum amount of time the server will wait to send a response.
 func WithServerWriteTimeout(timeout time.Duration) ServerOption {
@@ -367,14 +827,25 @@ func mapServerOptionsToDefaults(opts []ServerOption) serverOptions {
 	)
 
 	defaultOpts := serverOptions{
-		address:           ":8080",
-		codec:             NewJSONServerCodec(),
-		idleTimeout:       defaultIdleTimeout,
-		maxBodySize:       defaultMaxBodySize,
-		readHeaderTimeout: defaultReadHeaderTimeout,
-		readTimeout:       defaultReadTimeout,
-		shutdownTimeout:   defaultShutdownTimeout,
-		writeTimeout:      defaultWriteTimeout,
+		address:            ":8080",
+		codec:              NewJSONServerCodec(),
+		debugErrors:        false,
+		errorMappers:       nil,
+		extensionCodecs:    nil,
+		h2c:                false,
+		idleTimeout:        defaultIdleTimeout,
+		logAttributes:      nil,
+		maxBodySize:        defaultMaxBodySize,
+		panicMappers:       nil,
+		problemInstance:    nil,
+		readHeaderTimeout:  defaultReadHeaderTimeout,
+		readTimeout:        defaultReadTimeout,
+		requestDeadline:    "",
+		responseValidation: responseValidationOff,
+		shutdownTimeout:    defaultShutdownTimeout,
+		tlsConfig:          nil,
+		verifyDigest:       false,
+		writeTimeout:       defaultWriteTimeout,
 	}
 
 	for _, opt := range opts {
@@ -1,20 +1,26 @@
 package httputil_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
 	"errors"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/nickbryan/slogutil"
-	"github.com/nickbryan/slogutil/slogmem"
 
 	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
 )
 
 /*
@@ -49,8 +55,50 @@ func TestClientOptionsDefaults(t *testing.T) {
 		t.Error("expected cookie jar to be nil")
 	}
 
-	if httpClient.Transport != http.DefaultTransport {
-		t.Errorf("expected transport to be http.DefaultTransport, got: %T", httpClient.Transport)
+	if _, ok := httpClient.Transport.(*http.Transport); !ok || httpClient.Transport == http.DefaultTransport {
+		t.Errorf("expected transport to be a clone of http.DefaultTransport, got: %T", httpClient.Transport)
+	}
+}
+
+func TestNewClient_TransportIsolation(t *testing.T) {
+	t.Parallel()
+
+	plain := httputil.NewClient()
+	uncompressed := httputil.NewClient(httputil.WithClientDisableCompression())
+
+	plainTransport, ok := plain.Client().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected transport to be *http.Transport, got: %T", plain.Client().Transport)
+	}
+
+	uncompressedTransport, ok := uncompressed.Client().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected transport to be *http.Transport, got: %T", uncompressed.Client().Transport)
+	}
+
+	if plainTransport == uncompressedTransport {
+		t.Error("expected each client to have its own transport")
+	}
+
+	if plainTransport.DisableCompression {
+		t.Error("expected compression to be enabled on the default client transport")
+	}
+
+	if !uncompressedTransport.DisableCompression {
+		t.Error("expected compression to be disabled on the configured client transport")
+	}
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected http.DefaultTransport to be *http.Transport, got: %T", http.DefaultTransport)
+	}
+
+	if defaultTransport == plainTransport || defaultTransport == uncompressedTransport {
+		t.Error("expected clients not to share http.DefaultTransport")
+	}
+
+	if defaultTransport.DisableCompression {
+		t.Error("expected http.DefaultTransport to still have compression enabled")
 	}
 }
 
@@ -155,6 +203,304 @@ func TestWithClientTransport(t *testing.T) {
 	}
 }
 
+func TestWithClientDisableCompression(t *testing.T) {
+	t.Parallel()
+
+	var compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write([]byte(`{"name":"test"}`)); err != nil {
+		t.Fatalf("unexpected error compressing body: %v", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatalf("unexpected error closing gzip writer: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := map[string]struct {
+		options             []httputil.ClientOption
+		wantBody            []byte
+		wantContentEncoding string
+	}{
+		"responses are decompressed by default": {
+			options:             nil,
+			wantBody:            []byte(`{"name":"test"}`),
+			wantContentEncoding: "",
+		},
+		"the raw compressed body is returned when compression is disabled": {
+			options:             []httputil.ClientOption{httputil.WithClientDisableCompression()},
+			wantBody:            compressed.Bytes(),
+			wantContentEncoding: "gzip",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			client := httputil.NewClient(append(testCase.options, httputil.WithClientBasePath(server.URL))...)
+
+			resp, err := client.Get(t.Context(), "/")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			t.Cleanup(func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Errorf("closing response body: %s", err)
+				}
+			})
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("unexpected error reading body: %v", err)
+			}
+
+			if !bytes.Equal(body, testCase.wantBody) {
+				t.Errorf("body = %q, want: %q", body, testCase.wantBody)
+			}
+
+			if got := resp.Header.Get("Content-Encoding"); got != testCase.wantContentEncoding {
+				t.Errorf("Content-Encoding = %q, want: %q", got, testCase.wantContentEncoding)
+			}
+		})
+	}
+
+}
+
+func TestWithClientSafeRedirects(t *testing.T) {
+	t.Parallel()
+
+	type received struct {
+		authorization string
+		cookie        string
+		body          string
+	}
+
+	testCases := map[string]struct {
+		status      int
+		crossOrigin bool
+		want        received
+	}{
+		"a same origin 307 redirect keeps credentials and replays the body": {
+			status:      http.StatusTemporaryRedirect,
+			crossOrigin: false,
+			want:        received{authorization: "Bearer token", cookie: "session=abc", body: `{"name":"test"}`},
+		},
+		"a same origin 308 redirect keeps credentials and replays the body": {
+			status:      http.StatusPermanentRedirect,
+			crossOrigin: false,
+			want:        received{authorization: "Bearer token", cookie: "session=abc", body: `{"name":"test"}`},
+		},
+		"a cross origin 307 redirect strips credentials and replays the body": {
+			status:      http.StatusTemporaryRedirect,
+			crossOrigin: true,
+			want:        received{authorization: "", cookie: "", body: `{"name":"test"}`},
+		},
+		"a cross origin 308 redirect strips credentials and replays the body": {
+			status:      http.StatusPermanentRedirect,
+			crossOrigin: true,
+			want:        received{authorization: "", cookie: "", body: `{"name":"test"}`},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			receivedCh := make(chan received, 1)
+			record := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				receivedCh <- received{authorization: r.Header.Get("Authorization"), cookie: r.Header.Get("Cookie"), body: string(body)}
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			other := httptest.NewServer(record)
+			t.Cleanup(other.Close)
+
+			mux := http.NewServeMux()
+			mux.Handle("/final", record)
+
+			origin := httptest.NewServer(mux)
+			t.Cleanup(origin.Close)
+
+			// Both servers listen on the same host but a different port, which the
+			// default policy of http.Client treats as the same host.
+			target := origin.URL + "/final"
+			if testCase.crossOrigin {
+				target = other.URL + "/final"
+			}
+
+			mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, target, testCase.status)
+			})
+
+			client := httputil.NewClient(httputil.WithClientBasePath(origin.URL), httputil.WithClientSafeRedirects())
+
+			resp, err := client.Post(t.Context(), "/start", map[string]string{"name": "test"}, httputil.WithRequestHeaders(map[string]string{
+				"Authorization": "Bearer token",
+				"Cookie":        "session=abc",
+			}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			t.Cleanup(func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Errorf("closing response body: %s", err)
+				}
+			})
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("resp.StatusCode = %d, want: %d", resp.StatusCode, http.StatusOK)
+			}
+
+			if got := <-receivedCh; got != testCase.want {
+				t.Errorf("received = %+v, want: %+v", got, testCase.want)
+			}
+		})
+	}
+
+	t.Run("stops after 10 redirects", func(t *testing.T) {
+		t.Parallel()
+
+		var server *httptest.Server
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, server.URL, http.StatusFound)
+		}))
+		t.Cleanup(server.Close)
+
+		client := httputil.NewClient(httputil.WithClientBasePath(server.URL), httputil.WithClientSafeRedirects())
+
+		resp, err := client.Get(t.Context(), "/")
+		if err == nil {
+			_ = resp.Body.Close()
+
+			t.Fatal("expected an error, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "stopped after 10 redirects") {
+			t.Errorf("err = %q, want it to contain: %q", err, "stopped after 10 redirects")
+		}
+	})
+}
+
+func TestWithClientTransportConfig(t *testing.T) {
+	t.Parallel()
+
+	config := httputil.TransportConfig{
+		MaxIdleConns:        200,
+		MaxIdleConnsPerHost: 50,
+		MaxConnsPerHost:     100,
+		IdleConnTimeout:     30 * time.Second,
+	}
+
+	assertConfig := func(t *testing.T, transport *http.Transport, want httputil.TransportConfig) {
+		t.Helper()
+
+		got := httputil.TransportConfig{
+			MaxIdleConns:        transport.MaxIdleConns,
+			MaxIdleConnsPerHost: transport.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     transport.MaxConnsPerHost,
+			IdleConnTimeout:     transport.IdleConnTimeout,
+		}
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("transport config mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	t.Run("applies the config to the default transport", func(t *testing.T) {
+		t.Parallel()
+
+		client := httputil.NewClient(httputil.WithClientTransportConfig(config))
+
+		transport, ok := client.Client().Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected transport to be *http.Transport, got: %T", client.Client().Transport)
+		}
+
+		assertConfig(t, transport, config)
+	})
+
+	t.Run("applies the config to the root transport when interceptors are added", func(t *testing.T) {
+		t.Parallel()
+
+		var root http.RoundTripper
+
+		httputil.NewClient(
+			httputil.WithClientTransportConfig(config),
+			httputil.WithClientInterceptor(func(next http.RoundTripper) http.RoundTripper {
+				root = next
+				return next
+			}),
+		)
+
+		transport, ok := root.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected root transport to be *http.Transport, got: %T", root)
+		}
+
+		assertConfig(t, transport, config)
+	})
+
+	t.Run("zero fields leave the transport value unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		client := httputil.NewClient(httputil.WithClientTransportConfig(httputil.TransportConfig{
+			MaxIdleConns:        0,
+			MaxIdleConnsPerHost: 0,
+			MaxConnsPerHost:     10,
+			IdleConnTimeout:     0,
+		}))
+
+		transport, ok := client.Client().Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected transport to be *http.Transport, got: %T", client.Client().Transport)
+		}
+
+		defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected http.DefaultTransport to be *http.Transport, got: %T", http.DefaultTransport)
+		}
+
+		assertConfig(t, transport, httputil.TransportConfig{
+			MaxIdleConns:        defaultTransport.MaxIdleConns,
+			MaxIdleConnsPerHost: defaultTransport.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     10,
+			IdleConnTimeout:     defaultTransport.IdleConnTimeout,
+		})
+	})
+
+	t.Run("a custom transport is cloned rather than modified", func(t *testing.T) {
+		t.Parallel()
+
+		custom := &http.Transport{} //nolint:exhaustruct // Zero value transport is sufficient for the test.
+
+		client := httputil.NewClient(httputil.WithClientTransport(custom), httputil.WithClientTransportConfig(config))
+
+		transport, ok := client.Client().Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected transport to be *http.Transport, got: %T", client.Client().Transport)
+		}
+
+		if transport == custom {
+			t.Error("expected the custom transport to be cloned")
+		}
+
+		assertConfig(t, transport, config)
+		assertConfig(t, custom, httputil.TransportConfig{MaxIdleConns: 0, MaxIdleConnsPerHost: 0, MaxConnsPerHost: 0, IdleConnTimeout: 0})
+	})
+}
+
 func TestWithClientInterceptor(t *testing.T) {
 	t.Parallel()
 
@@ -408,6 +754,8 @@ func TestServerOptionsDefaults(t *testing.T) {
 func TestServerOptions(t *testing.T) {
 	t.Parallel()
 
+	tlsConfig := &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS13}
+
 	logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
 	server := httputil.NewServer(logger,
 		httputil.WithServerAddress("someaddr:8765"),
@@ -416,6 +764,7 @@ func TestServerOptions(t *testing.T) {
 		httputil.WithServerReadTimeout(time.Duration(3)),
 		httputil.WithServerWriteTimeout(time.Duration(4)),
 		httputil.WithServerCodec(serverTestCodec{}),
+		httputil.WithServerTLSConfig(tlsConfig),
 	)
 
 	netHTTPServer, ok := server.Listener.(*http.Server)
@@ -443,6 +792,10 @@ func TestServerOptions(t *testing.T) {
 		t.Errorf("default write timeout not set, got: %s, want: %s", got, want)
 	}
 
+	if netHTTPServer.TLSConfig != tlsConfig {
+		t.Errorf("tls config not set, got: %p, want: %p", netHTTPServer.TLSConfig, tlsConfig)
+	}
+
 	server.Register(httputil.Endpoint{
 		Method: http.MethodGet,
 		Path:   "/",
@@ -462,6 +815,102 @@ func TestServerOptions(t *testing.T) {
 	}
 }
 
+func TestH2C(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		serverOptions []httputil.ServerOption
+		clientOptions []httputil.ClientOption
+		wantProto     string
+		wantErr       bool
+	}{
+		"an h2c client and server communicate over HTTP/2": {
+			serverOptions: []httputil.ServerOption{httputil.WithServerH2C()},
+			clientOptions: []httputil.ClientOption{httputil.WithClientH2C()},
+			wantProto:     "HTTP/2.0",
+			wantErr:       false,
+		},
+		"an h2c server still accepts HTTP/1.1": {
+			serverOptions: []httputil.ServerOption{httputil.WithServerH2C()},
+			clientOptions: nil,
+			wantProto:     "HTTP/1.1",
+			wantErr:       false,
+		},
+		"an h2c client fails against a server without h2c": {
+			serverOptions: nil,
+			clientOptions: []httputil.ClientOption{httputil.WithClientH2C()},
+			wantProto:     "",
+			wantErr:       true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger, testCase.serverOptions...)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/proto",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = io.WriteString(w, r.Proto)
+				}),
+			})
+
+			netHTTPServer, ok := server.Listener.(*http.Server)
+			if !ok {
+				t.Fatal("listener is not a http.Server")
+			}
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("unexpected error listening: %v", err)
+			}
+
+			go func() { _ = netHTTPServer.Serve(listener) }()
+
+			t.Cleanup(func() { _ = netHTTPServer.Close() })
+
+			client := httputil.NewClient(append(testCase.clientOptions, httputil.WithClientBasePath("http://"+listener.Addr().String()))...)
+
+			resp, err := client.Get(t.Context(), "/proto")
+			if testCase.wantErr {
+				if err == nil {
+					_ = resp.Body.Close()
+
+					t.Fatal("expected an error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			t.Cleanup(func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Errorf("closing response body: %s", err)
+				}
+			})
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("unexpected error reading body: %v", err)
+			}
+
+			if got := string(body); got != testCase.wantProto {
+				t.Errorf("server request proto = %q, want: %q", got, testCase.wantProto)
+			}
+
+			if resp.Proto != testCase.wantProto {
+				t.Errorf("resp.Proto = %q, want: %q", resp.Proto, testCase.wantProto)
+			}
+		})
+	}
+}
+
 func TestHandlerOptions(t *testing.T) {
 	t.Parallel()
 
@@ -533,17 +982,9 @@ func TestHandlerOptions(t *testing.T) {
 			t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, res.Code)
 		}
 
-		query := slogmem.RecordQuery{
-			Message: "Handler received an unhandled error",
-			Level:   slog.LevelError,
-			Attrs: map[string]slog.Value{
-				"error": slog.AnyValue("calling action: unhandled action error"),
-			},
-		}
-
-		if ok, diff := logs.Contains(query); !ok {
-			t.Errorf("expected log record not found, diff (-want +got):\n%s", diff)
-		}
+		testutil.AssertLog(t, logs, slog.LevelError, "Handler received an unhandled error", map[string]slog.Value{
+			"error": slog.AnyValue("calling action: unhandled action error"),
+		})
 	})
 }
 
@@ -9,6 +9,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -19,6 +21,8 @@ import (
 const (
 	// tagParam is the struct tag for parameter binding.
 	tagParam = "param"
+	// tagTransform is the struct tag for normalizing string parameters.
+	tagTransform = "transform"
 	// sourceDefault identifies that the value came from the default declaration.
 	sourceDefault = "default"
 	// sourceQuery identifies that the value came from the URL query.
@@ -27,10 +31,16 @@ const (
 	sourceHeader = "header"
 	// sourcePath identifies that the value came from the URL path.
 	sourcePath = "path"
+	// sourceForm identifies that the value came from the form encoded request body.
+	sourceForm = "form"
 	// tagPartSize is the expected number of parts when splitting a tag part by "=".
 	tagPartSize = 2
 )
 
+// errUnknownTransform is returned when a transform tag names a transform that
+// does not exist.
+var errUnknownTransform = errors.New("unknown transform")
+
 // InvalidOutputTypeError is a custom error type for invalid output types.
 type InvalidOutputTypeError struct {
 	ProvidedType any
@@ -77,12 +87,14 @@ type paramInfo struct {
 }
 
 // resolvedParam represents the result of resolving a parameter value from a
-// request or tag.
+// request or tag. For query parameters, values holds every value of the
+// repeated parameter, the first of which is value.
 type resolvedParam struct {
 	canonicalName string
 	actualKey     string
 	sourceType    string
 	value         string
+	values        []string
 }
 
 // reportingKey returns the key that should be used when reporting errors for the parameter.
@@ -119,8 +131,10 @@ type tagPart struct {
 // and their meanings are:
 //
 //   - `param`: Specifies sources and options in "key=value" format, separated by commas.
-//     Keys: query, header, path, default.
+//     Keys: query, header, path, form, default.
 //     Order matters: first match wins.
+//   - `transform`: Normalizes string values before validation, separated by
+//     commas and applied in order. Transforms: trim, lower, upper, title.
 //   - `validate`: Provides rules for the validator.
 //
 // Example:
@@ -145,11 +159,35 @@ type tagPart struct {
 // - bool
 // - float64
 // - uuid.UUID
+// - time.Time, parsed as RFC 3339
+// - map[string]string, bound from query keys in bracket notation
+// - slices of the types above, except map[string]string
+//
+// The fields of embedded structs without a param tag, such as [TimeRange], are
+// bound as if they were fields of output.
 //
 // Returns problem.BadParameters if:
 // - A value cannot be converted to the target field type.
 // - Validation fails.
 //
+// A map[string]string field is bound from query keys in bracket notation, e.g.
+// "filter[status]=open&filter[type]=bug" is bound by `param:"query=filter"` as
+// {"status": "open", "type": "bug"}. Only query sources are read for map fields.
+// Validation errors for map entries are reported against the bracketed key.
+//
+// A slice field is bound from every value of a repeated query parameter, e.g.
+// "tag=a&tag=b", or from a single comma separated value, e.g. "ids=1,2,3". Each
+// element is converted to the element type of the slice, and a conversion error
+// is reported against the parameter. A default for a slice field may list
+// several comma separated values, e.g. `param:"query=tag,default=a,b"`, which
+// are used for the whole slice when the parameter has no values. Validation
+// errors for slice elements are reported against the parameter with the index
+// in brackets, e.g. "ids[1]".
+//
+// The form source reads values from a form encoded request body with
+// [http.Request.PostFormValue], which parses the body on first use. Query
+// parameters are not read by the form source; use the query source for them.
+//
 // Validation is skipped for parameters that were populated from a `default`
 // source. This allows developers to set default values that might strictly violate
 // validation rules (e.g. zero values for required fields) without causing client-facing errors.
@@ -164,48 +202,92 @@ func BindValidParameters(r *http.Request, output any) error {
 		return fmt.Errorf("validating output type: %w", err)
 	}
 
-	var paramErrors []problem.Parameter
-
-	paramTypes := make(map[string]paramInfo)
-
-	var fieldsToSkip []string
-
 	var query url.Values
 	if r.URL != nil {
 		query = r.URL.Query()
 	}
 
-	for i := range outputVal.NumField() {
-		field := outputVal.Type().Field(i)
+	binding := paramBinding{errors: nil, types: make(map[string]paramInfo), skip: nil}
+	if err = binding.bindFields(r, query, outputVal, ""); err != nil {
+		return err
+	}
+
+	paramErrors, err := validateStruct(r.Context(), output, binding.types, binding.errors, binding.skip)
+	if err != nil {
+		return err
+	}
+
+	if len(paramErrors) > 0 {
+		return problem.BadParameters(r, paramErrors...)
+	}
+
+	return nil
+}
+
+// paramBinding collects the state of binding the parameters of a struct.
+type paramBinding struct {
+	// errors holds the problems with the values of the parameters.
+	errors []problem.Parameter
+	// types holds the key and source of each parameter by field name.
+	types map[string]paramInfo
+	// skip holds the namespaces of the fields that were set from a default and
+	// are not validated.
+	skip []string
+}
+
+// bindFields binds the parameters of the fields of structVal, recursing into
+// embedded structs without a param tag, such as [TimeRange]. The fields of an
+// embedded struct are skipped from validation by their namespace, which is
+// prefixed to their name. Structs that implement paramDefaulter have their
+// defaults applied once their fields are bound.
+func (b *paramBinding) bindFields(r *http.Request, query url.Values, structVal reflect.Value, namespace string) error {
+	for i := range structVal.NumField() {
+		field := structVal.Type().Field(i)
 		if !field.IsExported() {
 			continue
 		}
 
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(tagParam) == "" {
+			if err := b.bindFields(r, query, structVal.Field(i), namespace+field.Name+"."); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if field.Type == reflect.TypeFor[map[string]string]() {
+			key, values := resolveBracketedParam(query, field)
+			b.types[field.Name] = paramInfo{actualKey: key, sourceType: sourceQuery}
+
+			if err := setMapField(structVal.Field(i), values, field.Tag.Get(tagTransform)); err != nil {
+				return fmt.Errorf("setting field value: %w", err)
+			}
+
+			continue
+		}
+
 		res := resolveParamValue(r, query, field)
-		paramTypes[field.Name] = paramInfo{
+		b.types[field.Name] = paramInfo{
 			actualKey:  res.reportingKey(field.Name),
 			sourceType: res.sourceType,
 		}
 
 		if res.actualKey == sourceDefault {
-			fieldsToSkip = append(fieldsToSkip, field.Name)
+			b.skip = append(b.skip, namespace+field.Name)
 		}
 
 		if res.value != "" {
-			paramErrors, err = setFieldAndHandleError(outputVal.Field(i), res, paramErrors)
+			var err error
+
+			b.errors, err = setFieldAndHandleError(structVal.Field(i), res, field.Tag.Get(tagTransform), b.errors)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	paramErrors, err = validateStruct(r.Context(), output, paramTypes, paramErrors, fieldsToSkip)
-	if err != nil {
-		return err
-	}
-
-	if len(paramErrors) > 0 {
-		return problem.BadParameters(r, paramErrors...)
+	if defaulter, ok := structVal.Addr().Interface().(paramDefaulter); ok {
+		defaulter.setParamDefaults()
 	}
 
 	return nil
@@ -213,12 +295,14 @@ func BindValidParameters(r *http.Request, output any) error {
 
 // setFieldAndHandleError attempts to set a struct field's value and handles any
 // conversion errors that occur by appending them to the provided error slice.
+// String values are normalized by the transforms in transform first.
 func setFieldAndHandleError(
 	fieldVal reflect.Value,
 	res resolvedParam,
+	transform string,
 	paramErrors []problem.Parameter,
 ) ([]problem.Parameter, error) {
-	if err := setFieldValue(fieldVal, res.actualKey, res.value, res.sourceType); err != nil {
+	if err := setFieldValue(fieldVal, res, transform); err != nil {
 		if paramConversionError, ok := errors.AsType[*ParamConversionError](err); res.actualKey != sourceDefault && ok {
 			paramErrors = append(paramErrors, problem.Parameter{
 				Parameter: paramConversionError.ParamName,
@@ -262,10 +346,18 @@ func processValidationErrors(errs validator.ValidationErrors, paramTypes map[str
 	validationErrors := make([]problem.Parameter, 0, len(errs))
 
 	for _, err := range errs {
-		info := paramTypes[err.StructField()]
+		// Errors for the entries of a map field are reported against the field
+		// with the map key in brackets, e.g. "Filter[status]".
+		fieldName, index, hasIndex := strings.Cut(err.StructField(), "[")
+		info := paramTypes[fieldName]
+
+		parameter := info.actualKey
+		if hasIndex {
+			parameter += "[" + index
+		}
 
 		validationErrors = append(validationErrors, problem.Parameter{
-			Parameter: info.actualKey,
+			Parameter: parameter,
 			Detail:    describeValidationError(err),
 			Type:      problem.ParameterType(info.sourceType),
 		})
@@ -286,7 +378,7 @@ func validateOutputType(output any) (reflect.Value, error) {
 }
 
 // resolveParamValue extracts a named parameter's value from an HTTP request
-// using struct field tags (query, header, path, default).
+// using struct field tags (query, header, path, form, default).
 func resolveParamValue(r *http.Request, query url.Values, field reflect.StructField) resolvedParam {
 	tag := parseParamTag(field.Tag.Get(tagParam))
 	if tag == nil {
@@ -295,6 +387,7 @@ func resolveParamValue(r *http.Request, query url.Values, field reflect.StructFi
 			actualKey:     "",
 			sourceType:    "",
 			value:         "",
+			values:        nil,
 		}
 	}
 
@@ -305,15 +398,22 @@ func resolveParamValue(r *http.Request, query url.Values, field reflect.StructFi
 				actualKey:     sourceDefault,
 				sourceType:    tag.firstSource,
 				value:         part.key,
+				values:        nil,
 			}
 		}
 
 		if value := getSourceValue(r, query, part.source, part.key); value != "" {
+			var values []string
+			if part.source == sourceQuery {
+				values = query[part.key]
+			}
+
 			return resolvedParam{
 				canonicalName: tag.canonicalName,
 				actualKey:     part.key,
 				sourceType:    part.source,
 				value:         value,
+				values:        values,
 			}
 		}
 	}
@@ -323,7 +423,57 @@ func resolveParamValue(r *http.Request, query url.Values, field reflect.StructFi
 		actualKey:     tag.canonicalName,
 		sourceType:    tag.firstSource,
 		value:         "",
+		values:        nil,
+	}
+}
+
+// resolveBracketedParam extracts the values of query keys in bracket notation,
+// e.g. "filter[status]=open", for a map field. The query sources of the field's
+// param tag are checked in order and the first key with any bracketed values
+// wins. It returns the key used and the values keyed by the bracketed name.
+func resolveBracketedParam(query url.Values, field reflect.StructField) (string, map[string]string) {
+	tag := parseParamTag(field.Tag.Get(tagParam))
+	if tag == nil {
+		return "", nil
+	}
+
+	for _, part := range tag.parts {
+		if part.source != sourceQuery {
+			continue
+		}
+
+		if values := bracketedQueryValues(query, part.key); len(values) > 0 {
+			return part.key, values
+		}
+	}
+
+	return tag.canonicalName, nil
+}
+
+// bracketedQueryValues returns the first value of each query key of the form
+// key[name], keyed by name. Keys with an empty or nested name are ignored.
+func bracketedQueryValues(query url.Values, key string) map[string]string {
+	var values map[string]string
+
+	for queryKey, queryValues := range query {
+		name, ok := strings.CutPrefix(queryKey, key+"[")
+		if !ok {
+			continue
+		}
+
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || name == "" || strings.ContainsAny(name, "[]") {
+			continue
+		}
+
+		if values == nil {
+			values = make(map[string]string)
+		}
+
+		values[name] = queryValues[0]
 	}
+
+	return values
 }
 
 // parseParamTag parses a 'param' struct tag into a paramTag struct.
@@ -341,6 +491,12 @@ func parseParamTag(tagStr string) *paramTag {
 	for part := range strings.SplitSeq(tagStr, ",") {
 		kv := strings.SplitN(strings.TrimSpace(part), "=", tagPartSize)
 		if len(kv) != tagPartSize {
+			// A part without a source continues the comma separated values of
+			// a default, e.g. "default=a,b" for a slice field.
+			if last := len(res.parts) - 1; last >= 0 && res.parts[last].source == sourceDefault {
+				res.parts[last].key += "," + strings.TrimSpace(part)
+			}
+
 			continue
 		}
 
@@ -369,21 +525,84 @@ func getSourceValue(r *http.Request, query url.Values, source, key string) strin
 		return r.Header.Get(key)
 	case sourcePath:
 		return r.PathValue(key)
+	case sourceForm:
+		return r.PostFormValue(key)
 	default:
 		return ""
 	}
 }
 
-// setFieldValue assigns a parameter value to a struct field, converting it to
-// the appropriate type or returning an error.
-func setFieldValue(fieldVal reflect.Value, paramName, paramValue, paramType string) error {
+// setFieldValue assigns the value of res to a struct field, converting it to
+// the appropriate type or returning an error. String values are normalized by
+// the transforms in transform.
+func setFieldValue(fieldVal reflect.Value, res resolvedParam, transform string) error {
+	if fieldVal.Kind() == reflect.Slice {
+		return setSliceField(fieldVal, res, transform)
+	}
+
+	return setScalarField(fieldVal, res.actualKey, res.value, res.sourceType, transform)
+}
+
+// setSliceField assigns the values of res to a slice field, converting each
+// to the element type of the slice. The repeated values of a query parameter
+// are used as they are, while a single value is split on commas and the
+// surrounding whitespace of each element is trimmed. Empty elements are
+// skipped. Returns an
+// [*UnsupportedFieldTypeError] if the element type is not supported.
+func setSliceField(fieldVal reflect.Value, res resolvedParam, transform string) error {
+	elemType := fieldVal.Type().Elem()
+	if elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Map {
+		return &UnsupportedFieldTypeError{FieldType: fieldVal.Interface()}
+	}
+
+	values := res.values
+	if len(values) <= 1 {
+		values = nil
+
+		for value := range strings.SplitSeq(res.value, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+
+	slice := reflect.MakeSlice(fieldVal.Type(), 0, len(values))
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if err := setScalarField(elem, res.actualKey, value, res.sourceType, transform); err != nil {
+			if _, ok := errors.AsType[*UnsupportedFieldTypeError](err); ok {
+				return &UnsupportedFieldTypeError{FieldType: fieldVal.Interface()}
+			}
+
+			return err
+		}
+
+		slice = reflect.Append(slice, elem)
+	}
+
+	fieldVal.Set(slice)
+
+	return nil
+}
+
+// setScalarField assigns a parameter value to a field that is not a slice,
+// converting it to the appropriate type or returning an error. String values
+// are normalized by the transforms in transform.
+func setScalarField(fieldVal reflect.Value, paramName, paramValue, paramType, transform string) error {
 	if _, ok := fieldVal.Interface().(uuid.UUID); ok {
 		return setUUIDField(fieldVal, paramName, paramValue, paramType)
 	}
 
+	if _, ok := fieldVal.Interface().(time.Time); ok {
+		return setTimeField(fieldVal, paramName, paramValue, paramType)
+	}
+
 	switch fieldVal.Kind() {
 	case reflect.String:
-		return setStringField(fieldVal, paramValue)
+		return setStringField(fieldVal, paramValue, transform)
 	case reflect.Int:
 		return setIntField(fieldVal, paramName, paramValue, paramType)
 	case reflect.Bool:
@@ -395,13 +614,90 @@ func setFieldValue(fieldVal reflect.Value, paramName, paramValue, paramType stri
 	}
 }
 
-// setStringField assigns a string value to a reflect.Value field. Returns an
-// error if the operation fails.
-func setStringField(fieldVal reflect.Value, paramValue string) error {
+// setStringField assigns a string value to a reflect.Value field after
+// applying the comma separated transforms in transform to it. Returns an error
+// if a transform is unknown.
+func setStringField(fieldVal reflect.Value, paramValue, transform string) error {
+	paramValue, err := applyTransforms(paramValue, transform)
+	if err != nil {
+		return err
+	}
+
 	fieldVal.SetString(paramValue)
+
+	return nil
+}
+
+// setMapField assigns values to a map[string]string field after applying the
+// comma separated transforms in transform to each value. The field is left
+// untouched if values is empty. Returns an error if a transform is unknown.
+func setMapField(fieldVal reflect.Value, values map[string]string, transform string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	for name, value := range values {
+		transformed, err := applyTransforms(value, transform)
+		if err != nil {
+			return err
+		}
+
+		values[name] = transformed
+	}
+
+	fieldVal.Set(reflect.ValueOf(values))
+
 	return nil
 }
 
+// applyTransforms returns value with the comma separated transforms in
+// transform applied to it in order. Returns an error if a transform is unknown.
+func applyTransforms(value, transform string) (string, error) {
+	for name := range strings.SplitSeq(transform, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "trim":
+			value = strings.TrimSpace(value)
+		case "lower":
+			value = strings.ToLower(value)
+		case "upper":
+			value = strings.ToUpper(value)
+		case "title":
+			value = toTitle(value)
+		default:
+			return "", fmt.Errorf("%w: %q", errUnknownTransform, name)
+		}
+	}
+
+	return value, nil
+}
+
+// toTitle returns s with the first letter of each word in upper case and the
+// remaining letters in lower case. Words are separated by whitespace.
+func toTitle(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	startOfWord := true
+
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			startOfWord = true
+		case startOfWord:
+			r = unicode.ToUpper(r)
+			startOfWord = false
+		default:
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
 // setIntField assigns an integer value to a reflect.Value field after
 // converting it from a string. Returns an error if the conversion fails.
 func setIntField(fieldVal reflect.Value, paramName, paramValue, paramType string) error {
@@ -473,3 +769,21 @@ func setUUIDField(fieldVal reflect.Value, paramName, paramValue, paramType strin
 
 	return nil
 }
+
+// setTimeField parses an RFC 3339 time string and sets it to the provided
+// reflect.Value field. Returns an error on parsing failure.
+func setTimeField(fieldVal reflect.Value, paramName, paramValue, paramType string) error {
+	v, err := time.Parse(time.RFC3339, paramValue)
+	if err != nil {
+		return &ParamConversionError{
+			ParameterType: problem.ParameterType(paramType),
+			ParamName:     paramName,
+			TargetType:    "time.Time",
+			Err:           err,
+		}
+	}
+
+	fieldVal.Set(reflect.ValueOf(v))
+
+	return nil
+}
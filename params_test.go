@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -48,6 +49,14 @@ func TestBindValidParameters(t *testing.T) {
 		Val string `validate:"min=5" param:"query=q,header=H"`
 	}
 
+	type numericStruct struct {
+		Page int `validate:"min=1" param:"query=page"`
+	}
+
+	type oneOfStruct struct {
+		Sort string `validate:"oneof=asc desc" param:"query=sort"`
+	}
+
 	type fallbackTypeStruct struct {
 		Val int `param:"query=q,header=H"`
 	}
@@ -74,6 +83,23 @@ func TestBindValidParameters(t *testing.T) {
 		Val string `param:"query=q"`
 	}
 
+	type sliceStruct struct {
+		IDs  []int    `validate:"dive,min=1" param:"query=ids"`
+		Tags []string `param:"query=tag,default=a,b"`
+	}
+
+	type formStruct struct {
+		Name     string `param:"form=name"`
+		Quantity int    `param:"form=quantity,query=quantity,default=1"`
+	}
+
+	newFormRequest := func(body, rawQuery string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/?"+rawQuery, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return r
+	}
+
 	// Struct tags are included in the 'expected' struct literals to ensure they
 	// match the type identity of the 'output' anonymous structs, as Go
 	// considers tags part of the type.
@@ -137,10 +163,22 @@ func TestBindValidParameters(t *testing.T) {
 				},
 			},
 			output: &struct {
-				Unsupported []int `param:"query=unsupported"`
+				Unsupported int64 `param:"query=unsupported"`
 			}{},
 			expectErr:   true,
-			expectedErr: "setting field value: unsupported field type: []int",
+			expectedErr: "setting field value: unsupported field type: int64",
+		},
+		"should fail when attempting to unmarshal into a slice of an unsupported type": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "unsupported=1,2",
+				},
+			},
+			output: &struct {
+				Unsupported []int64 `param:"query=unsupported"`
+			}{},
+			expectErr:   true,
+			expectedErr: "setting field value: unsupported field type: []int64",
 		},
 		"should ignore untagged fields in the struct": {
 			request: &http.Request{
@@ -481,7 +519,7 @@ func TestBindValidParameters(t *testing.T) {
 			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
 			expectedParamErrors: []problem.Parameter{
 				// Should report 'q' (primary) as the parameter, but 'header' (actual source) as the type.
-				{Parameter: "H", Detail: "should be min=5", Type: problem.ParameterTypeHeader},
+				{Parameter: "H", Detail: "must be at least 5 characters", Type: problem.ParameterTypeHeader},
 			},
 		},
 		"should safely ignore malformed param tags": {
@@ -530,6 +568,170 @@ func TestBindValidParameters(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		"should describe numeric validation errors by value": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "page=0",
+				},
+			},
+			output:      &numericStruct{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "page", Detail: "must be at least 1", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should describe oneof validation errors with the allowed values": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "sort=random",
+				},
+			},
+			output:      &oneOfStruct{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "sort", Detail: "must be one of: asc, desc", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should trim and lower string values before validation": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "sort=%20ASC%20",
+				},
+			},
+			output: &struct {
+				Sort string `validate:"oneof=asc desc" param:"query=sort" transform:"trim,lower"`
+			}{},
+			expected: &struct {
+				Sort string `validate:"oneof=asc desc" param:"query=sort" transform:"trim,lower"`
+			}{
+				Sort: "asc",
+			},
+			expectErr: false,
+		},
+		"should fail required validation when a trimmed value is empty": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "name=%20%20",
+				},
+			},
+			output: &struct {
+				Name string `validate:"required" param:"query=name" transform:"trim"`
+			}{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "name", Detail: "is required", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should upper and title case string values": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "code=gb&name=jANE%20o%27neil",
+				},
+			},
+			output: &struct {
+				Code string `param:"query=code" transform:"upper"`
+				Name string `param:"query=name" transform:"title"`
+			}{},
+			expected: &struct {
+				Code string `param:"query=code" transform:"upper"`
+				Name string `param:"query=name" transform:"title"`
+			}{
+				Code: "GB",
+				Name: "Jane O'neil",
+			},
+			expectErr: false,
+		},
+		"should bind bracketed query keys into a map field": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "filter[status]=open&filter[type]=bug&filter=ignored&filter[]=ignored&sort=asc",
+				},
+			},
+			output: &struct {
+				Filter map[string]string `param:"query=filter"`
+			}{},
+			expected: &struct {
+				Filter map[string]string `param:"query=filter"`
+			}{
+				Filter: map[string]string{"status": "open", "type": "bug"},
+			},
+			expectErr: false,
+		},
+		"should bind bracketed query keys from the first query source that has any": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "f[status]=OPEN",
+				},
+			},
+			output: &struct {
+				Filter map[string]string `param:"query=filter,query=f" transform:"lower"`
+			}{},
+			expected: &struct {
+				Filter map[string]string `param:"query=filter,query=f" transform:"lower"`
+			}{
+				Filter: map[string]string{"status": "open"},
+			},
+			expectErr: false,
+		},
+		"should leave a map field nil when no bracketed query keys are present": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "sort=asc",
+				},
+			},
+			output: &struct {
+				Filter map[string]string `param:"query=filter"`
+			}{},
+			expected: &struct {
+				Filter map[string]string `param:"query=filter"`
+			}{
+				Filter: nil,
+			},
+			expectErr: false,
+		},
+		"should report validation errors for map entries against the bracketed key": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "filter[status]=pending",
+				},
+			},
+			output: &struct {
+				Filter map[string]string `validate:"dive,oneof=open closed" param:"query=filter"`
+			}{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "filter[status]", Detail: "must be one of: open, closed", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should report a missing required map field against its query key": {
+			request: &http.Request{
+				URL: &url.URL{},
+			},
+			output: &struct {
+				Filter map[string]string `validate:"required" param:"query=filter"`
+			}{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "filter", Detail: "is required", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should fail when a transform is unknown": {
+			request: &http.Request{
+				URL: &url.URL{
+					RawQuery: "name=jane",
+				},
+			},
+			output: &struct {
+				Name string `param:"query=name" transform:"reverse"`
+			}{},
+			expectErr:   true,
+			expectedErr: `setting field value: unknown transform: "reverse"`,
+		},
 		"should report correct parameter source for type conversion error on fallback": {
 			request: &http.Request{
 				URL: &url.URL{
@@ -561,7 +763,7 @@ func TestBindValidParameters(t *testing.T) {
 			expectedParamErrors: []problem.Parameter{
 				{Parameter: "ival", Detail: "must be a valid int", Type: problem.ParameterTypeQuery},
 				{Parameter: "H", Detail: "must be a valid int", Type: problem.ParameterTypeHeader},
-				{Parameter: "sval", Detail: "should be min=5", Type: problem.ParameterTypeQuery},
+				{Parameter: "sval", Detail: "must be at least 5 characters", Type: problem.ParameterTypeQuery},
 			},
 		}, "should skip validation when default value is used": {
 			request: &http.Request{
@@ -588,6 +790,79 @@ func TestBindValidParameters(t *testing.T) {
 			expected:  &pureDefaultStruct{Val: 123},
 			expectErr: false,
 		},
+		"should extract form values from the request body": {
+			request:   newFormRequest("name=alice&quantity=3", ""),
+			output:    &formStruct{},
+			expected:  &formStruct{Name: "alice", Quantity: 3},
+			expectErr: false,
+		},
+		"should not read query params with the form source": {
+			request:   newFormRequest("", "name=alice"),
+			output:    &formStruct{},
+			expected:  &formStruct{Name: "", Quantity: 1},
+			expectErr: false,
+		},
+		"should fall back from a missing form value to the next source": {
+			request:   newFormRequest("name=alice", "quantity=7"),
+			output:    &formStruct{},
+			expected:  &formStruct{Name: "alice", Quantity: 7},
+			expectErr: false,
+		},
+		"should report form conversion errors with the form type": {
+			request:     newFormRequest("quantity=many", ""),
+			output:      &formStruct{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "quantity", Detail: "must be a valid int", Type: problem.ParameterTypeForm},
+			},
+		},
+		"should bind every value of a repeated query param to a slice": {
+			request: &http.Request{
+				URL: &url.URL{RawQuery: "tag=x&tag=y,z&ids=1&ids=2"},
+			},
+			output:    &sliceStruct{},
+			expected:  &sliceStruct{IDs: []int{1, 2}, Tags: []string{"x", "y,z"}},
+			expectErr: false,
+		},
+		"should split a single comma separated query param into a slice": {
+			request: &http.Request{
+				URL: &url.URL{RawQuery: "ids=1,%202,,3&tag=x"},
+			},
+			output:    &sliceStruct{},
+			expected:  &sliceStruct{IDs: []int{1, 2, 3}, Tags: []string{"x"}},
+			expectErr: false,
+		},
+		"should apply a default to the whole slice when the param has no values": {
+			request: &http.Request{
+				URL: &url.URL{},
+			},
+			output:    &sliceStruct{},
+			expected:  &sliceStruct{IDs: nil, Tags: []string{"a", "b"}},
+			expectErr: false,
+		},
+		"should report a conversion error for a bad slice element against the param": {
+			request: &http.Request{
+				URL: &url.URL{RawQuery: "ids=1,two"},
+			},
+			output:      &sliceStruct{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "ids", Detail: "must be a valid int", Type: problem.ParameterTypeQuery},
+			},
+		},
+		"should report a validation error for a slice element against its index": {
+			request: &http.Request{
+				URL: &url.URL{RawQuery: "ids=1,0"},
+			},
+			output:      &sliceStruct{},
+			expectErr:   true,
+			expectedErr: `400 Bad Parameters: The request parameters are invalid or malformed`,
+			expectedParamErrors: []problem.Parameter{
+				{Parameter: "ids[1]", Detail: "must be at least 1", Type: problem.ParameterTypeQuery},
+			},
+		},
 		"should treat explicit empty query param as missing": {
 			request: &http.Request{
 				URL: &url.URL{RawQuery: "q="},
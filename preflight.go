@@ -0,0 +1,92 @@
+package httputil
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// newAutomaticOptionsHandler creates a handler that answers OPTIONS requests for
+// paths that have at least one endpoint registered with the Server but no
+// OPTIONS endpoint, which would otherwise be rejected by router with a 405
+// Method Not Allowed. All other requests are passed to router.
+//
+// The response is a 204 No Content with the Allow header listing the methods
+// registered for the path. A CORS preflight request, which names the method of
+// the actual request in the Access-Control-Request-Method header, is passed
+// through the middleware applied with [EndpointGroup.WithMiddleware] to the
+// endpoint registered for that method, so that CORS middleware can answer it
+// as if the endpoint handled OPTIONS itself.
+func (s *Server) newAutomaticOptionsHandler(router *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		if _, pattern := router.Handler(r); pattern != "" {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, patterns := routedMethods(router, r)
+		if len(allowed) == 0 {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+		if preflight, ok := s.preflights[patterns[r.Header.Get("Access-Control-Request-Method")]]; ok {
+			preflight.ServeHTTP(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// routedMethods returns the methods that router has an endpoint for at the
+// path of r, along with the pattern that each method is routed to. OPTIONS is
+// always included in the allowed methods as it is answered automatically.
+func routedMethods(router *http.ServeMux, r *http.Request) ([]string, map[string]string) {
+	var allowed []string
+
+	patterns := make(map[string]string)
+
+	for _, method := range httpMethods {
+		if method == http.MethodOptions {
+			continue
+		}
+
+		probe := new(http.Request)
+		*probe = *r
+		probe.Method = method
+
+		if _, pattern := router.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+			patterns[method] = pattern
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+
+	return append(allowed, http.MethodOptions), patterns
+}
+
+// newPreflightHandler creates the handler that answers automatic OPTIONS
+// requests routed to endpoint, wrapping a 204 No Content response with the
+// middleware applied to endpoint in the same order as its Handler.
+func newPreflightHandler(endpoint Endpoint) http.Handler {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, m := range slices.Backward(endpoint.middlewares) {
+		handler = m(handler)
+	}
+
+	return handler
+}
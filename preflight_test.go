@@ -0,0 +1,154 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestServer_AutomaticOptions(t *testing.T) {
+	t.Parallel()
+
+	// cors is a minimal CORS middleware that answers preflight requests and
+	// passes everything else through.
+	cors := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := map[string]struct {
+		endpoints        httputil.EndpointGroup
+		target           string
+		header           http.Header
+		wantStatus       int
+		wantAllow        string
+		wantAllowMethods string
+	}{
+		"a preflight to a path with only a get route is answered by the cors middleware": {
+			endpoints:        httputil.EndpointGroup{{Method: http.MethodGet, Path: "/users", Handler: ok}}.WithMiddleware(cors),
+			target:           "/users",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodGet}},
+			wantStatus:       http.StatusNoContent,
+			wantAllow:        "GET, HEAD, OPTIONS",
+			wantAllowMethods: http.MethodGet,
+		},
+		"a preflight uses the middleware of the endpoint for the requested method": {
+			endpoints: append(
+				httputil.EndpointGroup{{Method: http.MethodGet, Path: "/users", Handler: ok}},
+				httputil.EndpointGroup{{Method: http.MethodPost, Path: "/users", Handler: ok}}.WithMiddleware(cors)...,
+			),
+			target:           "/users",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodPost}},
+			wantStatus:       http.StatusNoContent,
+			wantAllow:        "GET, HEAD, POST, OPTIONS",
+			wantAllowMethods: http.MethodPost,
+		},
+		"a preflight matches routes with wildcards": {
+			endpoints:        httputil.EndpointGroup{{Method: http.MethodDelete, Path: "/users/{id}", Handler: ok}}.WithMiddleware(cors),
+			target:           "/users/1",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodDelete}},
+			wantStatus:       http.StatusNoContent,
+			wantAllow:        "DELETE, OPTIONS",
+			wantAllowMethods: http.MethodDelete,
+		},
+		"a preflight for an unregistered method is answered without the middleware": {
+			endpoints:        httputil.EndpointGroup{{Method: http.MethodGet, Path: "/users", Handler: ok}}.WithMiddleware(cors),
+			target:           "/users",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodPut}},
+			wantStatus:       http.StatusNoContent,
+			wantAllow:        "GET, HEAD, OPTIONS",
+			wantAllowMethods: "",
+		},
+		"an options request that is not a preflight lists the allowed methods": {
+			endpoints:        httputil.EndpointGroup{{Method: http.MethodGet, Path: "/users", Handler: ok}}.WithMiddleware(cors),
+			target:           "/users",
+			header:           http.Header{},
+			wantStatus:       http.StatusNoContent,
+			wantAllow:        "GET, HEAD, OPTIONS",
+			wantAllowMethods: "",
+		},
+		"an options request to a path without routes is not found": {
+			endpoints:        httputil.EndpointGroup{{Method: http.MethodGet, Path: "/users", Handler: ok}}.WithMiddleware(cors),
+			target:           "/orders",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodGet}},
+			wantStatus:       http.StatusNotFound,
+			wantAllow:        "",
+			wantAllowMethods: "",
+		},
+		"a registered options endpoint takes precedence": {
+			endpoints: httputil.EndpointGroup{
+				{Method: http.MethodGet, Path: "/users", Handler: ok},
+				{Method: http.MethodOptions, Path: "/users", Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				})},
+			},
+			target:           "/users",
+			header:           http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {http.MethodGet}},
+			wantStatus:       http.StatusTeapot,
+			wantAllow:        "",
+			wantAllowMethods: "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(testCase.endpoints...)
+
+			request := httptest.NewRequest(http.MethodOptions, testCase.target, nil)
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if got := response.Header().Get("Allow"); got != testCase.wantAllow {
+				t.Errorf("Allow = %q, want: %q", got, testCase.wantAllow)
+			}
+
+			if got := response.Header().Get("Access-Control-Allow-Methods"); got != testCase.wantAllowMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want: %q", got, testCase.wantAllowMethods)
+			}
+		})
+	}
+
+	t.Run("other methods are still not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{Method: http.MethodGet, Path: "/users", Handler: ok})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPut, "/users", nil))
+
+		if response.Code != http.StatusMethodNotAllowed {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusMethodNotAllowed)
+		}
+	})
+}
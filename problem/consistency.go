@@ -0,0 +1,25 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInconsistentCode is returned by ValidateConsistency when the Code of a
+// DetailedError does not match its Status.
+var ErrInconsistentCode = errors.New("problem code is inconsistent with status")
+
+// ValidateConsistency checks that the Code of d is prefixed with its Status
+// followed by a hyphen, as in "400-01" for a Status of 400, which is the format
+// used by the constructors in this package. It returns an error wrapping
+// [ErrInconsistentCode] if it is not. This is intended for tests that guard
+// against mismatches when adding new problem constructors.
+func ValidateConsistency(d *DetailedError) error {
+	if prefix := strconv.Itoa(d.Status) + "-"; !strings.HasPrefix(d.Code, prefix) || len(d.Code) == len(prefix) {
+		return fmt.Errorf("%w: code %q does not match status %d", ErrInconsistentCode, d.Code, d.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package problem_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestValidateConsistency(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		status  int
+		code    string
+		wantErr bool
+	}{
+		"a code prefixed with the status is consistent": {
+			status:  http.StatusNotFound,
+			code:    "404-01",
+			wantErr: false,
+		},
+		"a code prefixed with a different status is inconsistent": {
+			status:  http.StatusNotFound,
+			code:    "400-01",
+			wantErr: true,
+		},
+		"a code sharing only the leading digits of the status is inconsistent": {
+			status:  http.StatusBadRequest,
+			code:    "4001-01",
+			wantErr: true,
+		},
+		"a code without a suffix is inconsistent": {
+			status:  http.StatusBadRequest,
+			code:    "400-",
+			wantErr: true,
+		},
+		"an empty code is inconsistent": {
+			status:  http.StatusBadRequest,
+			code:    "",
+			wantErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			details := &problem.DetailedError{
+				Type:             "",
+				Title:            "",
+				Detail:           "",
+				Status:           testCase.status,
+				Code:             testCase.code,
+				Instance:         "",
+				ExtensionMembers: nil,
+			}
+
+			err := problem.ValidateConsistency(details)
+			if got := errors.Is(err, problem.ErrInconsistentCode); got != testCase.wantErr {
+				t.Errorf("ValidateConsistency() error = %v, want ErrInconsistentCode: %t", err, testCase.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package problem
 
 import (
 	"net/http"
+	"strings"
+	"unicode"
 )
 
 const (
@@ -17,7 +19,8 @@ var ErrorDocumentationLocation = DefaultErrorDocumentationLocation //nolint:goch
 
 // ParameterType defines the type of the parameter that caused an error.
 // It is used to classify parameters into query parameters, header parameters,
-// or path parameters and to provide more context about the specific issue.
+// path parameters, or form parameters and to provide more context about the
+// specific issue.
 type ParameterType string
 
 const (
@@ -37,11 +40,16 @@ const (
 	// parameter. Path parameters are used in the URL path and typically represent a
 	// resource identifier or dynamic data.
 	ParameterTypePath ParameterType = "path"
+
+	// ParameterTypeForm indicates that the parameter error is related to a form
+	// parameter. Form parameters are sent in a form encoded request body alongside
+	// the data decoded by the handler.
+	ParameterTypeForm ParameterType = "form"
 )
 
 // Parameter represents a specific parameter that caused an error during request
 // validation. It provides details about the error, the parameter name, and its
-// type (query, header, path).
+// type (query, header, path, form).
 type Parameter struct {
 	Parameter string        `json:"parameter"`
 	Detail    string        `json:"detail"`
@@ -145,6 +153,53 @@ func Forbidden(r *http.Request) *DetailedError {
 	}
 }
 
+// Gone creates a DetailedError for requests to a resource that has been
+// permanently removed and will not be available again. Unlike [NotFound], it
+// tells clients that they should stop requesting the resource.
+func Gone(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("gone"),
+		Title:            "Gone",
+		Detail:           "The requested resource is no longer available and will not be available again",
+		Status:           http.StatusGone,
+		Code:             "410-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// New creates a DetailedError for application specific problems that are not
+// covered by the other constructors. The type is derived from title using the
+// same scheme as the other constructors, so a title of "Quota Exceeded" has a
+// type of [ErrorDocumentationLocation] followed by "quota-exceeded.md". Codes
+// conventionally take the form "<status>-<nn>", such as "429-01".
+func New(r *http.Request, status int, code, title, detail string) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation(typeSlug(title)),
+		Title:            title,
+		Detail:           detail,
+		Status:           status,
+		Code:             code,
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// NotAcceptable creates a DetailedError for requests that ask for a
+// representation the server cannot produce, such as an unsupported API version
+// or media type.
+func NotAcceptable(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("not-acceptable"),
+		Title:            "Not Acceptable",
+		Detail:           "The requested representation of the resource is not available",
+		Status:           http.StatusNotAcceptable,
+		Code:             "406-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
 // NotFound creates a DetailedError for not found errors.
 func NotFound(r *http.Request) *DetailedError {
 	return &DetailedError{
@@ -158,6 +213,64 @@ func NotFound(r *http.Request) *DetailedError {
 	}
 }
 
+// PayloadTooLarge creates a DetailedError for requests that are rejected
+// because their content, such as an uploaded file, is larger than the server
+// allows.
+func PayloadTooLarge(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("payload-too-large"),
+		Title:            "Payload Too Large",
+		Detail:           "The request payload is too large",
+		Status:           http.StatusRequestEntityTooLarge,
+		Code:             "413-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// PaymentRequired creates a DetailedError for requests to features that
+// require payment, such as a paid plan or a subscription that has lapsed.
+func PaymentRequired(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("payment-required"),
+		Title:            "Payment Required",
+		Detail:           "Payment is required to access this resource",
+		Status:           http.StatusPaymentRequired,
+		Code:             "402-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// PreconditionFailed creates a DetailedError for requests whose conditional
+// headers, such as If-Match or If-None-Match, do not hold for the current state
+// of the resource.
+func PreconditionFailed(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("precondition-failed"),
+		Title:            "Precondition Failed",
+		Detail:           "The resource does not meet the preconditions of the request",
+		Status:           http.StatusPreconditionFailed,
+		Code:             "412-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// PreconditionRequired creates a DetailedError for requests that must be
+// conditional, using a header such as If-Match, but are not.
+func PreconditionRequired(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("precondition-required"),
+		Title:            "Precondition Required",
+		Detail:           "The request must be conditional, use the If-Match or If-None-Match header",
+		Status:           http.StatusPreconditionRequired,
+		Code:             "428-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
 // ResourceExists creates a DetailedError for duplicate resource errors.
 func ResourceExists(r *http.Request) *DetailedError {
 	return &DetailedError{
@@ -171,6 +284,52 @@ func ResourceExists(r *http.Request) *DetailedError {
 	}
 }
 
+// Conflict creates a DetailedError for requests that conflict with the current
+// state of the resource, such as an update based on a stale version of the
+// resource or a transition that its current state does not allow. Use
+// [ResourceExists] when the conflict is a duplicate resource.
+func Conflict(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("conflict"),
+		Title:            "Conflict",
+		Detail:           "The request conflicts with the current state of the resource",
+		Status:           http.StatusConflict,
+		Code:             "409-02",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// RequestInProgress creates a DetailedError for requests that conflict with an
+// identical request that is still being processed, such as a retry sent with
+// the same idempotency key before the original request has completed.
+func RequestInProgress(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("request-in-progress"),
+		Title:            "Request In Progress",
+		Detail:           "A request with the same idempotency key is already being processed",
+		Status:           http.StatusConflict,
+		Code:             "409-03",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
+// RequestHeaderFieldsTooLarge creates a DetailedError for requests that are
+// rejected because they have too many header fields or a header field that is
+// too large.
+func RequestHeaderFieldsTooLarge(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("request-header-fields-too-large"),
+		Title:            "Request Header Fields Too Large",
+		Detail:           "The request header fields are too large",
+		Status:           http.StatusRequestHeaderFieldsTooLarge,
+		Code:             "431-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
 // ServerError creates a DetailedError for internal server errors.
 func ServerError(r *http.Request) *DetailedError {
 	return &DetailedError{
@@ -197,6 +356,30 @@ func Unauthorized(r *http.Request) *DetailedError {
 	}
 }
 
+// URITooLong creates a DetailedError for requests that are rejected because
+// their URI or query string is too long.
+func URITooLong(r *http.Request) *DetailedError {
+	return &DetailedError{
+		Type:             typeLocation("uri-too-long"),
+		Title:            "URI Too Long",
+		Detail:           "The request URI is too long",
+		Status:           http.StatusRequestURITooLong,
+		Code:             "414-01",
+		Instance:         r.URL.Path,
+		ExtensionMembers: nil,
+	}
+}
+
 func typeLocation(t string) string {
 	return ErrorDocumentationLocation + t + ".md"
 }
+
+// typeSlug converts title to the lower case, hyphen separated form used in type
+// locations, such as "quota-exceeded" for "Quota Exceeded".
+func typeSlug(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "-")
+}
@@ -236,6 +236,156 @@ func TestConstructors(t *testing.T) {
 				extensions:     "",
 			},
 		},
+		"request in progress sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.RequestInProgress(newRequest(t, http.MethodPost, "/orders"))
+			},
+			want: details{
+				detail:         "A request with the same idempotency key is already being processed",
+				instance:       "/orders",
+				status:         http.StatusConflict,
+				code:           "409-03",
+				title:          "Request In Progress",
+				typeIdentifier: "request-in-progress",
+				extensions:     "",
+			},
+		},
+		"gone sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.Gone(newRequest(t, http.MethodGet, "/orders/1"))
+			},
+			want: details{
+				detail:         "The requested resource is no longer available and will not be available again",
+				instance:       "/orders/1",
+				status:         http.StatusGone,
+				code:           "410-01",
+				title:          "Gone",
+				typeIdentifier: "gone",
+				extensions:     "",
+			},
+		},
+		"state conflict sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.Conflict(newRequest(t, http.MethodPut, "/orders/1"))
+			},
+			want: details{
+				detail:         "The request conflicts with the current state of the resource",
+				instance:       "/orders/1",
+				status:         http.StatusConflict,
+				code:           "409-02",
+				title:          "Conflict",
+				typeIdentifier: "conflict",
+				extensions:     "",
+			},
+		},
+		"payment required sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.PaymentRequired(newRequest(t, http.MethodPost, "/reports/export"))
+			},
+			want: details{
+				detail:         "Payment is required to access this resource",
+				instance:       "/reports/export",
+				status:         http.StatusPaymentRequired,
+				code:           "402-01",
+				title:          "Payment Required",
+				typeIdentifier: "payment-required",
+				extensions:     "",
+			},
+		},
+		"new sets the given problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.New(newRequest(t, http.MethodPost, "/reports"), http.StatusTooManyRequests, "429-01", "Quota Exceeded", "The monthly report quota has been used")
+			},
+			want: details{
+				detail:         "The monthly report quota has been used",
+				instance:       "/reports",
+				status:         http.StatusTooManyRequests,
+				code:           "429-01",
+				title:          "Quota Exceeded",
+				typeIdentifier: "quota-exceeded",
+				extensions:     "",
+			},
+		},
+		"new derives the type from a title with punctuation": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.New(newRequest(t, http.MethodGet, "/legal"), http.StatusUnavailableForLegalReasons, "451-01", "Unavailable (Legal Reasons)", "Blocked in your region")
+			},
+			want: details{
+				detail:         "Blocked in your region",
+				instance:       "/legal",
+				status:         http.StatusUnavailableForLegalReasons,
+				code:           "451-01",
+				title:          "Unavailable (Legal Reasons)",
+				typeIdentifier: "unavailable-legal-reasons",
+				extensions:     "",
+			},
+		},
+		"not acceptable sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.NotAcceptable(newRequest(t, http.MethodGet, "/orders"))
+			},
+			want: details{
+				detail:         "The requested representation of the resource is not available",
+				instance:       "/orders",
+				status:         http.StatusNotAcceptable,
+				code:           "406-01",
+				title:          "Not Acceptable",
+				typeIdentifier: "not-acceptable",
+				extensions:     "",
+			},
+		},
+		"precondition failed sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.PreconditionFailed(newRequest(t, http.MethodPut, "/orders"))
+			},
+			want: details{
+				detail:         "The resource does not meet the preconditions of the request",
+				instance:       "/orders",
+				status:         http.StatusPreconditionFailed,
+				code:           "412-01",
+				title:          "Precondition Failed",
+				typeIdentifier: "precondition-failed",
+				extensions:     "",
+			},
+		},
+		"precondition required sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.PreconditionRequired(newRequest(t, http.MethodPut, "/orders"))
+			},
+			want: details{
+				detail:         "The request must be conditional, use the If-Match or If-None-Match header",
+				instance:       "/orders",
+				status:         http.StatusPreconditionRequired,
+				code:           "428-01",
+				title:          "Precondition Required",
+				typeIdentifier: "precondition-required",
+				extensions:     "",
+			},
+		},
+		"request header fields too large sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.RequestHeaderFieldsTooLarge(newRequest(t, http.MethodGet, "/orders"))
+			},
+			want: details{
+				detail:         "The request header fields are too large",
+				instance:       "/orders",
+				status:         http.StatusRequestHeaderFieldsTooLarge,
+				code:           "431-01",
+				title:          "Request Header Fields Too Large",
+				typeIdentifier: "request-header-fields-too-large",
+				extensions:     "",
+			},
+		},
 		"internal server error sets the expected problem details for the resource instance": {
 			newDetailedError: func(t *testing.T) *problem.DetailedError {
 				t.Helper()
@@ -266,5 +416,73 @@ func TestConstructors(t *testing.T) {
 				extensions:     "",
 			},
 		},
+		"payload too large sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.PayloadTooLarge(newRequest(t, http.MethodPost, "/uploads"))
+			},
+			want: details{
+				detail:         "The request payload is too large",
+				instance:       "/uploads",
+				status:         http.StatusRequestEntityTooLarge,
+				code:           "413-01",
+				title:          "Payload Too Large",
+				typeIdentifier: "payload-too-large",
+				extensions:     "",
+			},
+		},
+		"uri too long sets the expected problem details for the resource instance": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+				return problem.URITooLong(newRequest(t, http.MethodGet, "/orders"))
+			},
+			want: details{
+				detail:         "The request URI is too long",
+				instance:       "/orders",
+				status:         http.StatusRequestURITooLong,
+				code:           "414-01",
+				title:          "URI Too Long",
+				typeIdentifier: "uri-too-long",
+				extensions:     "",
+			},
+		},
 	})
 }
+
+func TestConstructorsConsistency(t *testing.T) {
+	t.Parallel()
+
+	r := newRequest(t, http.MethodGet, "/tests")
+
+	constructors := map[string]*problem.DetailedError{
+		"BadParameters":               problem.BadParameters(r),
+		"BadRequest":                  problem.BadRequest(r),
+		"BusinessRuleViolation":       problem.BusinessRuleViolation(r),
+		"Conflict":                    problem.Conflict(r),
+		"ConstraintViolation":         problem.ConstraintViolation(r),
+		"Forbidden":                   problem.Forbidden(r),
+		"Gone":                        problem.Gone(r),
+		"NotAcceptable":               problem.NotAcceptable(r),
+		"NotFound":                    problem.NotFound(r),
+		"PayloadTooLarge":             problem.PayloadTooLarge(r),
+		"PaymentRequired":             problem.PaymentRequired(r),
+		"PreconditionFailed":          problem.PreconditionFailed(r),
+		"PreconditionRequired":        problem.PreconditionRequired(r),
+		"ResourceExists":              problem.ResourceExists(r),
+		"RequestInProgress":           problem.RequestInProgress(r),
+		"RequestHeaderFieldsTooLarge": problem.RequestHeaderFieldsTooLarge(r),
+		"ServerError":                 problem.ServerError(r),
+		"Unauthorized":                problem.Unauthorized(r),
+		"URITooLong":                  problem.URITooLong(r),
+	}
+
+	for name, details := range constructors {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := problem.ValidateConsistency(details); err != nil {
+				t.Errorf("ValidateConsistency() error = %v, want: nil", err)
+			}
+		})
+	}
+}
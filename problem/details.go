@@ -11,6 +11,7 @@ import (
 	"maps"
 	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -45,6 +46,18 @@ func (d *DetailedError) WithDetail(detail string) *DetailedError {
 	return &clone
 }
 
+// WithInstance creates a new DetailedError instance with the provided instance
+// URI reference. It returns a copy of the original DetailedError with the
+// updated Instance field.
+func (d *DetailedError) WithInstance(instance string) *DetailedError {
+	clone := *d
+
+	clone.Instance = instance
+	clone.ExtensionMembers = maps.Clone(d.ExtensionMembers)
+
+	return &clone
+}
+
 // WithExtension creates a new DetailedError instance with an added or updated
 // extension member. It returns a copy of the original DetailedError with the
 // specified extension member added or updated. If the original DetailedError has
@@ -64,6 +77,51 @@ func (d *DetailedError) WithExtension(k string, v any) *DetailedError {
 	return &clone
 }
 
+// WithHelp creates a new DetailedError instance with a "help" extension member
+// pointing to documentation that explains how to resolve the problem. Unlike
+// the Type URI, which identifies the problem type, the help URL is intended to
+// guide the client towards a fix. It follows the semantics of
+// [DetailedError.WithExtension]; the original DetailedError is not modified.
+func (d *DetailedError) WithHelp(url string) *DetailedError {
+	return d.WithExtension("help", url)
+}
+
+// IsClientError reports whether the problem is a client error with a 4xx
+// status. See [DetailedError.IsServerError] for how the status is determined.
+func (d *DetailedError) IsClientError() bool {
+	status := d.codeStatus()
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError
+}
+
+// IsServerError reports whether the problem is a server error with a 5xx
+// status. The status is taken from the prefix of the Code, such as "503" in
+// "503-01", falling back to the Status when the Code does not start with one.
+func (d *DetailedError) IsServerError() bool {
+	const maxServerErrorStatus = 599
+
+	status := d.codeStatus()
+
+	return status >= http.StatusInternalServerError && status <= maxServerErrorStatus
+}
+
+// IsCode reports whether the Code of the problem is code, such as "409-01".
+func (d *DetailedError) IsCode(code string) bool {
+	return d.Code == code
+}
+
+// codeStatus returns the status of the problem, taken from the prefix of the
+// Code if it has one and the Status otherwise.
+func (d *DetailedError) codeStatus() int {
+	const statusLen = 3
+
+	prefix, _, _ := strings.Cut(d.Code, "-")
+	if status, err := strconv.Atoi(prefix); err == nil && len(prefix) == statusLen {
+		return status
+	}
+
+	return d.Status
+}
+
 // Error implements the `error` interface, allowing DetailedError objects to be
 // used as errors.
 func (d *DetailedError) Error() string { return fmt.Sprintf("%d %s: %s", d.Status, d.Title, d.Detail) }
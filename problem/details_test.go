@@ -64,6 +64,86 @@ func TestResponse(t *testing.T) {
 	}
 }
 
+func TestDetailedErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	request := newRequest(t, http.MethodGet, "/tests")
+
+	tests := map[string]struct {
+		problem         *problem.DetailedError
+		wantClientError bool
+		wantServerError bool
+	}{
+		"a bad request is a client error": {
+			problem:         problem.BadRequest(request),
+			wantClientError: true,
+			wantServerError: false,
+		},
+		"a conflict is a client error": {
+			problem:         problem.ResourceExists(request),
+			wantClientError: true,
+			wantServerError: false,
+		},
+		"a server error is a server error": {
+			problem:         problem.ServerError(request),
+			wantClientError: false,
+			wantServerError: true,
+		},
+		"a service unavailable is a server error": {
+			problem:         &problem.DetailedError{Status: http.StatusServiceUnavailable, Code: "503-01"},
+			wantClientError: false,
+			wantServerError: true,
+		},
+		"the code prefix takes precedence over the status": {
+			problem:         &problem.DetailedError{Status: http.StatusInternalServerError, Code: "404-01"},
+			wantClientError: true,
+			wantServerError: false,
+		},
+		"the status is used when the code has no status prefix": {
+			problem:         &problem.DetailedError{Status: http.StatusBadGateway, Code: "UPSTREAM-FAILED"},
+			wantClientError: false,
+			wantServerError: true,
+		},
+		"a decoded problem without a code or status is neither": {
+			problem:         &problem.DetailedError{},
+			wantClientError: false,
+			wantServerError: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.problem.IsClientError(); got != tt.wantClientError {
+				t.Errorf("IsClientError() = %v, want %v", got, tt.wantClientError)
+			}
+
+			if got := tt.problem.IsServerError(); got != tt.wantServerError {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServerError)
+			}
+		})
+	}
+}
+
+func TestDetailedErrorIsCode(t *testing.T) {
+	t.Parallel()
+
+	conflict := problem.ResourceExists(newRequest(t, http.MethodGet, "/tests"))
+
+	if !conflict.IsCode("409-01") {
+		t.Errorf("IsCode(%q) = false, want true for code %q", "409-01", conflict.Code)
+	}
+
+	if conflict.IsCode("409") {
+		t.Errorf("IsCode(%q) = true, want false for code %q", "409", conflict.Code)
+	}
+
+	if conflict.IsCode("400-01") {
+		t.Errorf("IsCode(%q) = true, want false for code %q", "400-01", conflict.Code)
+	}
+}
+
 func TestDetailedErrorMarshalJSON(t *testing.T) {
 	t.Parallel()
 
@@ -129,6 +209,23 @@ func TestDetailedErrorMarshalJSON(t *testing.T) {
 				extensions:     "",
 			},
 		},
+		"updates the instance field when WithInstance is called": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+
+				return problem.BadRequest(newRequest(t, http.MethodGet, "/tests")).
+					WithInstance("https://example.com/tests?page=2")
+			},
+			want: details{
+				detail:         "The request is invalid or malformed",
+				instance:       "https://example.com/tests?page=2",
+				status:         http.StatusBadRequest,
+				code:           "400-01",
+				title:          "Bad Request",
+				typeIdentifier: "bad-request",
+				extensions:     "",
+			},
+		},
 		"adds extensions to the problem details when WithExtension is called": {
 			newDetailedError: func(t *testing.T) *problem.DetailedError {
 				t.Helper()
@@ -189,6 +286,98 @@ func TestDetailedErrorMarshalJSON(t *testing.T) {
 	})
 }
 
+func TestDetailedErrorWithHelp(t *testing.T) {
+	t.Parallel()
+
+	const helpURL = "https://example.com/docs/fix-bad-request"
+
+	testCases := map[string]struct {
+		newDetailedError func(t *testing.T) *problem.DetailedError
+		want             map[string]any
+	}{
+		"adds the help member alongside the base members": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+
+				return problem.BadRequest(newRequest(t, http.MethodGet, "/tests")).WithHelp(helpURL)
+			},
+			want: map[string]any{
+				"code":     "400-01",
+				"detail":   "The request is invalid or malformed",
+				"help":     helpURL,
+				"instance": "/tests",
+				"status":   float64(http.StatusBadRequest),
+				"title":    "Bad Request",
+				"type":     "https://github.com/nickbryan/httputil/blob/main/docs/problems/bad-request.md",
+			},
+		},
+		"keeps other extensions and overridden base members": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+
+				return problem.BadRequest(newRequest(t, http.MethodGet, "/tests")).
+					WithExtension("validation", "error").
+					WithDetail("This is the overridden detail").
+					WithHelp(helpURL)
+			},
+			want: map[string]any{
+				"code":       "400-01",
+				"detail":     "This is the overridden detail",
+				"help":       helpURL,
+				"instance":   "/tests",
+				"status":     float64(http.StatusBadRequest),
+				"title":      "Bad Request",
+				"type":       "https://github.com/nickbryan/httputil/blob/main/docs/problems/bad-request.md",
+				"validation": "error",
+			},
+		},
+		"takes the last call to WithHelp into account when multiple calls are made": {
+			newDetailedError: func(t *testing.T) *problem.DetailedError {
+				t.Helper()
+
+				return problem.BadRequest(newRequest(t, http.MethodGet, "/tests")).
+					WithHelp("https://example.com/docs/old").
+					WithHelp(helpURL)
+			},
+			want: map[string]any{
+				"code":     "400-01",
+				"detail":   "The request is invalid or malformed",
+				"help":     helpURL,
+				"instance": "/tests",
+				"status":   float64(http.StatusBadRequest),
+				"title":    "Bad Request",
+				"type":     "https://github.com/nickbryan/httputil/blob/main/docs/problems/bad-request.md",
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var got map[string]any
+			if err := json.Unmarshal(testCase.newDetailedError(t).MustMarshalJSON(), &got); err != nil {
+				t.Fatalf("unable to unmarshal detailedError: %+v", err)
+			}
+
+			if diff := cmp.Diff(testCase.want, got); diff != "" {
+				t.Errorf("detailedError does not match expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("does not modify the original DetailedError", func(t *testing.T) {
+		t.Parallel()
+
+		original := problem.BadRequest(newRequest(t, http.MethodGet, "/tests"))
+		_ = original.WithHelp(helpURL)
+
+		if _, ok := original.ExtensionMembers["help"]; ok {
+			t.Errorf("original.ExtensionMembers contains help, want it unmodified")
+		}
+	})
+}
+
 func TestDetailedErrorMustMarshalJSON(t *testing.T) {
 	t.Parallel()
 
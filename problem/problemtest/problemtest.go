@@ -0,0 +1,93 @@
+// Package problemtest provides helpers for asserting problem details in tests.
+//
+// Problems are compared on their JSON representation so that extension members
+// built from typed values (e.g. []problem.Parameter) compare equal to the same
+// members decoded from a response body. The Instance member is ignored as it
+// is derived from the request URL and is rarely relevant to the assertion.
+package problemtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// NewRequest creates a new *http.Request suitable for passing to the problem
+// constructors. The path is resolved against http://localhost.
+func NewRequest(method, path string) *http.Request {
+	return httptest.NewRequest(method, "http://localhost"+path, nil)
+}
+
+// AssertProblem decodes body as a problem.DetailedError and reports a test
+// error if the decoded problem does not have the wanted status and code. The
+// test fails immediately if body is not a valid problem JSON document.
+func AssertProblem(t testing.TB, body []byte, wantStatus int, wantCode string) {
+	t.Helper()
+
+	var got problem.DetailedError
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("problemtest: unable to decode problem from body %q: %+v", body, err)
+		return
+	}
+
+	if got.Status != wantStatus {
+		t.Errorf("problemtest: problem status = %d, want %d", got.Status, wantStatus)
+	}
+
+	if got.Code != wantCode {
+		t.Errorf("problemtest: problem code = %q, want %q", got.Code, wantCode)
+	}
+}
+
+// Equal reports whether got and want describe the same problem, ignoring the
+// Instance member. When they differ, a human-readable diff (-want +got) is
+// returned alongside false.
+func Equal(got, want *problem.DetailedError) (bool, string) {
+	diff := cmp.Diff(normalize(want), normalize(got))
+
+	return diff == "", diff
+}
+
+// normalized is the normalized form of a problem.DetailedError used for
+// comparison. Extension members are round-tripped through JSON so that typed
+// values and decoded values compare equal.
+type normalized struct {
+	Type             string
+	Title            string
+	Detail           string
+	Status           int
+	Code             string
+	ExtensionMembers map[string]any
+}
+
+// normalize converts d into its normalized form. A nil d normalizes to nil so
+// that nil problems can be compared.
+func normalize(d *problem.DetailedError) *normalized {
+	if d == nil {
+		return nil
+	}
+
+	var decoded problem.DetailedError
+	if err := json.Unmarshal(d.MustMarshalJSON(), &decoded); err != nil {
+		// This should never happen as we are decoding our own encoding.
+		panic("problemtest: round-tripping problem: " + err.Error())
+	}
+
+	if len(decoded.ExtensionMembers) == 0 {
+		decoded.ExtensionMembers = nil
+	}
+
+	return &normalized{
+		Type:             decoded.Type,
+		Title:            decoded.Title,
+		Detail:           decoded.Detail,
+		Status:           decoded.Status,
+		Code:             decoded.Code,
+		ExtensionMembers: decoded.ExtensionMembers,
+	}
+}
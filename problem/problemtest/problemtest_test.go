@@ -0,0 +1,172 @@
+package problemtest_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/httputil/problem"
+	"github.com/nickbryan/httputil/problem/problemtest"
+)
+
+func TestAssertProblem(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		body       []byte
+		wantStatus int
+		wantCode   string
+		wantErrors []string
+		wantFatal  bool
+	}{
+		"no errors are reported when the status and code match": {
+			body:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).MustMarshalJSON(),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "404-01",
+		},
+		"an error is reported when the status does not match": {
+			body:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).MustMarshalJSON(),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "404-01",
+			wantErrors: []string{"problemtest: problem status = 404, want 400"},
+		},
+		"an error is reported when the code does not match": {
+			body:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).MustMarshalJSON(),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "404-02",
+			wantErrors: []string{`problemtest: problem code = "404-01", want "404-02"`},
+		},
+		"the test fails immediately when the body is not valid JSON": {
+			body:       []byte("not json"),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "404-01",
+			wantFatal:  true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recordingTB{TB: t, errors: nil, fatal: false}
+
+			problemtest.AssertProblem(rec, testCase.body, testCase.wantStatus, testCase.wantCode)
+
+			if rec.fatal != testCase.wantFatal {
+				t.Errorf("fatal = %t, want %t", rec.fatal, testCase.wantFatal)
+			}
+
+			if testCase.wantFatal {
+				return
+			}
+
+			if strings.Join(rec.errors, "\n") != strings.Join(testCase.wantErrors, "\n") {
+				t.Errorf("errors = %q, want %q", rec.errors, testCase.wantErrors)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		got, want *problem.DetailedError
+		wantEqual bool
+	}{
+		"problems are equal when all fields match": {
+			got:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")),
+			want:      problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")),
+			wantEqual: true,
+		},
+		"problems are equal when only the instance differs": {
+			got:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test/1")),
+			want:      problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test/2")),
+			wantEqual: true,
+		},
+		"problems are equal when typed extension members match decoded extension members": {
+			got: mustDecode(t, problem.BadParameters(
+				problemtest.NewRequest(http.MethodGet, "/test"),
+				problem.Parameter{Parameter: "id", Detail: "is required", Type: problem.ParameterTypePath},
+			).MustMarshalJSON()),
+			want: problem.BadParameters(
+				problemtest.NewRequest(http.MethodGet, "/test"),
+				problem.Parameter{Parameter: "id", Detail: "is required", Type: problem.ParameterTypePath},
+			),
+			wantEqual: true,
+		},
+		"problems are equal when both are nil": {
+			got:       nil,
+			want:      nil,
+			wantEqual: true,
+		},
+		"problems are not equal when the detail differs": {
+			got:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).WithDetail("other"),
+			want:      problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")),
+			wantEqual: false,
+		},
+		"problems are not equal when the code differs": {
+			got:       problem.BadRequest(problemtest.NewRequest(http.MethodGet, "/test")),
+			want:      problem.BadParameters(problemtest.NewRequest(http.MethodGet, "/test")),
+			wantEqual: false,
+		},
+		"problems are not equal when extension members differ": {
+			got:       problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).WithExtension("foo", "bar"),
+			want:      problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")).WithExtension("foo", "baz"),
+			wantEqual: false,
+		},
+		"problems are not equal when one is nil": {
+			got:       nil,
+			want:      problem.NotFound(problemtest.NewRequest(http.MethodGet, "/test")),
+			wantEqual: false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			equal, diff := problemtest.Equal(testCase.got, testCase.want)
+			if equal != testCase.wantEqual {
+				t.Errorf("Equal() = %t, want %t", equal, testCase.wantEqual)
+			}
+
+			if equal && diff != "" {
+				t.Errorf("Equal() returned a diff for equal problems:\n%s", diff)
+			}
+
+			if !equal && diff == "" {
+				t.Error("Equal() returned an empty diff for unequal problems")
+			}
+		})
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+
+	errors []string
+	fatal  bool
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(_ string, _ ...any) {
+	r.fatal = true
+}
+
+func mustDecode(t *testing.T, body []byte) *problem.DetailedError {
+	t.Helper()
+
+	var d problem.DetailedError
+	if err := d.UnmarshalJSON(body); err != nil {
+		t.Fatalf("unable to decode problem: %+v", err)
+	}
+
+	return &d
+}
@@ -0,0 +1,23 @@
+package httputil
+
+import (
+	"net/http"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// ProblemInstanceFunc computes the instance member of a problem response
+// written for r. See [WithServerProblemInstance].
+type ProblemInstanceFunc func(r *http.Request) string
+
+// applyProblemInstance returns a copy of problemDetails with its instance set by
+// instance. problemDetails is returned unchanged if instance is nil or the
+// instance is not the request path set by the problem constructors, so that
+// instances set explicitly are kept.
+func applyProblemInstance(r *http.Request, problemDetails *problem.DetailedError, instance ProblemInstanceFunc) *problem.DetailedError {
+	if instance == nil || problemDetails.Instance != r.URL.Path {
+		return problemDetails
+	}
+
+	return problemDetails.WithInstance(instance(r))
+}
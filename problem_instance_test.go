@@ -0,0 +1,217 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestServer_ProblemInstance(t *testing.T) {
+	t.Parallel()
+
+	notFound := func(r *http.Request) error { return problem.NotFound(r) }
+	// httptest.NewRequest sets the remote address to 192.0.2.1.
+	trustedProxy := netip.MustParsePrefix("192.0.2.0/24")
+
+	testCases := map[string]struct {
+		options      []httputil.ServerOption
+		target       string
+		header       http.Header
+		err          func(r *http.Request) error
+		wantInstance string
+	}{
+		"the instance is the request path by default": {
+			options:      nil,
+			target:       "http://example.com/things/1?page=2",
+			header:       http.Header{},
+			err:          notFound,
+			wantInstance: "/things/1",
+		},
+		"the instance is the full request url when enabled": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			target:       "http://example.com/things/1?page=2",
+			header:       http.Header{},
+			err:          notFound,
+			wantInstance: "http://example.com/things/1?page=2",
+		},
+		"the scheme is https for a tls request": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			target:       "https://example.com/things/1",
+			header:       http.Header{},
+			err:          notFound,
+			wantInstance: "https://example.com/things/1",
+		},
+		"forwarded headers are ignored when not trusted": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			target:       "http://internal:8080/things/1",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"api.example.com"}},
+			err:          notFound,
+			wantInstance: "http://internal:8080/things/1",
+		},
+		"forwarded headers are ignored from a peer that is not a trusted proxy": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL(netip.MustParsePrefix("10.0.0.0/8"))},
+			target:       "http://internal:8080/things/1",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"api.example.com"}},
+			err:          notFound,
+			wantInstance: "http://internal:8080/things/1",
+		},
+		"x-forwarded headers set the scheme and host when trusted": {
+			options: []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL(trustedProxy)},
+			target:  "http://internal:8080/things/1",
+			header: http.Header{
+				"X-Forwarded-For":   {"198.51.100.17, 192.0.2.2"},
+				"X-Forwarded-Proto": {"https, http"},
+				"X-Forwarded-Host":  {"api.example.com, proxy"},
+			},
+			err:          notFound,
+			wantInstance: "https://api.example.com/things/1",
+		},
+		"the forwarded header takes precedence when trusted": {
+			options: []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL(trustedProxy)},
+			target:  "http://internal:8080/things/1",
+			header: http.Header{
+				"Forwarded":         {`for=198.51.100.17;proto=https;host="public.example.com"`},
+				"X-Forwarded-Proto": {"http"},
+				"X-Forwarded-Host":  {"api.example.com"},
+			},
+			err:          notFound,
+			wantInstance: "https://public.example.com/things/1",
+		},
+		"a forwarded element set by the client is ignored": {
+			options: []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL(trustedProxy)},
+			target:  "http://internal:8080/things/1",
+			header: http.Header{
+				"Forwarded": {`proto=https;host=evil.example.com, for=198.51.100.17;proto=http;host=public.example.com`},
+			},
+			err:          notFound,
+			wantInstance: "http://public.example.com/things/1",
+		},
+		"an unsupported forwarded scheme is ignored": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL(trustedProxy)},
+			target:       "http://internal:8080/things/1",
+			header:       http.Header{"X-Forwarded-Proto": {"javascript"}},
+			err:          notFound,
+			wantInstance: "http://internal:8080/things/1",
+		},
+		"an explicitly set instance is kept": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			target:       "http://example.com/things/1",
+			header:       http.Header{},
+			err:          func(r *http.Request) error { return problem.NotFound(r).WithInstance("urn:thing:1") },
+			wantInstance: "urn:thing:1",
+		},
+		"sentinel errors use the configured instance": {
+			options:      []httputil.ServerOption{httputil.WithServerProblemInstanceFullURL()},
+			target:       "http://example.com/things/1",
+			header:       http.Header{},
+			err:          func(_ *http.Request) error { return httputil.ErrForbidden },
+			wantInstance: "http://example.com/things/1",
+		},
+		"a custom function computes the instance": {
+			options: []httputil.ServerOption{httputil.WithServerProblemInstance(func(r *http.Request) string {
+				return "urn:request:" + r.Header.Get("X-Request-Id")
+			})},
+			target:       "http://example.com/things/1",
+			header:       http.Header{"X-Request-Id": {"abc"}},
+			err:          notFound,
+			wantInstance: "urn:request:abc",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			svr := httputil.NewServer(logger, testCase.options...)
+			svr.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/things/{id}",
+				Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+					return nil, testCase.err(r.Request)
+				}),
+			})
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			svr.ServeHTTP(response, request)
+
+			if got := decodeProblemInstance(t, response); got != testCase.wantInstance {
+				t.Errorf("instance = %q, want: %q", got, testCase.wantInstance)
+			}
+		})
+	}
+
+	t.Run("guard errors of net/http handlers use the configured instance", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger, httputil.WithServerProblemInstanceFullURL())
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/things",
+			Handler: httputil.WrapNetHTTPHandlerFunc(
+				func(_ http.ResponseWriter, _ *http.Request) {},
+				httputil.WithHandlerGuard(httputil.NewRequiredHeadersGuard("X-Api-Key")),
+			),
+		})
+
+		response := httptest.NewRecorder()
+		svr.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "http://example.com/things", nil))
+
+		if got, want := decodeProblemInstance(t, response), "http://example.com/things"; got != want {
+			t.Errorf("instance = %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("mapped panics use the configured instance", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(
+			logger,
+			httputil.WithServerProblemInstanceFullURL(),
+			httputil.WithServerPanicMapper(validationPanicMapper),
+		)
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/things",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(validationPanic("name is invalid"))
+			}),
+		})
+
+		response := httptest.NewRecorder()
+		svr.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "http://example.com/things", nil))
+
+		if got, want := decodeProblemInstance(t, response), "http://example.com/things"; got != want {
+			t.Errorf("instance = %q, want: %q", got, want)
+		}
+	})
+}
+
+// decodeProblemInstance decodes the instance member of the problem in the
+// response body.
+func decodeProblemInstance(t *testing.T, response *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Instance string `json:"instance"`
+	}
+
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error decoding problem %q: %v", response.Body.String(), err)
+	}
+
+	return body.Instance
+}
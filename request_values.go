@@ -0,0 +1,79 @@
+package httputil
+
+import (
+	"context"
+	"sync"
+)
+
+// Values is a request-scoped, string-keyed store for passing data between
+// guards, transformers, middleware and actions without defining a context key
+// for each value. Keys are not namespaced, so prefer context values with
+// unexported key types for data where collisions matter.
+//
+// A Values is created for each request served by a [Server] and is safe for
+// concurrent use.
+type Values struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+// valuesCtxKey is the context key for Values.
+type valuesCtxKey struct{}
+
+// newValuesContext returns a copy of ctx holding an empty Values.
+func newValuesContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, valuesCtxKey{}, &Values{mu: sync.RWMutex{}, values: nil})
+}
+
+// ValuesFromContext returns the Values for the request that ctx belongs to, or
+// nil if the request is not being served by a [Server]. Guards can use
+// ValuesFromContext(r.Context()) to set values that are later read by the
+// action.
+func ValuesFromContext(ctx context.Context) *Values {
+	v, _ := ctx.Value(valuesCtxKey{}).(*Values)
+	return v
+}
+
+// Get returns the value stored for key and whether it was present. Calling Get
+// on a nil Values reports that the value is not present.
+func (v *Values) Get(key string) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	val, ok := v.values[key]
+
+	return val, ok
+}
+
+// Set stores val for key, replacing any existing value. Calling Set on a nil
+// Values is a no-op.
+func (v *Values) Set(key string, val any) {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.values == nil {
+		v.values = make(map[string]any)
+	}
+
+	v.values[key] = val
+}
+
+// Get returns the request-scoped value stored for key and whether it was
+// present. See [Values] for details.
+func (r Request[D, P]) Get(key string) (any, bool) {
+	return ValuesFromContext(r.Context()).Get(key)
+}
+
+// Set stores a request-scoped value for key so that it can be read with
+// [Request.Get]. See [Values] for details.
+func (r Request[D, P]) Set(key string, val any) {
+	ValuesFromContext(r.Context()).Set(key, val)
+}
@@ -0,0 +1,108 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+)
+
+type tenantFromValuesRequest struct {
+	Name   string `json:"name"`
+	Tenant string `json:"-"`
+}
+
+func (t *tenantFromValuesRequest) Transform(ctx context.Context) error {
+	if tenant, ok := httputil.ValuesFromContext(ctx).Get("tenant"); ok {
+		t.Tenant, _ = tenant.(string)
+	}
+
+	return nil
+}
+
+func TestRequestValues(t *testing.T) {
+	t.Parallel()
+
+	setTenantGuard := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+		httputil.ValuesFromContext(r.Context()).Set("tenant", "acme")
+		return r, nil
+	})
+
+	testCases := map[string]struct {
+		handler          http.Handler
+		wantResponseBody string
+	}{
+		"a value set in a guard can be read in the action": {
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				tenant, ok := r.Get("tenant")
+				return httputil.OK(map[string]any{"tenant": tenant, "ok": ok})
+			}, httputil.WithHandlerGuard(setTenantGuard)),
+			wantResponseBody: `{"ok":true,"tenant":"acme"}`,
+		},
+		"a value set in a guard can be read in a transformer": {
+			handler: httputil.NewHandler(func(r httputil.RequestData[tenantFromValuesRequest]) (*httputil.Response, error) {
+				return httputil.OK(map[string]any{"name": r.Data.Name, "tenant": r.Data.Tenant})
+			}, httputil.WithHandlerGuard(setTenantGuard)),
+			wantResponseBody: `{"name":"test","tenant":"acme"}`,
+		},
+		"a value set in the action can be read back": {
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				r.Set("tenant", "other")
+				tenant, ok := r.Get("tenant")
+
+				return httputil.OK(map[string]any{"tenant": tenant, "ok": ok})
+			}),
+			wantResponseBody: `{"ok":true,"tenant":"other"}`,
+		},
+		"a value that has not been set is reported as not present": {
+			handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+				tenant, ok := r.Get("tenant")
+				return httputil.OK(map[string]any{"tenant": tenant, "ok": ok})
+			}),
+			wantResponseBody: `{"ok":false,"tenant":null}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{Method: http.MethodPost, Path: "/test", Handler: testCase.handler})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"test"}`)))
+
+			if response.Code != http.StatusOK {
+				t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusOK)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValuesFromContext(t *testing.T) {
+	t.Parallel()
+
+	values := httputil.ValuesFromContext(context.Background())
+	if values != nil {
+		t.Fatalf("ValuesFromContext() = %v, want: nil", values)
+	}
+
+	values.Set("key", "value")
+
+	if val, ok := values.Get("key"); ok || val != nil {
+		t.Errorf("values.Get() = %v, %t, want: nil, false", val, ok)
+	}
+}
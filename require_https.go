@@ -0,0 +1,77 @@
+package httputil
+
+import (
+	"net/http"
+	"net/netip"
+	"net/url"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+type (
+	// RequireHTTPSOption allows default [NewRequireHTTPSMiddleware] config values
+	// to be overridden.
+	RequireHTTPSOption func(o *requireHTTPSOptions)
+
+	requireHTTPSOptions struct {
+		reject         bool
+		trustedProxies []netip.Prefix
+	}
+)
+
+// WithRequireHTTPSReject makes the middleware reject plain HTTP requests with
+// a [problem.Forbidden] error instead of redirecting them to HTTPS.
+func WithRequireHTTPSReject() RequireHTTPSOption {
+	return func(o *requireHTTPSOptions) {
+		o.reject = true
+	}
+}
+
+// WithRequireHTTPSTrustedProxies sets the network prefixes of the proxies that
+// are trusted to report the scheme and host used by the client via the
+// Forwarded or X-Forwarded-Proto and X-Forwarded-Host headers. The headers are
+// ignored on requests from any other address. As proxies append to the
+// headers, the chain is walked back from the nearest proxy in the same way as
+// [ClientIP], and the values added by the last trusted proxy are used.
+func WithRequireHTTPSTrustedProxies(prefixes ...netip.Prefix) RequireHTTPSOption {
+	return func(o *requireHTTPSOptions) {
+		o.trustedProxies = append(o.trustedProxies, prefixes...)
+	}
+}
+
+// NewRequireHTTPSMiddleware creates a MiddlewareFunc that enforces the use of
+// HTTPS. Requests served over TLS, or forwarded by a trusted proxy that reports
+// the https scheme, are passed through. Other requests are redirected to the
+// same URL with the https scheme using a 308 Permanent Redirect, which
+// preserves the method and body, or rejected when [WithRequireHTTPSReject] is
+// used.
+//
+// Behind a TLS-terminating proxy, the address of the proxy must be trusted with
+// [WithRequireHTTPSTrustedProxies], otherwise every request is treated as plain
+// HTTP.
+func NewRequireHTTPSMiddleware(options ...RequireHTTPSOption) MiddlewareFunc {
+	opts := requireHTTPSOptions{reject: false, trustedProxies: nil}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, host := effectiveSchemeAndHost(r, opts.trustedProxies)
+			if scheme == "https" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if opts.reject {
+				writeMiddlewareError(w, r, problem.Forbidden(r).WithDetail("HTTPS is required to access this resource"))
+				return
+			}
+
+			//nolint:exhaustruct // Only the fields that make up the request URL are set.
+			target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+
+			http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewRequireHTTPSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	trustedProxies := httputil.WithRequireHTTPSTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8"))
+
+	testCases := map[string]struct {
+		options      []httputil.RequireHTTPSOption
+		target       string
+		remoteAddr   string
+		header       http.Header
+		wantStatus   int
+		wantLocation string
+	}{
+		"a tls request is passed through": {
+			options:      nil,
+			target:       "https://example.com/orders",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{},
+			wantStatus:   http.StatusOK,
+			wantLocation: "",
+		},
+		"a plain http request is redirected to https": {
+			options:      nil,
+			target:       "http://example.com/orders?page=2",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders?page=2",
+		},
+		"the forwarded proto is ignored from an untrusted address": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://example.com/orders",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders",
+		},
+		"the forwarded proto is ignored when no proxies are trusted": {
+			options:      nil,
+			target:       "http://example.com/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders",
+		},
+		"an https forwarded proto from a trusted proxy is passed through": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://internal/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}},
+			wantStatus:   http.StatusOK,
+			wantLocation: "",
+		},
+		"an https forwarded header from a trusted ipv6 proxy is passed through": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://internal/orders",
+			remoteAddr:   "[fd00::1]:1234",
+			header:       http.Header{"Forwarded": {"for=192.0.2.1;proto=https"}},
+			wantStatus:   http.StatusOK,
+			wantLocation: "",
+		},
+		"an http forwarded proto from a trusted proxy is redirected to the forwarded host": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://internal/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"X-Forwarded-Proto": {"http"}, "X-Forwarded-Host": {"api.example.com"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://api.example.com/orders",
+		},
+		"a spoofed x-forwarded proto before the trusted proxy's value is redirected": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://example.com/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"X-Forwarded-For": {"192.0.2.1"}, "X-Forwarded-Proto": {"https, http"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders",
+		},
+		"a spoofed first forwarded element is ignored": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://example.com/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"Forwarded": {"proto=https;host=evil.example.com, for=192.0.2.1;proto=http"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders",
+		},
+		"the element added by the proxy nearest the client is used in a chain of trusted proxies": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://internal/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"Forwarded": {"proto=http, for=192.0.2.1;proto=https, for=10.0.0.2;proto=http"}},
+			wantStatus:   http.StatusOK,
+			wantLocation: "",
+		},
+		"an invalid forwarded host is ignored": {
+			options:      []httputil.RequireHTTPSOption{trustedProxies},
+			target:       "http://example.com/orders",
+			remoteAddr:   "10.0.0.1:1234",
+			header:       http.Header{"X-Forwarded-Host": {"evil.example.com/phish?"}},
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://example.com/orders",
+		},
+		"a tls request is passed through in reject mode": {
+			options:      []httputil.RequireHTTPSOption{httputil.WithRequireHTTPSReject()},
+			target:       "https://example.com/orders",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{},
+			wantStatus:   http.StatusOK,
+			wantLocation: "",
+		},
+		"a plain http request is rejected in reject mode": {
+			options:      []httputil.RequireHTTPSOption{httputil.WithRequireHTTPSReject()},
+			target:       "http://example.com/orders",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{},
+			wantStatus:   http.StatusForbidden,
+			wantLocation: "",
+		},
+		"the forwarded proto from an untrusted address is rejected in reject mode": {
+			options:      []httputil.RequireHTTPSOption{httputil.WithRequireHTTPSReject(), trustedProxies},
+			target:       "http://example.com/orders",
+			remoteAddr:   "192.0.2.1:1234",
+			header:       http.Header{"X-Forwarded-Proto": {"https"}},
+			wantStatus:   http.StatusForbidden,
+			wantLocation: "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodPost, Path: "/orders", Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})},
+			}.WithMiddleware(httputil.NewRequireHTTPSMiddleware(testCase.options...))...)
+
+			request := httptest.NewRequest(http.MethodPost, testCase.target, nil)
+			request.RemoteAddr = testCase.remoteAddr
+			request.Header = testCase.header
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if got := response.Header().Get("Location"); got != testCase.wantLocation {
+				t.Errorf("Location = %q, want: %q", got, testCase.wantLocation)
+			}
+
+			if testCase.wantStatus == http.StatusForbidden {
+				want := problem.Forbidden(request).WithDetail("HTTPS is required to access this resource").MustMarshalJSONString()
+				if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
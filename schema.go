@@ -0,0 +1,102 @@
+package httputil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// schemaURL is the resource URL used when compiling a schema. Schemas are
+// compiled in isolation so the URL only needs to be stable.
+const schemaURL = "schema.json"
+
+// compileSchema compiles the given JSON Schema document so that it can be used
+// to validate request and response bodies.
+func compileSchema(schema []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("adding schema resource: %w", err)
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("compiling schema: %w", err)
+	}
+
+	return compiled, nil
+}
+
+// errSchemaUnmarshal is returned when a body is not valid JSON and therefore
+// cannot be validated against a schema.
+var errSchemaUnmarshal = errors.New("body is not valid JSON")
+
+// validateSchema validates body against schema and returns a problem.Property
+// for each violation. A nil slice is returned if the body is valid.
+func validateSchema(schema *jsonschema.Schema, body []byte) ([]problem.Property, error) {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errSchemaUnmarshal, err)
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+
+	validationErr, ok := errors.AsType[*jsonschema.ValidationError](err)
+	if !ok {
+		return nil, fmt.Errorf("validating schema: %w", err)
+	}
+
+	var properties []problem.Property
+
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+
+		properties = append(properties, describeSchemaError(unit.InstanceLocation, unit.Error.Kind, unit.Error.String())...)
+	}
+
+	return properties, nil
+}
+
+// describeSchemaError maps a single schema error to problem.Property entries.
+// Missing and additional properties are reported against the property itself
+// so that their pointers match those produced by struct validation.
+func describeSchemaError(location string, errKind jsonschema.ErrorKind, msg string) []problem.Property {
+	switch k := errKind.(type) {
+	case *kind.Required:
+		properties := make([]problem.Property, 0, len(k.Missing))
+		for _, name := range k.Missing {
+			properties = append(properties, problem.Property{Detail: "is required", Pointer: location + "/" + escapeJSONPointer(name)})
+		}
+
+		return properties
+	case *kind.AdditionalProperties:
+		properties := make([]problem.Property, 0, len(k.Properties))
+		for _, name := range k.Properties {
+			properties = append(properties, problem.Property{Detail: "is not allowed", Pointer: location + "/" + escapeJSONPointer(name)})
+		}
+
+		return properties
+	default:
+		return []problem.Property{{Detail: msg, Pointer: location}}
+	}
+}
+
+// escapeJSONPointer escapes a reference token as per RFC 6901.
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
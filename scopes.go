@@ -0,0 +1,49 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// scopesCtxKey is the context key for the scopes granted to a request.
+type scopesCtxKey struct{}
+
+// WithScopes returns a copy of ctx that carries the scopes granted to the
+// authenticated principal. Authentication guards call it with the scopes of
+// the credentials they verify, such as the space separated "scope" claim of a
+// token split with strings.Fields, so that [RequireScopes] can enforce them.
+func WithScopes(ctx context.Context, scopes ...string) context.Context {
+	return context.WithValue(ctx, scopesCtxKey{}, slices.Clone(scopes))
+}
+
+// ScopesFromContext returns the scopes stored in ctx by [WithScopes], or nil
+// if there are none.
+func ScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesCtxKey{}).([]string)
+	return slices.Clone(scopes)
+}
+
+// RequireScopes creates a Guard that ensures every one of scopes was granted
+// to the request with [WithScopes]. Requests that are missing a scope are
+// rejected with a [problem.Forbidden] error naming the required scopes. It
+// does not authenticate the request, so compose it after the authentication
+// guard in a [GuardStack]:
+//
+//	endpoints.WithGuard(httputil.GuardStack{authGuard, httputil.RequireScopes("orders:read")})
+func RequireScopes(scopes ...string) GuardFunc {
+	return func(r *http.Request) (*http.Request, error) {
+		granted := ScopesFromContext(r.Context())
+
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return nil, problem.Forbidden(r).WithDetail("The request requires the scopes: " + strings.Join(scopes, ", "))
+			}
+		}
+
+		return r, nil
+	}
+}
@@ -0,0 +1,134 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestRequireScopes(t *testing.T) {
+	t.Parallel()
+
+	// The authentication guard grants the scopes of each API key.
+	grants := map[string][]string{
+		"reader": {"orders:read"},
+		"admin":  {"orders:read", "orders:write"},
+	}
+
+	authGuard := httputil.NewAPIKeyGuard("X-API-Key", func(ctx context.Context, key string) (context.Context, error) {
+		scopes, ok := grants[key]
+		if !ok {
+			return nil, httputil.ErrAPIKeyInvalid
+		}
+
+		return httputil.WithScopes(ctx, scopes...), nil
+	})
+
+	testCases := map[string]struct {
+		required         []string
+		apiKey           string
+		wantStatus       int
+		wantResponseBody func(r *http.Request) string
+	}{
+		"a request with the required scope is allowed": {
+			required:   []string{"orders:read"},
+			apiKey:     "reader",
+			wantStatus: http.StatusOK,
+			wantResponseBody: func(*http.Request) string {
+				return `{"scopes":["orders:read"]}`
+			},
+		},
+		"a request with more than the required scopes is allowed": {
+			required:   []string{"orders:read", "orders:write"},
+			apiKey:     "admin",
+			wantStatus: http.StatusOK,
+			wantResponseBody: func(*http.Request) string {
+				return `{"scopes":["orders:read","orders:write"]}`
+			},
+		},
+		"a request without a required scope is forbidden": {
+			required:   []string{"orders:read", "orders:write"},
+			apiKey:     "reader",
+			wantStatus: http.StatusForbidden,
+			wantResponseBody: func(r *http.Request) string {
+				return problem.Forbidden(r).
+					WithDetail("The request requires the scopes: orders:read, orders:write").
+					MustMarshalJSONString()
+			},
+		},
+		"an unauthenticated request is rejected by the authentication guard first": {
+			required:   []string{"orders:read"},
+			apiKey:     "",
+			wantStatus: http.StatusUnauthorized,
+			wantResponseBody: func(r *http.Request) string {
+				return problem.Unauthorized(r).MustMarshalJSONString()
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.EndpointGroup{
+				{
+					Method: http.MethodGet,
+					Path:   "/orders",
+					Handler: httputil.NewHandler(func(r httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.OK(map[string][]string{"scopes": httputil.ScopesFromContext(r.Context())})
+					}),
+				},
+			}.WithGuard(httputil.GuardStack{authGuard, httputil.RequireScopes(testCase.required...)})...)
+
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if testCase.apiKey != "" {
+				request.Header.Set("X-API-Key", testCase.apiKey)
+			}
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody(request), response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScopesFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns nil when no scopes were granted", func(t *testing.T) {
+		t.Parallel()
+
+		if got := httputil.ScopesFromContext(context.Background()); got != nil {
+			t.Errorf("ScopesFromContext() = %v, want: nil", got)
+		}
+	})
+
+	t.Run("returns a copy of the granted scopes", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := httputil.WithScopes(context.Background(), "orders:read")
+		httputil.ScopesFromContext(ctx)[0] = "orders:write"
+
+		if diff := cmp.Diff([]string{"orders:read"}, httputil.ScopesFromContext(ctx)); diff != "" {
+			t.Errorf("ScopesFromContext() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
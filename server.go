@@ -5,8 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -19,13 +24,22 @@ type Server struct {
 		Shutdown(ctx context.Context) error
 	}
 
-	codec   ServerCodec
-	handler http.Handler
-	logger  *slog.Logger
-	router  *http.ServeMux
+	codec          ServerCodec
+	debugErrors    bool
+	defaultHeaders http.Header
+	errorMappers   []ErrorMapper
+	handler        http.Handler
+	logAttributes  LogAttributesFunc
+	logger         *slog.Logger
+	preflights     map[string]http.Handler
+	router         *http.ServeMux
 
-	address         string
-	shutdownTimeout time.Duration
+	problemInstance ProblemInstanceFunc
+
+	address            string
+	responseValidation responseValidationMode
+	shutdownTimeout    time.Duration
+	startedAt          time.Time
 }
 
 // NewServer creates a new Server instance with the specified logger and
@@ -37,19 +51,35 @@ func NewServer(logger *slog.Logger, options ...ServerOption) *Server {
 	router := http.NewServeMux()
 
 	server := &Server{
-		Listener: nil, // We need to set Listener after we have a server as we pass server as the handler.
-		logger:   logger,
-		router:   router,
-		// Build the middleware chain once at construction rather than per request.
-		handler: newPanicRecoveryMiddleware(logger)(
-			newMaxBodySizeMiddleware(logger, opts.maxBodySize)(
-				router,
+		Listener:           nil, // We need to set Listener after we have a server as we pass server as the handler.
+		logger:             logger,
+		preflights:         make(map[string]http.Handler),
+		router:             router,
+		handler:            nil, // We need to set handler after we have a server as it answers OPTIONS requests.
+		address:            opts.address,
+		codec:              opts.codec,
+		debugErrors:        opts.debugErrors,
+		defaultHeaders:     nil,
+		errorMappers:       opts.errorMappers,
+		logAttributes:      opts.logAttributes,
+		problemInstance:    opts.problemInstance,
+		responseValidation: opts.responseValidation,
+		shutdownTimeout:    opts.shutdownTimeout,
+		startedAt:          time.Now(),
+	}
+
+	// Build the middleware chain once at construction rather than per request.
+	server.handler = newPanicRecoveryMiddleware(logger, opts.codec, opts.panicMappers, opts.problemInstance, opts.logAttributes, opts.debugErrors)(
+		newMaxBodySizeMiddleware(logger, opts.maxBodySize, opts.logAttributes)(
+			newVerifyDigestMiddleware(logger, opts.verifyDigest, opts.logAttributes)(
+				newRequestDeadlineMiddleware(opts.requestDeadline)(
+					newExtensionNegotiationMiddleware(opts.extensionCodecs)(
+						server.newAutomaticOptionsHandler(router),
+					),
+				),
 			),
 		),
-		address:         opts.address,
-		codec:           opts.codec,
-		shutdownTimeout: opts.shutdownTimeout,
-	}
+	)
 
 	//nolint:exhaustruct // Accept defaults for fields we do not set.
 	server.Listener = &http.Server{
@@ -61,43 +91,247 @@ func NewServer(logger *slog.Logger, options ...ServerOption) *Server {
 		IdleTimeout:       opts.idleTimeout,
 		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
 		ErrorLog:          slog.NewLogLogger(netHTTPServerLogAdapter{Handler: logger.Handler()}, slog.LevelError),
+		Protocols:         serverProtocols(opts.h2c),
+		TLSConfig:         opts.tlsConfig,
 	}
 
 	return server
 }
 
+// serverProtocols returns the protocols accepted by the Server. A nil result
+// leaves the http.Server defaults of HTTP/1.1 and HTTP/2 over TLS in place.
+func serverProtocols(h2c bool) *http.Protocols {
+	if !h2c {
+		return nil
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return protocols
+}
+
 // Register one or more endpoints with the Server so they are handled by the
 // underlying router.
+//
+// Register panics with an [*ExampleError] if any of an endpoint's Examples do
+// not decode into the data type of its Handler. Like conflicting route
+// patterns, this is a programming error that should surface at startup. For
+// the same reason, Register panics with an [*EndpointError] wrapping
+// [ErrMissingDependency] if an endpoint's Handler requires a dependency, such
+// as a logger, that the Server cannot provide; see [DependentHandler].
+//
+// OPTIONS requests to a path with at least one registered endpoint are
+// answered automatically, unless an OPTIONS endpoint is registered for the
+// path. The response lists the registered methods in the Allow header. CORS
+// preflight requests are passed through the middleware applied with
+// [EndpointGroup.WithMiddleware] to the endpoint for the method in the
+// Access-Control-Request-Method header, so that CORS middleware does not
+// require an OPTIONS endpoint to be registered alongside each route.
 func (s *Server) Register(endpoints ...Endpoint) {
-	for _, endpoint := range endpoints {
+	for i, endpoint := range endpoints {
+		if err := s.validateExamples(endpoint); err != nil {
+			panic(err)
+		}
+
+		if err := s.validateDependencies(endpoint); err != nil {
+			panic(&EndpointError{Index: i, Method: endpoint.Method, Path: endpoint.Path, Err: err})
+		}
+
 		// Allocate hc outside the closure so each endpoint gets its own
 		// handlerContext at registration time (one allocation per
 		// endpoint, not per request).
 		hc := &handlerContext{
-			codec:  s.codec,
-			guard:  endpoint.guard,
-			logger: s.logger,
+			codec:              s.endpointCodec(endpoint),
+			debugErrors:        s.debugErrors,
+			errorMappers:       s.errorMappers,
+			guard:              endpoint.guard,
+			logAttributes:      s.logAttributes,
+			logger:             s.endpointLogger(endpoint),
+			problemInstance:    s.problemInstance,
+			responseValidation: s.responseValidation,
+		}
+
+		pattern := endpoint.Method + " " + endpoint.Path
+
+		s.router.Handle(pattern, withHandlerContext(hc, endpoint.Handler))
+
+		if endpoint.Method != http.MethodOptions {
+			s.preflights[pattern] = withHandlerContext(hc, newPreflightHandler(endpoint))
+		}
+	}
+}
+
+// withHandlerContext returns a handler that serves requests with next after
+// adding hc and the per request state used by handlers to the request context.
+func withHandlerContext(hc *handlerContext, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := newBodySizesContext(newValuesContext(context.WithValue(r.Context(), handlerCtxKey{}, hc)))
+		r = r.WithContext(ctx)
+
+		recordServedRequest(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RegisterGroup validates every endpoint in group and registers them with the
+// Server if they are all valid. Unlike [Server.Register], it does not panic on
+// the first invalid endpoint. Instead, it returns an error joining an
+// [*EndpointError] for each endpoint that:
+//
+//   - has a Method that is not defined by net/http ([ErrInvalidMethod]);
+//   - has a nil Handler ([ErrNilHandler]);
+//   - has the same Method and Path as an earlier endpoint in group
+//     ([ErrDuplicateEndpoint]);
+//   - has Examples that do not decode into the data type of its Handler
+//     ([*ExampleError]);
+//   - has a Handler that requires a dependency that the Server cannot
+//     provide ([ErrMissingDependency]).
+//
+// No endpoints are registered if an error is returned. Conflicts with endpoints
+// registered previously still cause a panic, as with [Server.Register].
+func (s *Server) RegisterGroup(group EndpointGroup) error {
+	var errs []error
+
+	seen := make(map[string]struct{}, len(group))
+
+	for i, endpoint := range group {
+		pattern := endpoint.Method + " " + endpoint.Path
+		_, duplicate := seen[pattern]
+
+		var err error
+
+		switch {
+		case !slices.Contains(httpMethods, endpoint.Method):
+			err = ErrInvalidMethod
+		case endpoint.Handler == nil:
+			err = ErrNilHandler
+		case duplicate:
+			err = ErrDuplicateEndpoint
+		default:
+			seen[pattern] = struct{}{}
+
+			if err = s.validateExamples(endpoint); err == nil {
+				err = s.validateDependencies(endpoint)
+			}
 		}
 
-		s.router.Handle(endpoint.Method+" "+endpoint.Path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := context.WithValue(r.Context(), handlerCtxKey{}, hc)
-			endpoint.Handler.ServeHTTP(w, r.WithContext(ctx))
-		}))
+		if err != nil {
+			errs = append(errs, &EndpointError{Index: i, Method: endpoint.Method, Path: endpoint.Path, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("registering endpoint group: %w", errors.Join(errs...))
 	}
+
+	s.Register(group...)
+
+	return nil
+}
+
+// httpMethods are the methods accepted by [Server.RegisterGroup].
+//
+//nolint:gochecknoglobals // Lookup table for the methods defined by net/http.
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// validateExamples checks each of the endpoint's examples against the data type
+// of its handler. Handlers that do not expose their data type (e.g. those
+// wrapped by middleware or net/http handlers) are not validated.
+func (s *Server) validateExamples(endpoint Endpoint) error {
+	validator, ok := endpoint.Handler.(exampleValidator)
+	if !ok {
+		return nil
+	}
+
+	for _, example := range endpoint.Examples {
+		if err := validator.validateExample(s.endpointCodec(endpoint), example); err != nil {
+			return &ExampleError{Method: endpoint.Method, Path: endpoint.Path, Name: example.Name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// endpointCodec returns the codec set on endpoint by [EndpointBuilder.Codec] or
+// [EndpointGroup.WithConfig], falling back to the codec of the Server.
+func (s *Server) endpointCodec(endpoint Endpoint) ServerCodec {
+	if endpoint.codec != nil {
+		return endpoint.codec
+	}
+
+	return s.codec
+}
+
+// endpointLogger returns the logger set on endpoint by
+// [EndpointGroup.WithConfig], falling back to the logger of the Server.
+func (s *Server) endpointLogger(endpoint Endpoint) *slog.Logger {
+	if endpoint.logger != nil {
+		return endpoint.logger
+	}
+
+	return s.logger
 }
 
 // Serve starts the HTTP server and listens for incoming requests. It gracefully
 // shuts down the server when it receives an SIGINT, SIGTERM, or SIGQUIT signal.
 func (s *Server) Serve(ctx context.Context) {
+	s.serve(ctx, serverListener{listen: s.listenAndServe, shutdown: s.Listener.Shutdown})
+}
+
+// ServeWithHTTPRedirect starts the server, as with [Server.Serve], along with
+// an HTTP listener on httpAddr that redirects every request to the same URL
+// with the https scheme using a 308 Permanent Redirect. The redirect targets
+// the port of the Server address, unless it is the default HTTPS port. Both
+// listeners are shut down gracefully together when the Server receives a
+// signal, ctx is canceled, or either of them fails.
+//
+// The Server is expected to serve HTTPS, see [WithServerTLSConfig].
+func (s *Server) ServeWithHTTPRedirect(ctx context.Context, httpAddr string) {
+	redirect := s.newRedirectServer(httpAddr)
+
+	s.logger.InfoContext(ctx, "Server redirecting HTTP to HTTPS", slog.String("address", httpAddr))
+
+	s.serve(ctx,
+		serverListener{listen: s.listenAndServe, shutdown: s.Listener.Shutdown},
+		serverListener{listen: redirect.ListenAndServe, shutdown: redirect.Shutdown},
+	)
+}
+
+// serverListener is a listener run by [Server.serve].
+type serverListener struct {
+	listen   func() error
+	shutdown func(ctx context.Context) error
+}
+
+// serve runs listeners until the Server receives a signal, ctx is canceled, or
+// one of the listeners fails, and then shuts them all down gracefully within
+// the shutdown timeout.
+func (s *Server) serve(ctx context.Context, listeners ...serverListener) {
 	awaitSignalCtx, cancelAwaitSignal := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer cancelAwaitSignal()
 
-	go func() {
-		defer cancelAwaitSignal()
+	for _, listener := range listeners {
+		go func() {
+			defer cancelAwaitSignal()
 
-		if err := s.Listener.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			s.logger.ErrorContext(ctx, "Server failed to listen and serve", slog.Any("error", err))
-		}
-	}()
+			if err := listener.listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.ErrorContext(ctx, "Server failed to listen and serve", slog.Any("error", err))
+			}
+		}()
+	}
 
 	s.logger.InfoContext(ctx, "Server started", slog.String("address", s.address))
 	<-awaitSignalCtx.Done()
@@ -111,17 +345,106 @@ func (s *Server) Serve(ctx context.Context) {
 	defer cancelShutdown()
 
 	// Calling Shutdown causes ListenAndServe to return ErrServerClosed immediately.
-	// Shutdown then takes over and handles graceful shutdown.
-	if err := s.Listener.Shutdown(shutdownCtx); err != nil { //nolint:contextcheck // False positive.
-		s.logger.ErrorContext(ctx, "Server failed to shutdown gracefully", slog.Any("error", err))
+	// Shutdown then takes over and handles graceful shutdown. Listeners are shut
+	// down concurrently so that they share the shutdown timeout.
+	var wg sync.WaitGroup
+
+	for _, listener := range listeners {
+		wg.Go(func() {
+			if err := listener.shutdown(shutdownCtx); err != nil { //nolint:contextcheck // False positive.
+				s.logger.ErrorContext(ctx, "Server failed to shutdown gracefully", slog.Any("error", err))
+			}
+		})
 	}
 
+	wg.Wait()
+
 	s.logger.InfoContext(ctx, "Server shutdown")
 }
 
+// redirectReadHeaderTimeout is the ReadHeaderTimeout of the redirect listener
+// when the Listener is not a *http.Server to share timeouts with.
+const redirectReadHeaderTimeout = 5 * time.Second
+
+// newRedirectServer returns the *http.Server used by
+// [Server.ServeWithHTTPRedirect] to redirect requests on httpAddr to HTTPS. It
+// shares the timeouts of the Listener when it is a *http.Server.
+func (s *Server) newRedirectServer(httpAddr string) *http.Server {
+	//nolint:exhaustruct // Accept defaults for fields we do not set.
+	redirect := &http.Server{
+		Addr:              httpAddr,
+		Handler:           s.httpsRedirectHandler(),
+		ReadHeaderTimeout: redirectReadHeaderTimeout,
+		ErrorLog:          slog.NewLogLogger(netHTTPServerLogAdapter{Handler: s.logger.Handler()}, slog.LevelError),
+	}
+
+	if srv, ok := s.Listener.(*http.Server); ok {
+		redirect.ReadTimeout = srv.ReadTimeout
+		redirect.ReadHeaderTimeout = srv.ReadHeaderTimeout
+		redirect.WriteTimeout = srv.WriteTimeout
+		redirect.IdleTimeout = srv.IdleTimeout
+	}
+
+	return redirect
+}
+
+// httpsRedirectHandler returns a handler that redirects requests to the same
+// URL with the https scheme and the port of the Server address.
+func (s *Server) httpsRedirectHandler() http.Handler {
+	_, port, err := net.SplitHostPort(s.address)
+	if err != nil || port == "443" {
+		port = ""
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+
+		if port != "" {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+
+		//nolint:exhaustruct // Only the fields that make up the request URL are set.
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
+
+// listenAndServe starts the Listener, serving HTTPS when it is a *http.Server
+// with a TLS config set by [WithServerTLSConfig].
+func (s *Server) listenAndServe() error {
+	if srv, ok := s.Listener.(*http.Server); ok && srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "") //nolint:wrapcheck // The error is logged by Serve.
+	}
+
+	return s.Listener.ListenAndServe() //nolint:wrapcheck // The error is logged by Serve.
+}
+
+// SetDefaultHeaders sets headers that are added to every response written by
+// the Server, such as X-Service-Version. The headers are set before the request
+// is handled, so values set by handlers and middleware replace them. Header
+// names are canonicalized and header is copied, so later changes to it have no
+// effect.
+//
+// SetDefaultHeaders is not safe to call concurrently with requests being
+// served; call it before [Server.Serve].
+func (s *Server) SetDefaultHeaders(header http.Header) {
+	s.defaultHeaders = make(http.Header, len(header))
+	for name, values := range header {
+		s.defaultHeaders[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+	}
+}
+
 // ServeHTTP delegates the request handling to the underlying router. Exposing
 // ServeHTTP allows endpoints to be tested without a running server.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for name, values := range s.defaultHeaders {
+		w.Header()[name] = slices.Clone(values)
+	}
+
 	s.handler.ServeHTTP(w, r)
 }
 
@@ -1,18 +1,22 @@
 package httputil_test
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"testing"
+	"testing/synctest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -22,6 +26,8 @@ import (
 	"github.com/nickbryan/slogutil/slogmem"
 
 	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
 )
 
 //nolint:paralleltest // These test do not run in parallel due to how signal notifications are handled and tested.
@@ -181,14 +187,144 @@ func TestServer_Serve(t *testing.T) {
 			}
 
 			for _, query := range testCase.wantLogs {
-				if ok, diff := logs.Contains(query); !ok {
-					t.Errorf("logs does not contain query, want: %+v, got:\n%s", query, diff)
-				}
+				testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
 			}
 		})
 	}
 }
 
+//nolint:paralleltest // This test does not run in parallel due to how signal notifications are handled and tested.
+func TestServer_ServeWithHTTPRedirect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error reserving an address: %s", err.Error())
+	}
+
+	httpAddr := listener.Addr().String()
+
+	if err = listener.Close(); err != nil {
+		t.Fatalf("unexpected error releasing the address: %s", err.Error())
+	}
+
+	logger, logs := slogutil.NewInMemoryLogger(slog.LevelDebug)
+	server := httputil.NewServer(logger, httputil.WithServerAddress(":8443"), httputil.WithServerShutdownTimeout(time.Second))
+
+	httpsListener := &fakeListener{
+		listenAndServeErr: nil,
+		shutdownErr:       nil,
+		connCloseDuration: 0,
+		listenChan:        make(chan any),
+	}
+	server.Listener = httpsListener
+
+	served := make(chan struct{})
+
+	go func() {
+		defer close(served)
+		server.ServeWithHTTPRedirect(t.Context(), httpAddr)
+	}()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	testCases := map[string]struct {
+		host         string
+		target       string
+		wantLocation string
+	}{
+		"redirects to the https port of the server": {
+			host:         "example.com",
+			target:       "/orders?page=2",
+			wantLocation: "https://example.com:8443/orders?page=2",
+		},
+		"replaces the port of the request host": {
+			host:         "example.com:80",
+			target:       "/orders/1",
+			wantLocation: "https://example.com:8443/orders/1",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://"+httpAddr+testCase.target, nil)
+			if err != nil {
+				t.Fatalf("unexpected error creating request: %s", err.Error())
+			}
+
+			request.Host = testCase.host
+
+			response := awaitResponse(t, client, request)
+			defer response.Body.Close()
+
+			if response.StatusCode != http.StatusPermanentRedirect {
+				t.Errorf("response.StatusCode = %d, want: %d", response.StatusCode, http.StatusPermanentRedirect)
+			}
+
+			if got := response.Header.Get("Location"); got != testCase.wantLocation {
+				t.Errorf("Location = %q, want: %q", got, testCase.wantLocation)
+			}
+		})
+	}
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error finding process: %s", err.Error())
+	}
+
+	if err = proc.Signal(syscall.SIGINT); err != nil {
+		t.Fatalf("unexpected error sending signal: %s", err.Error())
+	}
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeWithHTTPRedirect did not return after receiving SIGINT")
+	}
+
+	select {
+	case <-httpsListener.listenChan:
+	default:
+		t.Error("the https listener was not shut down")
+	}
+
+	if conn, err := net.Dial("tcp", httpAddr); err == nil {
+		_ = conn.Close()
+
+		t.Error("the http listener was not shut down")
+	}
+
+	for _, query := range []slogmem.RecordQuery{
+		{Level: slog.LevelInfo, Message: "Server redirecting HTTP to HTTPS", Attrs: map[string]slog.Value{"address": slog.StringValue(httpAddr)}},
+		{Level: slog.LevelInfo, Message: "Server started", Attrs: map[string]slog.Value{"address": slog.StringValue(":8443")}},
+		{Level: slog.LevelInfo, Message: "Server shutting down", Attrs: map[string]slog.Value{"reason": slog.AnyValue("interrupt signal received")}},
+		{Level: slog.LevelInfo, Message: "Server shutdown", Attrs: nil},
+	} {
+		testutil.AssertLog(t, logs, query.Level, query.Message, query.Attrs)
+	}
+}
+
+// awaitResponse sends request with client, retrying until the server has
+// started listening.
+func awaitResponse(t *testing.T, client *http.Client, request *http.Request) *http.Response {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		response, err := client.Do(request)
+		if err == nil {
+			return response
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected error sending request: %s", err.Error())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestServer_ServeHTTP(t *testing.T) {
 	t.Parallel()
 
@@ -228,6 +364,113 @@ func TestServer_ServeHTTP(t *testing.T) {
 		}
 	})
 
+	t.Run("maps a recognised panic value to a problem", func(t *testing.T) {
+		t.Parallel()
+
+		logger, records := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger, httputil.WithServerPanicMapper(validationPanicMapper))
+
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(validationPanic("name is invalid"))
+			}),
+		})
+
+		svr.ServeHTTP(response, request)
+
+		if response.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("unexpected status code, want: %d, got: %d", http.StatusBadRequest, response.Result().StatusCode)
+		}
+
+		want := problem.BadRequest(request).WithDetail("name is invalid").MustMarshalJSONString()
+		if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+			t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+		}
+
+		query := slogmem.RecordQuery{
+			Level:   slog.LevelWarn,
+			Message: "Handler panicked with a mapped value",
+			Attrs: map[string]slog.Value{
+				"error": slog.AnyValue(validationPanic("name is invalid")),
+			},
+		}
+
+		if ok, diff := records.Contains(query); !ok {
+			t.Errorf("logs does not contain query, diff:\n%s", diff)
+		}
+	})
+
+	t.Run("encodes a mapped panic with the codec selected for the request", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(
+			logger,
+			httputil.WithServerPanicMapper(validationPanicMapper),
+			httputil.WithServerExtensionNegotiation(map[string]httputil.ServerCodec{"xml": xmlServerCodec{}}),
+		)
+
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/report",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(validationPanic("name is invalid"))
+			}),
+		})
+
+		response := httptest.NewRecorder()
+		svr.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/report.xml", nil))
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+
+		if got, want := response.Header().Get("Content-Type"), "application/problem+xml"; got != want {
+			t.Errorf("Content-Type = %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("recovers from an unrecognised panic value with a server error", func(t *testing.T) {
+		t.Parallel()
+
+		logger, records := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger, httputil.WithServerPanicMapper(validationPanicMapper))
+
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic("panic from handler")
+			}),
+		})
+
+		svr.ServeHTTP(response, request)
+
+		if response.Result().StatusCode != http.StatusInternalServerError {
+			t.Errorf("unexpected status code, want: %d, got: %d", http.StatusInternalServerError, response.Result().StatusCode)
+		}
+
+		query := slogmem.RecordQuery{
+			Level:   slog.LevelError,
+			Message: "Handler panicked",
+			Attrs: map[string]slog.Value{
+				"error": slog.AnyValue("panic from handler"),
+			},
+		}
+
+		if ok, diff := records.Contains(query); !ok {
+			t.Errorf("logs does not contain query, diff:\n%s", diff)
+		}
+	})
+
 	t.Run("limits the request body", func(t *testing.T) {
 		t.Parallel()
 
@@ -267,6 +510,689 @@ func TestServer_ServeHTTP(t *testing.T) {
 	})
 }
 
+func TestServer_ExpectContinue(t *testing.T) {
+	t.Parallel()
+
+	// sendHeaders writes the header of a request expecting 100-continue with
+	// the given Content-Length to server and returns the first response read,
+	// without sending the body.
+	sendHeaders := func(t *testing.T, server *httptest.Server, contentLength int) (*http.Response, net.Conn) {
+		t.Helper()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("unexpected error dialing server: %v", err)
+		}
+
+		t.Cleanup(func() { _ = conn.Close() })
+
+		_, err = fmt.Fprintf(conn, "POST /upload HTTP/1.1\r\nHost: example.com\r\nExpect: 100-continue\r\nContent-Length: %d\r\n\r\n", contentLength)
+		if err != nil {
+			t.Fatalf("unexpected error writing request header: %v", err)
+		}
+
+		response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatalf("unexpected error reading response: %v", err)
+		}
+
+		t.Cleanup(func() { _ = response.Body.Close() })
+
+		return response, conn
+	}
+
+	newServer := func(t *testing.T, bodyRead *atomic.Bool) *httptest.Server {
+		t.Helper()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger, httputil.WithServerMaxBodySize(10))
+		svr.Register(httputil.Endpoint{
+			Method: http.MethodPost,
+			Path:   "/upload",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bodyRead.Store(true)
+
+				if _, err := io.ReadAll(r.Body); err != nil {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		})
+
+		server := httptest.NewServer(svr)
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	t.Run("an oversized upload is rejected before the client is told to continue", func(t *testing.T) {
+		t.Parallel()
+
+		var bodyRead atomic.Bool
+
+		response, _ := sendHeaders(t, newServer(t, &bodyRead), 1024)
+
+		if response.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("response.StatusCode = %d, want: %d", response.StatusCode, http.StatusRequestEntityTooLarge)
+		}
+
+		if bodyRead.Load() {
+			t.Error("handler was called, want the request to be rejected before the body is read")
+		}
+	})
+
+	t.Run("an upload within the limit is told to continue", func(t *testing.T) {
+		t.Parallel()
+
+		var bodyRead atomic.Bool
+
+		response, conn := sendHeaders(t, newServer(t, &bodyRead), 5)
+
+		if response.StatusCode != http.StatusContinue {
+			t.Fatalf("response.StatusCode = %d, want: %d", response.StatusCode, http.StatusContinue)
+		}
+
+		if _, err := conn.Write([]byte("hello")); err != nil {
+			t.Fatalf("unexpected error writing request body: %v", err)
+		}
+
+		final, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatalf("unexpected error reading response: %v", err)
+		}
+
+		_ = final.Body.Close()
+
+		if final.StatusCode != http.StatusNoContent {
+			t.Errorf("final.StatusCode = %d, want: %d", final.StatusCode, http.StatusNoContent)
+		}
+	})
+
+	t.Run("an unsupported expectation is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		logger, records := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger)
+		svr.Register(httputil.Endpoint{
+			Method:  http.MethodPost,
+			Path:    "/upload",
+			Handler: httputil.WrapNetHTTPHandlerFunc(func(http.ResponseWriter, *http.Request) { t.Error("handler was called") }),
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("hello"))
+		request.Header.Set("Expect", "200-ok")
+
+		response := httptest.NewRecorder()
+		svr.ServeHTTP(response, request)
+
+		if response.Code != http.StatusExpectationFailed {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusExpectationFailed)
+		}
+
+		testutil.AssertLog(t, records, slog.LevelWarn, "Request has an unsupported expectation", map[string]slog.Value{
+			"expect": slog.StringValue("200-ok"),
+		})
+	})
+}
+
+type validationPanic string
+
+func validationPanicMapper(r *http.Request, recovered any) *problem.DetailedError {
+	if v, ok := recovered.(validationPanic); ok {
+		return problem.BadRequest(r).WithDetail(string(v))
+	}
+
+	return nil
+}
+
+func TestServer_RequestDeadlineHeader(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		headerName   string
+		header       http.Header
+		wantDeadline time.Duration
+		wantOK       bool
+	}{
+		"a request without the header has no deadline": {
+			headerName:   "X-Request-Timeout",
+			header:       http.Header{},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+		"a number of seconds sets the deadline": {
+			headerName:   "X-Request-Timeout",
+			header:       http.Header{"X-Request-Timeout": {"30"}},
+			wantDeadline: 30 * time.Second,
+			wantOK:       true,
+		},
+		"a duration string sets the deadline": {
+			headerName:   "X-Request-Timeout",
+			header:       http.Header{"X-Request-Timeout": {"1.5s"}},
+			wantDeadline: 1500 * time.Millisecond,
+			wantOK:       true,
+		},
+		"an invalid value sets no deadline": {
+			headerName:   "X-Request-Timeout",
+			header:       http.Header{"X-Request-Timeout": {"soon"}},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+		"a non-positive value sets no deadline": {
+			headerName:   "X-Request-Timeout",
+			header:       http.Header{"X-Request-Timeout": {"-5s"}},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+		"a grpc-timeout in milliseconds sets the deadline": {
+			headerName:   "grpc-timeout",
+			header:       http.Header{"Grpc-Timeout": {"250m"}},
+			wantDeadline: 250 * time.Millisecond,
+			wantOK:       true,
+		},
+		"a grpc-timeout in hours sets the deadline": {
+			headerName:   "grpc-timeout",
+			header:       http.Header{"Grpc-Timeout": {"2H"}},
+			wantDeadline: 2 * time.Hour,
+			wantOK:       true,
+		},
+		"a grpc-timeout with an unknown unit sets no deadline": {
+			headerName:   "grpc-timeout",
+			header:       http.Header{"Grpc-Timeout": {"250x"}},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+		"a grpc-timeout with too many digits sets no deadline": {
+			headerName:   "grpc-timeout",
+			header:       http.Header{"Grpc-Timeout": {"123456789S"}},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+		"the header is ignored when the option is not set": {
+			headerName:   "",
+			header:       http.Header{"X-Request-Timeout": {"30"}},
+			wantDeadline: 0,
+			wantOK:       false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			synctest.Test(t, func(t *testing.T) {
+				logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+				svr := httputil.NewServer(logger, httputil.WithServerRequestDeadlineHeader(testCase.headerName))
+
+				var (
+					gotDeadline time.Time
+					gotOK       bool
+				)
+
+				svr.Register(httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/",
+					Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+						gotDeadline, gotOK = r.Context().Deadline()
+					}),
+				})
+
+				request := httptest.NewRequest(http.MethodGet, "/", nil)
+				request.Header = testCase.header
+
+				svr.ServeHTTP(httptest.NewRecorder(), request)
+
+				if gotOK != testCase.wantOK {
+					t.Fatalf("context has deadline = %t, want: %t", gotOK, testCase.wantOK)
+				}
+
+				if want := time.Now().Add(testCase.wantDeadline); gotOK && !gotDeadline.Equal(want) {
+					t.Errorf("context deadline = %s, want: %s", gotDeadline, want)
+				}
+			})
+		})
+	}
+}
+
+func TestServer_Register(t *testing.T) {
+	t.Parallel()
+
+	type exampleData struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	action := func(_ httputil.RequestData[exampleData]) (*httputil.Response, error) {
+		return httputil.NoContent()
+	}
+
+	testCases := map[string]struct {
+		endpoint  httputil.Endpoint
+		wantPanic bool
+	}{
+		"registration succeeds when the example request decodes into the data type": {
+			endpoint: httputil.Endpoint{
+				Method:  http.MethodPost,
+				Path:    "/test",
+				Handler: httputil.NewHandler(action),
+				Examples: []httputil.Example{
+					{Name: "valid", Request: []byte(`{"name":"test"}`), Response: nil, StatusCode: http.StatusNoContent},
+				},
+			},
+			wantPanic: false,
+		},
+		"registration panics when the example request does not decode into the data type": {
+			endpoint: httputil.Endpoint{
+				Method:  http.MethodPost,
+				Path:    "/test",
+				Handler: httputil.NewHandler(action),
+				Examples: []httputil.Example{
+					{Name: "invalid", Request: []byte(`{"name":123}`), Response: nil, StatusCode: http.StatusNoContent},
+				},
+			},
+			wantPanic: true,
+		},
+		"registration panics when the example request fails validation": {
+			endpoint: httputil.Endpoint{
+				Method:  http.MethodPost,
+				Path:    "/test",
+				Handler: httputil.NewHandler(action),
+				Examples: []httputil.Example{
+					{Name: "stale", Request: []byte(`{"title":"test"}`), Response: nil, StatusCode: http.StatusNoContent},
+				},
+			},
+			wantPanic: true,
+		},
+		"registration panics when the example has a request but the handler expects no data": {
+			endpoint: httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/test",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.NoContent()
+				}),
+				Examples: []httputil.Example{
+					{Name: "unexpected", Request: []byte(`{"name":"test"}`), Response: nil, StatusCode: http.StatusNoContent},
+				},
+			},
+			wantPanic: true,
+		},
+		"registration does not validate examples for handlers that hide their data type": {
+			endpoint: httputil.Endpoint{
+				Method:  http.MethodPost,
+				Path:    "/test",
+				Handler: httputil.WrapNetHTTPHandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}),
+				Examples: []httputil.Example{
+					{Name: "unchecked", Request: []byte(`not json`), Response: nil, StatusCode: http.StatusOK},
+				},
+			},
+			wantPanic: false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			defer func() {
+				r := recover()
+				if (r != nil) != testCase.wantPanic {
+					t.Fatalf("unexpected panic state, want panic: %t, got: %v", testCase.wantPanic, r)
+				}
+
+				if r == nil {
+					return
+				}
+
+				err, ok := r.(error)
+				if !ok {
+					t.Fatalf("panic value is not an error: %v", r)
+				}
+
+				if _, ok := errors.AsType[*httputil.ExampleError](err); !ok {
+					t.Errorf("panic value is not an *httputil.ExampleError: %T", err)
+				}
+			}()
+
+			server.Register(testCase.endpoint)
+		})
+	}
+}
+
+func TestServer_RegisterGroup(t *testing.T) {
+	t.Parallel()
+
+	noContent := httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+		return httputil.NoContent()
+	})
+
+	t.Run("registers every endpoint when the group is valid", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger)
+
+		err := svr.RegisterGroup(httputil.EndpointGroup{
+			{Method: http.MethodGet, Path: "/users", Handler: noContent},
+			{Method: http.MethodPost, Path: "/users", Handler: noContent},
+		})
+		if err != nil {
+			t.Fatalf("RegisterGroup() error = %v, want: nil", err)
+		}
+
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			response := httptest.NewRecorder()
+			svr.ServeHTTP(response, httptest.NewRequest(method, "/users", nil))
+
+			if response.Code != http.StatusNoContent {
+				t.Errorf("%s /users response.Code = %d, want: %d", method, response.Code, http.StatusNoContent)
+			}
+		}
+	})
+
+	t.Run("reports every invalid endpoint and registers none", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger)
+
+		err := svr.RegisterGroup(httputil.EndpointGroup{
+			{Method: http.MethodGet, Path: "/users", Handler: noContent},
+			{Method: "FETCH", Path: "/users", Handler: noContent},
+			{Method: http.MethodPost, Path: "/users", Handler: nil},
+			{Method: http.MethodGet, Path: "/users", Handler: noContent},
+		})
+
+		want := []httputil.EndpointError{
+			{Index: 1, Method: "FETCH", Path: "/users", Err: httputil.ErrInvalidMethod},
+			{Index: 2, Method: http.MethodPost, Path: "/users", Err: httputil.ErrNilHandler},
+			{Index: 3, Method: http.MethodGet, Path: "/users", Err: httputil.ErrDuplicateEndpoint},
+		}
+
+		for _, wantErr := range want {
+			if !errors.Is(err, wantErr.Err) {
+				t.Errorf("RegisterGroup() error = %v, want it to wrap: %v", err, wantErr.Err)
+			}
+
+			if err == nil || !strings.Contains(err.Error(), wantErr.Error()) {
+				t.Errorf("RegisterGroup() error = %v, want it to contain: %q", err, wantErr.Error())
+			}
+		}
+
+		response := httptest.NewRecorder()
+		svr.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("response.Code = %d, want: %d", response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("reports invalid examples", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		svr := httputil.NewServer(logger)
+
+		err := svr.RegisterGroup(httputil.EndpointGroup{
+			{
+				Method:  http.MethodPost,
+				Path:    "/users",
+				Handler: noContent,
+				Examples: []httputil.Example{
+					{Name: "unexpected", Request: []byte(`{"name":"test"}`), Response: nil, StatusCode: http.StatusNoContent},
+				},
+			},
+		})
+
+		endpointErr, ok := errors.AsType[*httputil.EndpointError](err)
+		if !ok {
+			t.Fatalf("RegisterGroup() error = %v, want an *httputil.EndpointError", err)
+		}
+
+		if _, ok := errors.AsType[*httputil.ExampleError](endpointErr); !ok {
+			t.Errorf("EndpointError.Err = %v, want an *httputil.ExampleError", endpointErr.Err)
+		}
+	})
+}
+
+func TestServer_RegisterDependencies(t *testing.T) {
+	t.Parallel()
+
+	newNoContent := func(options ...httputil.HandlerOption) http.Handler {
+		return httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+			return httputil.NoContent()
+		}, options...)
+	}
+	raw := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	passThrough := func(next http.Handler) http.Handler { return next }
+	withoutCodec := []httputil.ServerOption{httputil.WithServerCodec(nil)}
+
+	testCases := map[string]struct {
+		options []httputil.ServerOption
+		group   func() httputil.EndpointGroup
+		wantErr string
+	}{
+		"a handler without a codec is flagged": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: newNoContent()}}
+			},
+			wantErr: "endpoint 0 (GET /orders): missing dependency: codec",
+		},
+		"a handler without a codec behind middleware is flagged": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: newNoContent()}}.WithMiddleware(passThrough)
+			},
+			wantErr: "endpoint 0 (GET /orders): missing dependency: codec",
+		},
+		"a handler marked as requiring a codec is flagged": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: httputil.RequireDependencies(raw, httputil.DependencyCodec)}}
+			},
+			wantErr: "endpoint 0 (GET /orders): missing dependency: codec",
+		},
+		"a handler with its own codec is accepted": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: newNoContent(httputil.WithHandlerCodec(httputil.NewJSONServerCodec()))}}
+			},
+			wantErr: "",
+		},
+		"a handler given a codec by its group is accepted": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: newNoContent()}}.
+					WithConfig(httputil.GroupConfig{Logger: nil, Codec: httputil.NewJSONServerCodec(), Guard: nil})
+			},
+			wantErr: "",
+		},
+		"a wrapped net/http handler does not require a codec": {
+			options: withoutCodec,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: httputil.WrapNetHTTPHandler(raw)}}
+			},
+			wantErr: "",
+		},
+		"a handler marked as requiring a logger is given the server logger": {
+			options: nil,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: httputil.RequireDependencies(raw, httputil.DependencyLogger)}}
+			},
+			wantErr: "",
+		},
+		"a handler on a server with a codec is accepted": {
+			options: nil,
+			group: func() httputil.EndpointGroup {
+				return httputil.EndpointGroup{{Method: http.MethodGet, Path: "/orders", Handler: newNoContent()}}
+			},
+			wantErr: "",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			err := httputil.NewServer(logger, testCase.options...).RegisterGroup(testCase.group())
+
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("RegisterGroup() error = %v, want: nil", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, httputil.ErrMissingDependency) {
+				t.Errorf("RegisterGroup() error = %v, want it to wrap: %v", err, httputil.ErrMissingDependency)
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("RegisterGroup() error = %v, want it to contain: %q", err, testCase.wantErr)
+			}
+		})
+	}
+
+	t.Run("register panics for a handler without a codec", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			err, ok := recover().(error)
+			if !ok || !errors.Is(err, httputil.ErrMissingDependency) {
+				t.Errorf("Register() panic = %v, want an error wrapping: %v", err, httputil.ErrMissingDependency)
+			}
+		}()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		httputil.NewServer(logger, withoutCodec...).Register(httputil.Endpoint{Method: http.MethodGet, Path: "/orders", Handler: newNoContent()})
+	})
+}
+
+func TestServer_SetDefaultHeaders(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		path       string
+		wantHeader http.Header
+	}{
+		"defaults are added to handler responses": {
+			path: "/plain",
+			wantHeader: http.Header{
+				"X-Service-Version":      {"1.2.3"},
+				"X-Content-Type-Options": {"nosniff"},
+				"Vary":                   {"Accept"},
+			},
+		},
+		"defaults are replaced by values set by the handler": {
+			path: "/override",
+			wantHeader: http.Header{
+				"X-Service-Version":      {"2.0.0"},
+				"X-Content-Type-Options": {"nosniff"},
+				"Vary":                   {"Accept", "Origin"},
+			},
+		},
+		"defaults are added to problem responses": {
+			path: "/problem",
+			wantHeader: http.Header{
+				"X-Service-Version":      {"1.2.3"},
+				"X-Content-Type-Options": {"nosniff"},
+				"Vary":                   {"Accept"},
+			},
+		},
+		"defaults are added to responses for unknown routes": {
+			path: "/unknown",
+			wantHeader: http.Header{
+				"X-Service-Version":      {"1.2.3"},
+				"X-Content-Type-Options": {"nosniff"},
+				"Vary":                   {"Accept"},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			defaults := http.Header{
+				"x-service-version":      {"1.2.3"},
+				"X-Content-Type-Options": {"nosniff"},
+				"Vary":                   {"Accept"},
+			}
+			server.SetDefaultHeaders(defaults)
+
+			// Changes after SetDefaultHeaders returns must not leak into responses.
+			defaults.Set("X-Content-Type-Options", "changed")
+
+			server.Register(
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/plain",
+					Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.NoContent()
+					}),
+				},
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/override",
+					Handler: httputil.WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+						w.Header().Set("X-Service-Version", "2.0.0")
+						w.Header().Add("Vary", "Origin")
+						w.WriteHeader(http.StatusNoContent)
+					}),
+				},
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/problem",
+					Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return nil, httputil.ErrNotFound
+					}),
+				},
+			)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+			for name := range testCase.wantHeader {
+				if diff := cmp.Diff(testCase.wantHeader.Values(name), response.Header().Values(name)); diff != "" {
+					t.Errorf("response.Header()[%q] mismatch (-want +got):\n%s", name, diff)
+				}
+			}
+		})
+	}
+
+	t.Run("responses are unchanged without defaults", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+		server := httputil.NewServer(logger)
+		server.Register(httputil.Endpoint{
+			Method: http.MethodGet,
+			Path:   "/plain",
+			Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				return httputil.NoContent()
+			}),
+		})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/plain", nil))
+
+		if got := response.Header().Get("X-Service-Version"); got != "" {
+			t.Errorf("X-Service-Version = %q, want: none", got)
+		}
+	})
+}
+
 func TestNetHTTPServerLogAdapter(t *testing.T) {
 	t.Parallel()
 
@@ -284,17 +1210,9 @@ func TestNetHTTPServerLogAdapter(t *testing.T) {
 		t.Errorf("unexpected number of logs produced, want: 1, got: %d", logs.Len())
 	}
 
-	query := slogmem.RecordQuery{
-		Level:   slog.LevelError,
-		Message: "Internal error logged by net/http server",
-		Attrs: map[string]slog.Value{
-			"error": slog.AnyValue("some internal server error message"),
-		},
-	}
-
-	if ok, diff := logs.Contains(query); !ok {
-		t.Errorf("logs does not contain query, want: %+v, got:\n%s", query, diff)
-	}
+	testutil.AssertLog(t, logs, slog.LevelError, "Internal error logged by net/http server", map[string]slog.Value{
+		"error": slog.AnyValue("some internal server error message"),
+	})
 }
 
 func sendFutureSignalNotification(ctx context.Context, t *testing.T, sig os.Signal) (returnErr error) {
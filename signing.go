@@ -0,0 +1,137 @@
+package httputil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type (
+	// HMACSigningOption allows default HMAC signing config values to be
+	// overridden.
+	HMACSigningOption func(so *hmacSigningOptions)
+
+	hmacSigningOptions struct {
+		now func() time.Time
+	}
+)
+
+// WithHMACSigningClock sets the function used to get the current time for the
+// Date header. By default, time.Now is used.
+func WithHMACSigningClock(now func() time.Time) HMACSigningOption {
+	return func(so *hmacSigningOptions) {
+		so.now = now
+	}
+}
+
+// NewHMACSigningInterceptor creates an InterceptorFunc that signs each request
+// with an HMAC-SHA256 of a canonical string made up of the following values,
+// each terminated by a newline:
+//
+//   - the request method;
+//   - the request path and query;
+//   - the Date header, set to the current time;
+//   - the Digest header, set to "SHA-256=" and the base64 encoded SHA-256 of
+//     the request body.
+//
+// The signature is sent in the Authorization header as:
+//
+//	HMAC-SHA256 keyId="<keyID>", signature="<base64 encoded signature>"
+//
+// The request body is read from GetBody when it is set, as it is for bodies
+// encoded by the Client, so that it can be replayed on redirects. Otherwise,
+// the body is buffered in memory. The request passed to the interceptor is not
+// modified.
+func NewHMACSigningInterceptor(keyID string, secret []byte, options ...HMACSigningOption) InterceptorFunc {
+	opts := hmacSigningOptions{now: time.Now}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			signed, bodyDigest, err := cloneWithBodyDigest(req)
+			if err != nil {
+				return nil, err
+			}
+
+			signed.Header.Set("Date", opts.now().UTC().Format(http.TimeFormat))
+			signed.Header.Set("Digest", "SHA-256="+bodyDigest)
+
+			canonical := signed.Method + "\n" +
+				signed.URL.RequestURI() + "\n" +
+				signed.Header.Get("Date") + "\n" +
+				signed.Header.Get("Digest") + "\n"
+
+			mac := hmac.New(sha256.New, secret)
+			_, _ = io.WriteString(mac, canonical)
+
+			signed.Header.Set("Authorization", fmt.Sprintf(
+				`HMAC-SHA256 keyId="%s", signature="%s"`,
+				keyID,
+				base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+			))
+
+			return next.RoundTrip(signed) //nolint:wrapcheck // Errors from the transport are returned as is.
+		})
+	}
+}
+
+// cloneWithBodyDigest clones req so that its headers can be set without
+// modifying the original, and returns the base64 encoded SHA-256 of its body.
+// If req has no GetBody, its body is buffered and replaced on the clone. The
+// body of req is closed if an error occurs, as required of a RoundTripper.
+func cloneWithBodyDigest(req *http.Request) (*http.Request, string, error) {
+	signed := req.Clone(req.Context())
+	digest := sha256.New()
+
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+		// There is no body to read, the digest is of an empty body.
+	case req.GetBody != nil:
+		body, err := req.GetBody()
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, "", fmt.Errorf("getting request body to sign: %w", err)
+		}
+
+		err = hashBody(digest, body)
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, "", err
+		}
+	default:
+		var buf bytes.Buffer
+
+		err := hashBody(io.MultiWriter(digest, &buf), req.Body)
+		if err != nil {
+			return nil, "", err
+		}
+
+		signed.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+		signed.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+		}
+	}
+
+	return signed, base64.StdEncoding.EncodeToString(digest.Sum(nil)), nil
+}
+
+// hashBody copies body into w and closes it.
+func hashBody(w io.Writer, body io.ReadCloser) error {
+	_, err := io.Copy(w, body)
+	if closeErr := body.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading request body to sign: %w", err)
+	}
+
+	return nil
+}
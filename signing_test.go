@@ -0,0 +1,120 @@
+package httputil_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestNewHMACSigningInterceptor(t *testing.T) {
+	t.Parallel()
+
+	now := func() time.Time { return time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC) }
+
+	testCases := map[string]struct {
+		method            string
+		url               string
+		body              func() io.Reader
+		wantDigest        string
+		wantAuthorization string
+		wantBody          string
+	}{
+		"signs a request without a body": {
+			method:            http.MethodGet,
+			url:               "http://example.com/webhooks",
+			body:              func() io.Reader { return nil },
+			wantDigest:        "SHA-256=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+			wantAuthorization: `HMAC-SHA256 keyId="key-1", signature="N8oXkKKovhPmBipVSKrbO6RnFOs3Q7TysQ4oOghT/LU="`,
+			wantBody:          "",
+		},
+		"signs a request with a replayable body": {
+			method:            http.MethodPost,
+			url:               "http://example.com/webhooks?attempt=1",
+			body:              func() io.Reader { return strings.NewReader(`{"event":"created"}`) },
+			wantDigest:        "SHA-256=Kbxs5L6SOo9JZBAWU07GC6NZd0AJwr+jy48+rBhTx0w=",
+			wantAuthorization: `HMAC-SHA256 keyId="key-1", signature="uCKZloGXpnZu8vhtp0A/lZMS9sqwI+EAPlynVAorbdk="`,
+			wantBody:          `{"event":"created"}`,
+		},
+		"signs a request with a body that cannot be replayed": {
+			method: http.MethodPost,
+			url:    "http://example.com/webhooks?attempt=1",
+			body: func() io.Reader {
+				// Hiding the type of the reader stops http.NewRequest from setting GetBody.
+				return io.MultiReader(strings.NewReader(`{"event":"created"}`))
+			},
+			wantDigest:        "SHA-256=Kbxs5L6SOo9JZBAWU07GC6NZd0AJwr+jy48+rBhTx0w=",
+			wantAuthorization: `HMAC-SHA256 keyId="key-1", signature="uCKZloGXpnZu8vhtp0A/lZMS9sqwI+EAPlynVAorbdk="`,
+			wantBody:          `{"event":"created"}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				gotHeader http.Header
+				gotBody   string
+			)
+
+			transport := httputil.NewHMACSigningInterceptor("key-1", []byte("top-secret"), httputil.WithHMACSigningClock(now))(
+				httputil.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					gotHeader = r.Header
+
+					if r.Body != nil {
+						body, err := io.ReadAll(r.Body)
+						if err != nil {
+							t.Fatalf("unexpected error reading body: %v", err)
+						}
+
+						gotBody = string(body)
+					}
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				}),
+			)
+
+			req, err := http.NewRequestWithContext(t.Context(), testCase.method, testCase.url, testCase.body())
+			if err != nil {
+				t.Fatalf("unexpected error creating request: %v", err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_ = resp.Body.Close()
+
+			want := map[string]string{
+				"Date":          "Fri, 02 Jan 2026 03:04:05 GMT",
+				"Digest":        testCase.wantDigest,
+				"Authorization": testCase.wantAuthorization,
+			}
+
+			got := map[string]string{
+				"Date":          gotHeader.Get("Date"),
+				"Digest":        gotHeader.Get("Digest"),
+				"Authorization": gotHeader.Get("Authorization"),
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("signed headers mismatch (-want +got):\n%s", diff)
+			}
+
+			if gotBody != testCase.wantBody {
+				t.Errorf("body = %q, want: %q", gotBody, testCase.wantBody)
+			}
+
+			if len(req.Header) != 0 {
+				t.Errorf("original request headers = %v, want them to be unmodified", req.Header)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package httputil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// errIsDirectory is returned by openFSFile when the file is a directory.
+var errIsDirectory = errors.New("file is a directory")
+
+// SPAHandler creates a Handler that serves a single-page app from fsys. A
+// request for a file in fsys is served that file, and any other request is
+// served the file at indexPath so that the app can route it on the client.
+// Requests for a missing file with an extension, such as /assets/app.js, are
+// treated as missing assets rather than client routes and are rejected with a
+// [problem.NotFound] error.
+//
+// Register the handler for GET requests to "/" so that it serves every path
+// that no other endpoint matches. API endpoints registered with more specific
+// paths take precedence:
+//
+//	server.Register(httputil.Endpoint{
+//		Method:  http.MethodGet,
+//		Path:    "/",
+//		Handler: httputil.SPAHandler(dist, "index.html"),
+//	})
+//
+// The handler is wrapped with [WrapNetHTTPHandler] and options are passed to
+// it.
+func SPAHandler(fsys fs.FS, indexPath string, options ...HandlerOption) http.Handler {
+	return WrapNetHTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+		if name != "" && isFile(fsys, name) {
+			serveFSFile(w, r, fsys, name)
+			return
+		}
+
+		if path.Ext(name) != "" {
+			writeMiddlewareError(w, r, problem.NotFound(r))
+			return
+		}
+
+		serveFSFile(w, r, fsys, indexPath)
+	}, options...)
+}
+
+// isFile reports whether name is a regular file in fsys.
+func isFile(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// serveFSFile serves the file name from fsys with http.ServeContent, which
+// handles conditional and range requests and sets the Content-Type from the
+// extension of name. Unlike http.ServeFileFS, requests are never redirected.
+func serveFSFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	content, info, err := openFSFile(fsys, name)
+	if err != nil {
+		middlewareLogger(r).ErrorContext(r.Context(), "SPA handler failed to open file", slog.String("name", name), slog.Any("error", err))
+		writeMiddlewareError(w, r, problem.ServerError(r))
+
+		return
+	}
+
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+// openFSFile opens the file name from fsys as an io.ReadSeeker, buffering it
+// in memory if the file of fsys can not seek.
+func openFSFile(fsys fs.FS, name string) (io.ReadSeeker, fs.FileInfo, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("getting file info: %w", err)
+	}
+
+	if info.IsDir() {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("opening file: %w", errIsDirectory)
+	}
+
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		return seeker, info, nil
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	return bytes.NewReader(content), info, nil
+}
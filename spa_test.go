@@ -0,0 +1,126 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestSPAHandler(t *testing.T) {
+	t.Parallel()
+
+	const index = `<!doctype html><div id="app"></div>`
+
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte(index)},
+		"assets/app.js": {Data: []byte(`console.log("app")`)},
+		"favicon.ico":   {Data: []byte("icon")},
+	}
+
+	testCases := map[string]struct {
+		target          string
+		wantStatus      int
+		wantContentType string
+		wantBody        func(r *http.Request) string
+	}{
+		"serves an asset": {
+			target:          "/assets/app.js",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/javascript; charset=utf-8",
+			wantBody:        func(*http.Request) string { return `console.log("app")` },
+		},
+		"serves the index for the root path": {
+			target:          "/",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        func(*http.Request) string { return index },
+		},
+		"serves the index for the index path without redirecting": {
+			target:          "/index.html",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        func(*http.Request) string { return index },
+		},
+		"falls back to the index for a deep client route": {
+			target:          "/orders/42/edit",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        func(*http.Request) string { return index },
+		},
+		"falls back to the index for a directory": {
+			target:          "/assets",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        func(*http.Request) string { return index },
+		},
+		"a missing asset is not found": {
+			target:          "/assets/missing.js",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantBody:        func(r *http.Request) string { return problem.NotFound(r).MustMarshalJSONString() },
+		},
+		"api routes take precedence": {
+			target:          "/api/orders",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        func(*http.Request) string { return `{"orders":[]}` },
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(
+				httputil.Endpoint{
+					Method: http.MethodGet,
+					Path:   "/api/orders",
+					Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+						return httputil.OK(map[string][]string{"orders": {}})
+					}),
+				},
+				httputil.Endpoint{
+					Method:  http.MethodGet,
+					Path:    "/",
+					Handler: httputil.SPAHandler(fsys, "index.html"),
+				},
+			)
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if got := response.Header().Get("Content-Type"); got != testCase.wantContentType {
+				t.Errorf("Content-Type = %q, want: %q", got, testCase.wantContentType)
+			}
+
+			want := testCase.wantBody(request)
+			if testCase.wantContentType == "application/json; charset=utf-8" || testCase.wantStatus == http.StatusNotFound {
+				if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+					t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+				}
+
+				return
+			}
+
+			if diff := cmp.Diff(want, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+// ErrUnknownField is returned by [SparseFieldset] when a requested field is not
+// a member of the data being projected.
+var ErrUnknownField = errors.New("unknown field")
+
+// errSparseFieldsetData is returned by [SparseFieldset] when data is not an
+// object or an array of objects.
+var errSparseFieldsetData = errors.New("sparse fieldsets require object data")
+
+// sparseFieldsetParam is the query parameter that lists the fields of a sparse
+// fieldset, see [WithHandlerSparseFieldsets].
+const sparseFieldsetParam = "fields"
+
+// SparseFieldset projects data to only the requested fields and returns the
+// projected subset. Fields are matched by the JSON member names of data, so a
+// struct field tagged `json:"name"` is selected with "name". An object is
+// projected to a map, and an array or slice of objects to a slice of maps.
+// Fields that are known but omitted from the JSON of data, such as empty
+// fields tagged omitempty, are left out of the projection.
+//
+// An error wrapping [ErrUnknownField] is returned if a field is not a member
+// of data. For structs, the members are the JSON names of its fields, and for
+// maps they are the keys of each map.
+func SparseFieldset(data any, fields []string) (any, error) {
+	known := knownFields(data)
+	for _, field := range fields {
+		if _, ok := known[field]; known != nil && !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownField, field)
+		}
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling data: %w", err)
+	}
+
+	// Numbers are decoded as json.Number so that they are encoded unchanged,
+	// as decoding them to float64 would lose the precision of large integers.
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var decoded any
+	if err = decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("unmarshaling data: %w", err)
+	}
+
+	switch value := decoded.(type) {
+	case map[string]any:
+		return projectFields(value, fields, known == nil)
+	case []any:
+		projected := make([]any, len(value))
+
+		for i, item := range value {
+			object, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("projecting item %d: %w", i, errSparseFieldsetData)
+			}
+
+			if projected[i], err = projectFields(object, fields, known == nil); err != nil {
+				return nil, err
+			}
+		}
+
+		return projected, nil
+	default:
+		return nil, errSparseFieldsetData
+	}
+}
+
+// projectFields returns a copy of object with only fields. When strict is true,
+// fields that object does not have are reported as unknown.
+func projectFields(object map[string]any, fields []string, strict bool) (map[string]any, error) {
+	projected := make(map[string]any, len(fields))
+
+	for _, field := range fields {
+		value, ok := object[field]
+		if !ok {
+			if strict {
+				return nil, fmt.Errorf("%w %q", ErrUnknownField, field)
+			}
+
+			continue
+		}
+
+		projected[field] = value
+	}
+
+	return projected, nil
+}
+
+// knownFields returns the JSON member names of the struct type of data, or of
+// the element type of an array or slice of structs. It returns nil if the
+// members are not known from the type, such as for maps.
+func knownFields(data any) map[string]struct{} {
+	t := reflect.TypeOf(data)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	known := make(map[string]struct{})
+	addStructFields(t, known)
+
+	return known
+}
+
+// addStructFields adds the JSON member names of the struct type t to known,
+// including those of embedded structs that encoding/json promotes.
+func addStructFields(t reflect.Type, known map[string]struct{}) {
+	for field := range t.Fields() {
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, known)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		known[name] = struct{}{}
+	}
+}
+
+// requestedFields returns the fields of the sparse fieldset requested by r, or
+// nil if it does not request one.
+func requestedFields(r *http.Request) []string {
+	var fields []string
+
+	for field := range strings.SplitSeq(r.URL.Query().Get(sparseFieldsetParam), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// unknownFieldProblem returns the problem written when the sparse fieldset
+// requested by r names a field that is not a member of the response data.
+func unknownFieldProblem(r *http.Request, err error) *problem.DetailedError {
+	return problem.BadParameters(r, problem.Parameter{
+		Parameter: sparseFieldsetParam,
+		Detail:    err.Error(),
+		Type:      problem.ParameterTypeQuery,
+	})
+}
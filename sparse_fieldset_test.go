@@ -0,0 +1,240 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+type sparseAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type sparseUser struct {
+	sparseAudit
+
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"-"`
+	Age      int
+}
+
+func TestSparseFieldset(t *testing.T) {
+	t.Parallel()
+
+	user := sparseUser{
+		sparseAudit: sparseAudit{CreatedBy: "admin"},
+		ID:          "1",
+		Name:        "alice",
+		Email:       "",
+		Password:    "secret",
+		Age:         30,
+	}
+
+	testCases := map[string]struct {
+		data    any
+		fields  []string
+		want    any
+		wantErr string
+	}{
+		"projects a struct to the requested fields": {
+			data:    user,
+			fields:  []string{"id", "name"},
+			want:    map[string]any{"id": "1", "name": "alice"},
+			wantErr: "",
+		},
+		"projects a pointer to a struct": {
+			data:    &user,
+			fields:  []string{"name"},
+			want:    map[string]any{"name": "alice"},
+			wantErr: "",
+		},
+		"selects fields by their JSON names, including promoted and untagged fields": {
+			data:    user,
+			fields:  []string{"createdBy", "Age"},
+			want:    map[string]any{"createdBy": "admin", "Age": json.Number("30")},
+			wantErr: "",
+		},
+		"leaves out known fields that are omitted from the JSON": {
+			data:    user,
+			fields:  []string{"id", "email"},
+			want:    map[string]any{"id": "1"},
+			wantErr: "",
+		},
+		"projects each item of a slice of structs": {
+			data:    []sparseUser{user, {ID: "2", Name: "bob"}},
+			fields:  []string{"id"},
+			want:    []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}},
+			wantErr: "",
+		},
+		"projects a map to the requested keys": {
+			data:    map[string]any{"id": "1", "name": "alice"},
+			fields:  []string{"name"},
+			want:    map[string]any{"name": "alice"},
+			wantErr: "",
+		},
+		"rejects an unknown field of a struct": {
+			data:    user,
+			fields:  []string{"id", "nickname"},
+			want:    nil,
+			wantErr: `unknown field "nickname"`,
+		},
+		"rejects a field that is not serialized": {
+			data:    user,
+			fields:  []string{"Password"},
+			want:    nil,
+			wantErr: `unknown field "Password"`,
+		},
+		"rejects an unknown key of a map": {
+			data:    map[string]any{"id": "1"},
+			fields:  []string{"name"},
+			want:    nil,
+			wantErr: `unknown field "name"`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := httputil.SparseFieldset(testCase.data, testCase.fields)
+
+			if testCase.wantErr != "" {
+				if !errors.Is(err, httputil.ErrUnknownField) {
+					t.Fatalf("SparseFieldset() error = %v, want it to wrap: %v", err, httputil.ErrUnknownField)
+				}
+
+				if diff := cmp.Diff(testCase.wantErr, err.Error()); diff != "" {
+					t.Errorf("SparseFieldset() error mismatch (-want +got):\n%s", diff)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SparseFieldset() error = %v, want: nil", err)
+			}
+
+			if diff := cmp.Diff(testCase.want, got); diff != "" {
+				t.Errorf("SparseFieldset() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("keeps the precision of large integers", func(t *testing.T) {
+		t.Parallel()
+
+		data := struct {
+			ID int64 `json:"id"`
+		}{ID: 9007199254740993}
+
+		got, err := httputil.SparseFieldset(data, []string{"id"})
+		if err != nil {
+			t.Fatalf("SparseFieldset() error = %v, want: nil", err)
+		}
+
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("unexpected error marshaling projection: %v", err)
+		}
+
+		if want := `{"id":9007199254740993}`; string(b) != want {
+			t.Errorf("json.Marshal(SparseFieldset()) = %s, want: %s", b, want)
+		}
+	})
+
+	t.Run("rejects data that is not an object", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := httputil.SparseFieldset("alice", []string{"name"}); err == nil {
+			t.Error("SparseFieldset() error = nil, want an error")
+		}
+	})
+}
+
+func TestWithHandlerSparseFieldsets(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		options          []httputil.HandlerOption
+		target           string
+		wantStatus       int
+		wantResponseBody func(r *http.Request) string
+	}{
+		"projects the response to the requested fields": {
+			options:    []httputil.HandlerOption{httputil.WithHandlerSparseFieldsets()},
+			target:     "/users/1?fields=id,%20name",
+			wantStatus: http.StatusOK,
+			wantResponseBody: func(*http.Request) string {
+				return `{"id":"1","name":"alice"}`
+			},
+		},
+		"returns the full response when no fields are requested": {
+			options:    []httputil.HandlerOption{httputil.WithHandlerSparseFieldsets()},
+			target:     "/users/1",
+			wantStatus: http.StatusOK,
+			wantResponseBody: func(*http.Request) string {
+				return `{"createdBy":"admin","id":"1","name":"alice","Age":30}`
+			},
+		},
+		"rejects an unknown field with a bad parameters problem": {
+			options:    []httputil.HandlerOption{httputil.WithHandlerSparseFieldsets()},
+			target:     "/users/1?fields=id,nickname",
+			wantStatus: http.StatusBadRequest,
+			wantResponseBody: func(r *http.Request) string {
+				return problem.BadParameters(r, problem.Parameter{
+					Parameter: "fields",
+					Detail:    `unknown field "nickname"`,
+					Type:      problem.ParameterTypeQuery,
+				}).MustMarshalJSONString()
+			},
+		},
+		"the fields parameter is ignored when the option is not set": {
+			options:    nil,
+			target:     "/users/1?fields=id",
+			wantStatus: http.StatusOK,
+			wantResponseBody: func(*http.Request) string {
+				return `{"createdBy":"admin","id":"1","name":"alice","Age":30}`
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodGet,
+				Path:   "/users/{id}",
+				Handler: httputil.NewHandler(func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+					return httputil.OK(sparseUser{sparseAudit: sparseAudit{CreatedBy: "admin"}, ID: "1", Name: "alice", Age: 30})
+				}, testCase.options...),
+			})
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantResponseBody(request), response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package httputil
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// defaultTimeRangeDuration is the length of the range that a [TimeRange]
+// covers when a request does not specify both of its bounds.
+const defaultTimeRangeDuration = 24 * time.Hour
+
+// paramDefaulter is implemented by parameter types that set defaults for the
+// parameters that a request did not specify. [BindValidParameters] calls
+// setParamDefaults once the fields of the type are bound and before they are
+// validated.
+type paramDefaulter interface {
+	setParamDefaults()
+}
+
+// TimeRange binds the "from" and "to" query parameters as RFC 3339 times,
+// such as for reporting endpoints. Embed it in a params struct to use it with
+// [BindValidParameters]:
+//
+//	type ReportParams struct {
+//		httputil.TimeRange
+//		Format string `param:"query=format,default=csv"`
+//	}
+//
+// A request that does not specify either bound covers the last 24 hours. When
+// only From is specified, To is the current time, and when only To is
+// specified, From is 24 hours before it. A range where From is after To is
+// rejected with a [problem.BadParameters] error on the "to" parameter.
+type TimeRange struct {
+	From time.Time `param:"query=from"`
+	To   time.Time `param:"query=to"`
+}
+
+// Ensure TimeRange implements paramDefaulter.
+var _ paramDefaulter = &TimeRange{} //nolint:exhaustruct // Compile time implementation check.
+
+// setParamDefaults sets the bounds that the request did not specify.
+func (tr *TimeRange) setParamDefaults() {
+	switch {
+	case tr.From.IsZero() && tr.To.IsZero():
+		tr.To = time.Now()
+		tr.From = tr.To.Add(-defaultTimeRangeDuration)
+	case tr.To.IsZero():
+		tr.To = time.Now()
+	case tr.From.IsZero():
+		tr.From = tr.To.Add(-defaultTimeRangeDuration)
+	}
+}
+
+// validateTimeRange is the struct level validation of [TimeRange], reporting
+// a range where From is after To as an error on To.
+func validateTimeRange(_ context.Context, sl validator.StructLevel) {
+	tr, _ := sl.Current().Interface().(TimeRange)
+	if tr.From.After(tr.To) {
+		sl.ReportError(tr.To, "to", "To", "gtefield", "from")
+	}
+}
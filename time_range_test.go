@@ -0,0 +1,143 @@
+package httputil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestTimeRange(t *testing.T) {
+	t.Parallel()
+
+	type reportParams struct {
+		httputil.TimeRange
+
+		Format string `param:"query=format,default=csv"`
+	}
+
+	testCases := map[string]struct {
+		target      string
+		want        func(now time.Time) reportParams
+		wantProblem func(r *http.Request) *problem.DetailedError
+	}{
+		"binds a valid range": {
+			target: "/reports?from=2026-01-01T00:00:00Z&to=2026-01-31T00:00:00Z&format=json",
+			want: func(time.Time) reportParams {
+				return reportParams{
+					TimeRange: httputil.TimeRange{
+						From: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+						To:   time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+					},
+					Format: "json",
+				}
+			},
+			wantProblem: nil,
+		},
+		"a range with equal bounds is valid": {
+			target: "/reports?from=2026-01-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+			want: func(time.Time) reportParams {
+				return reportParams{
+					TimeRange: httputil.TimeRange{
+						From: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+						To:   time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+					},
+					Format: "csv",
+				}
+			},
+			wantProblem: nil,
+		},
+		"defaults to the last 24 hours when both bounds are absent": {
+			target: "/reports",
+			want: func(now time.Time) reportParams {
+				return reportParams{
+					TimeRange: httputil.TimeRange{From: now.Add(-24 * time.Hour), To: now},
+					Format:    "csv",
+				}
+			},
+			wantProblem: nil,
+		},
+		"defaults to to the current time when only from is given": {
+			target: "/reports?from=1999-12-31T00:00:00Z",
+			want: func(now time.Time) reportParams {
+				return reportParams{
+					TimeRange: httputil.TimeRange{From: time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), To: now},
+					Format:    "csv",
+				}
+			},
+			wantProblem: nil,
+		},
+		"defaults from to 24 hours before to when only to is given": {
+			target: "/reports?to=2026-01-02T00:00:00Z",
+			want: func(time.Time) reportParams {
+				return reportParams{
+					TimeRange: httputil.TimeRange{
+						From: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+						To:   time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC),
+					},
+					Format: "csv",
+				}
+			},
+			wantProblem: nil,
+		},
+		"rejects an inverted range": {
+			target: "/reports?from=2026-01-31T00:00:00Z&to=2026-01-01T00:00:00Z",
+			want:   nil,
+			wantProblem: func(r *http.Request) *problem.DetailedError {
+				return problem.BadParameters(r, problem.Parameter{
+					Parameter: "to",
+					Detail:    "must be greater than or equal to from",
+					Type:      problem.ParameterTypeQuery,
+				})
+			},
+		},
+		"rejects a time that is not RFC 3339": {
+			target: "/reports?from=yesterday",
+			want:   nil,
+			wantProblem: func(r *http.Request) *problem.DetailedError {
+				return problem.BadParameters(r, problem.Parameter{
+					Parameter: "from",
+					Detail:    "must be a valid time.Time",
+					Type:      problem.ParameterTypeQuery,
+				})
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			// The synctest bubble fixes the current time used for the defaults.
+			synctest.Test(t, func(t *testing.T) {
+				request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+
+				var params reportParams
+
+				err := httputil.BindValidParameters(request, &params)
+
+				if testCase.wantProblem != nil {
+					if diff := cmp.Diff(testCase.wantProblem(request), err); diff != "" {
+						t.Errorf("BindValidParameters() error mismatch (-want +got):\n%s", diff)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("BindValidParameters() error = %v, want: nil", err)
+				}
+
+				if diff := cmp.Diff(testCase.want(time.Now()), params); diff != "" {
+					t.Errorf("BindValidParameters() params mismatch (-want +got):\n%s", diff)
+				}
+			})
+		})
+	}
+}
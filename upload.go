@@ -0,0 +1,137 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// sniffLen is the number of leading bytes of an uploaded file that
+// [ValidateUpload] uses to detect its content type.
+const sniffLen = 512
+
+var (
+	// ErrUploadTooLarge is returned by [ValidateUpload] when a file is larger
+	// than [UploadRules.MaxBytes]. It results in a [problem.PayloadTooLarge]
+	// response.
+	ErrUploadTooLarge = errors.New("uploaded file is too large")
+	// ErrUploadContentType is returned by [ValidateUpload] when the sniffed
+	// content type of a file is not one of [UploadRules.AllowedTypes]. It
+	// results in a [problem.BadRequest] response.
+	ErrUploadContentType = errors.New("uploaded file has a content type that is not allowed")
+	// ErrTooManyUploads is returned by [ValidateUploads] when there are more
+	// files than [UploadRules.MaxFiles]. It results in a [problem.BadRequest]
+	// response.
+	ErrTooManyUploads = errors.New("too many uploaded files")
+)
+
+const (
+	// uploadTooLargeDetail is the problem detail used for [ErrUploadTooLarge].
+	uploadTooLargeDetail = "The uploaded file is too large"
+	// uploadContentTypeDetail is the problem detail used for
+	// [ErrUploadContentType].
+	uploadContentTypeDetail = "The uploaded file has a content type that is not allowed"
+	// tooManyUploadsDetail is the problem detail used for [ErrTooManyUploads].
+	tooManyUploadsDetail = "The request has too many uploaded files"
+)
+
+// UploadRules are the constraints that [ValidateUpload] and [ValidateUploads]
+// check uploaded files against. A zero value disables the corresponding check.
+type UploadRules struct {
+	// MaxBytes is the maximum size of each file.
+	MaxBytes int64
+	// AllowedTypes are the media types, such as "image/png", that a file may
+	// have. A type of the form "image/*" allows any subtype.
+	AllowedTypes []string
+	// MaxFiles is the maximum number of files checked by [ValidateUploads].
+	MaxFiles int
+}
+
+// ValidateUpload checks the file of fh against the size and content type
+// constraints of rules. The content type is detected from the first bytes of
+// the file with http.DetectContentType, so neither the file name nor the
+// Content-Type header sent by the client are trusted.
+//
+// The returned error wraps [ErrUploadTooLarge] or [ErrUploadContentType] when a
+// constraint is violated, which handlers created by [NewHandler] report as a
+// problem response when it is returned from an [Action].
+func ValidateUpload(fh *multipart.FileHeader, rules UploadRules) error {
+	if rules.MaxBytes > 0 && fh.Size > rules.MaxBytes {
+		return fmt.Errorf("%w: %q exceeds %d bytes", ErrUploadTooLarge, fh.Filename, rules.MaxBytes)
+	}
+
+	if len(rules.AllowedTypes) == 0 {
+		return nil
+	}
+
+	contentType, err := sniffContentType(fh)
+	if err != nil {
+		return err
+	}
+
+	if !allowedType(contentType, rules.AllowedTypes) {
+		return fmt.Errorf("%w: %q is %s", ErrUploadContentType, fh.Filename, contentType)
+	}
+
+	return nil
+}
+
+// ValidateUploads checks that there are no more than [UploadRules.MaxFiles]
+// files and then checks each of them with [ValidateUpload]. The returned error
+// wraps [ErrTooManyUploads] when there are too many files.
+func ValidateUploads(files []*multipart.FileHeader, rules UploadRules) error {
+	if rules.MaxFiles > 0 && len(files) > rules.MaxFiles {
+		return fmt.Errorf("%w: %d files exceeds %d", ErrTooManyUploads, len(files), rules.MaxFiles)
+	}
+
+	for _, fh := range files {
+		if err := ValidateUpload(fh, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sniffContentType returns the media type of the file of fh, without
+// parameters, detected from its first bytes.
+func sniffContentType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("opening uploaded file %q: %w", fh.Filename, err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("reading uploaded file %q: %w", fh.Filename, err)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return "", fmt.Errorf("parsing sniffed content type of %q: %w", fh.Filename, err)
+	}
+
+	return mediaType, nil
+}
+
+// allowedType reports whether mediaType matches one of allowed, where an
+// allowed type of the form "type/*" matches any subtype of type.
+func allowedType(mediaType string, allowed []string) bool {
+	return slices.ContainsFunc(allowed, func(allowedType string) bool {
+		allowedType = strings.ToLower(strings.TrimSpace(allowedType))
+
+		if prefix, ok := strings.CutSuffix(allowedType, "/*"); ok {
+			return strings.HasPrefix(mediaType, prefix+"/")
+		}
+
+		return mediaType == allowedType
+	})
+}
@@ -0,0 +1,159 @@
+package httputil_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/nickbryan/httputil"
+)
+
+// pngHeader is the signature that starts every PNG file.
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+// uploadedFile is a file part of a multipart form built by newFileHeaders.
+type uploadedFile struct {
+	filename    string
+	contentType string
+	content     string
+}
+
+// newFileHeaders returns the file headers of a parsed multipart form holding
+// files under the "files" field.
+func newFileHeaders(t *testing.T, files ...uploadedFile) []*multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	for _, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="files"; filename="`+file.filename+`"`)
+		header.Set("Content-Type", file.contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatalf("CreatePart() error = %v, want: nil", err)
+		}
+
+		if _, err = part.Write([]byte(file.content)); err != nil {
+			t.Fatalf("part.Write() error = %v, want: nil", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close() error = %v, want: nil", err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/uploads", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	if err := request.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm() error = %v, want: nil", err)
+	}
+
+	return request.MultipartForm.File["files"]
+}
+
+func TestValidateUpload(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		file    uploadedFile
+		rules   httputil.UploadRules
+		wantErr error
+	}{
+		"an allowed image is accepted": {
+			file:    uploadedFile{filename: "cat.png", contentType: "image/png", content: pngHeader},
+			rules:   httputil.UploadRules{MaxBytes: 1 << 10, AllowedTypes: []string{"image/png"}, MaxFiles: 0},
+			wantErr: nil,
+		},
+		"a wildcard type allows any subtype": {
+			file:    uploadedFile{filename: "cat.png", contentType: "image/png", content: pngHeader},
+			rules:   httputil.UploadRules{MaxBytes: 0, AllowedTypes: []string{"image/*"}, MaxFiles: 0},
+			wantErr: nil,
+		},
+		"a disallowed type masquerading as an image is rejected": {
+			file:    uploadedFile{filename: "cat.png", contentType: "image/png", content: "<html><script>alert(1)</script></html>"},
+			rules:   httputil.UploadRules{MaxBytes: 1 << 10, AllowedTypes: []string{"image/png"}, MaxFiles: 0},
+			wantErr: httputil.ErrUploadContentType,
+		},
+		"an oversized file is rejected": {
+			file:    uploadedFile{filename: "cat.png", contentType: "image/png", content: pngHeader + string(make([]byte, 64))},
+			rules:   httputil.UploadRules{MaxBytes: 32, AllowedTypes: []string{"image/png"}, MaxFiles: 0},
+			wantErr: httputil.ErrUploadTooLarge,
+		},
+		"any file is accepted without rules": {
+			file:    uploadedFile{filename: "notes.txt", contentType: "text/plain", content: "hello"},
+			rules:   httputil.UploadRules{MaxBytes: 0, AllowedTypes: nil, MaxFiles: 0},
+			wantErr: nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			fileHeaders := newFileHeaders(t, testCase.file)
+
+			err := httputil.ValidateUpload(fileHeaders[0], testCase.rules)
+
+			if testCase.wantErr == nil && err != nil {
+				t.Fatalf("ValidateUpload() error = %v, want: nil", err)
+			}
+
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("ValidateUpload() error = %v, want it to wrap: %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateUploads(t *testing.T) {
+	t.Parallel()
+
+	image := uploadedFile{filename: "cat.png", contentType: "image/png", content: pngHeader}
+
+	testCases := map[string]struct {
+		files   []uploadedFile
+		rules   httputil.UploadRules
+		wantErr error
+	}{
+		"files within the limit are accepted": {
+			files:   []uploadedFile{image, image},
+			rules:   httputil.UploadRules{MaxBytes: 0, AllowedTypes: []string{"image/png"}, MaxFiles: 2},
+			wantErr: nil,
+		},
+		"too many files are rejected": {
+			files:   []uploadedFile{image, image, image},
+			rules:   httputil.UploadRules{MaxBytes: 0, AllowedTypes: []string{"image/png"}, MaxFiles: 2},
+			wantErr: httputil.ErrTooManyUploads,
+		},
+		"each file is validated": {
+			files:   []uploadedFile{image, {filename: "cat.png", contentType: "image/png", content: "plain text"}},
+			rules:   httputil.UploadRules{MaxBytes: 0, AllowedTypes: []string{"image/png"}, MaxFiles: 2},
+			wantErr: httputil.ErrUploadContentType,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			err := httputil.ValidateUploads(newFileHeaders(t, testCase.files...), testCase.rules)
+
+			if testCase.wantErr == nil && err != nil {
+				t.Fatalf("ValidateUploads() error = %v, want: nil", err)
+			}
+
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("ValidateUploads() error = %v, want it to wrap: %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
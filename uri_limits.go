@@ -0,0 +1,78 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nickbryan/httputil/problem"
+)
+
+const (
+	// defaultMaxURLBytes is the default maximum size of a request URL allowed
+	// by [NewURILimitsMiddleware].
+	defaultMaxURLBytes = 8 << 10
+	// defaultMaxQueryBytes is the default maximum size of a request query
+	// string allowed by [NewURILimitsMiddleware].
+	defaultMaxQueryBytes = 4 << 10
+)
+
+type (
+	// URILimitsOption allows default [NewURILimitsMiddleware] config values to
+	// be overridden.
+	URILimitsOption func(o *uriLimitsOptions)
+
+	uriLimitsOptions struct {
+		maxURLBytes   int
+		maxQueryBytes int
+	}
+)
+
+// WithURILimitsMaxURLBytes sets the maximum size, in bytes, of the URL of a
+// request, including its query string. The default is 8KB.
+func WithURILimitsMaxURLBytes(size int) URILimitsOption {
+	return func(o *uriLimitsOptions) {
+		o.maxURLBytes = size
+	}
+}
+
+// WithURILimitsMaxQueryBytes sets the maximum size, in bytes, of the raw query
+// string of a request. The default is 4KB.
+func WithURILimitsMaxQueryBytes(size int) URILimitsOption {
+	return func(o *uriLimitsOptions) {
+		o.maxQueryBytes = size
+	}
+}
+
+// NewURILimitsMiddleware creates a MiddlewareFunc that rejects requests with a
+// URL or query string that is too long with a [problem.URITooLong] error.
+func NewURILimitsMiddleware(options ...URILimitsOption) MiddlewareFunc {
+	opts := uriLimitsOptions{maxURLBytes: defaultMaxURLBytes, maxQueryBytes: defaultMaxQueryBytes}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if detail := opts.violation(r); detail != "" {
+				writeMiddlewareError(w, r, problem.URITooLong(r).WithDetail(detail))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// violation returns the problem detail describing the first limit that the URL
+// of r exceeds, or an empty string if it is within the limits.
+func (o uriLimitsOptions) violation(r *http.Request) string {
+	if len(r.URL.RawQuery) > o.maxQueryBytes {
+		return fmt.Sprintf("The request query string exceeds %d bytes", o.maxQueryBytes)
+	}
+
+	if len(r.URL.String()) > o.maxURLBytes {
+		return fmt.Sprintf("The request URL exceeds %d bytes", o.maxURLBytes)
+	}
+
+	return ""
+}
@@ -0,0 +1,89 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+func TestNewURILimitsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		options    []httputil.URILimitsOption
+		target     string
+		wantStatus int
+		wantDetail string
+	}{
+		"a request within the default limits is passed through": {
+			options:    nil,
+			target:     "/orders?status=open&page=2",
+			wantStatus: http.StatusOK,
+			wantDetail: "",
+		},
+		"a request with a query string within the default limit is passed through": {
+			options:    nil,
+			target:     "/orders?q=" + strings.Repeat("a", 4<<10-2),
+			wantStatus: http.StatusOK,
+			wantDetail: "",
+		},
+		"a request with a query string over the default limit is rejected": {
+			options:    nil,
+			target:     "/orders?q=" + strings.Repeat("a", 4<<10-1),
+			wantStatus: http.StatusRequestURITooLong,
+			wantDetail: "The request query string exceeds 4096 bytes",
+		},
+		"a request with an overly long query string is rejected": {
+			options:    []httputil.URILimitsOption{httputil.WithURILimitsMaxQueryBytes(10)},
+			target:     "/orders?status=cancelled",
+			wantStatus: http.StatusRequestURITooLong,
+			wantDetail: "The request query string exceeds 10 bytes",
+		},
+		"a request with an overly long URL is rejected": {
+			options:    []httputil.URILimitsOption{httputil.WithURILimitsMaxURLBytes(20)},
+			target:     "/orders?status=cancelled",
+			wantStatus: http.StatusRequestURITooLong,
+			wantDetail: "The request URL exceeds 20 bytes",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodGet, Path: "/orders", Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})},
+			}.WithMiddleware(httputil.NewURILimitsMiddleware(testCase.options...))...)
+
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Fatalf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if testCase.wantDetail == "" {
+				return
+			}
+
+			want := problem.URITooLong(request).WithDetail(testCase.wantDetail).MustMarshalJSONString()
+			if diff := testutil.DiffJSON(want, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
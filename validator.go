@@ -1,8 +1,11 @@
 package httputil
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/form/v4"
@@ -30,6 +33,13 @@ const (
 //nolint:gochecknoglobals // See the comment above.
 var validate *validator.Validate
 
+// oneOfValuesRegexp matches the values of a oneof tag parameter, which are
+// separated by spaces and may be quoted with single quotes to include spaces,
+// as parsed by the validator.
+//
+//nolint:gochecknoglobals // Compiled once rather than per validation failure.
+var oneOfValuesRegexp = regexp.MustCompile(`'[^']*'|\S+`)
+
 //nolint:gochecknoinits // Required to create our singleton instance of the validator.
 func init() {
 	validate = defaultValidator()
@@ -58,9 +68,41 @@ func defaultValidator() *validator.Validate {
 		return ""
 	})
 
+	vld.RegisterStructValidationCtx(validateTimeRange, TimeRange{}) //nolint:exhaustruct // Used only for its type.
+
 	return vld
 }
 
+// ValidationFunc is a custom validation that has access to the context of the
+// request being validated. It reports whether the field described by fl is
+// valid. Request-scoped data, such as the tenant of the authenticated user,
+// can be placed in the context by a [Guard] and read here using the context
+// key of the application.
+type ValidationFunc func(ctx context.Context, fl validator.FieldLevel) bool
+
+// RegisterValidation registers fn as the validation for tag on the validator
+// used by every handler and [BindValidParameters], so that it can be used in
+// `validate` struct tags of request data and params. fn is called with the
+// context of the request, including any values added to it by a Guard.
+//
+// Validations must be registered before any requests are served, typically
+// during start up, as registration is not safe for concurrent use with
+// validation. Registering a tag again replaces its validation, including the
+// built in ones. Failures of a custom validation are described as
+// "should be <tag>", or by the [MessageFunc] of the handler when set.
+//
+//	httputil.RegisterValidation("tenant_unique", func(ctx context.Context, fl validator.FieldLevel) bool {
+//	    tenant, _ := ctx.Value(tenantKey{}).(string)
+//	    return !store.Exists(ctx, tenant, fl.Field().String())
+//	})
+func RegisterValidation(tag string, fn ValidationFunc) error {
+	if err := validate.RegisterValidationCtx(tag, validator.FuncCtx(fn)); err != nil {
+		return fmt.Errorf("registering validation %q: %w", tag, err)
+	}
+
+	return nil
+}
+
 // MessageFunc generates a user-facing error message for a validation failure.
 // The tag is the validation rule that failed (e.g. "required", "min", "email")
 // and param is its argument (e.g. "5" for min=5, empty for required).
@@ -85,20 +127,110 @@ func describeValidationError(err validator.FieldError) string {
 		return "should be a valid email"
 	case "e164":
 		return "should be a valid international phone number (e.g. +33 6 06 06 06 06)"
+	case "oneof":
+		values := oneOfValuesRegexp.FindAllString(err.Param(), -1)
+		for i, value := range values {
+			values[i] = strings.Trim(value, "'")
+		}
+
+		return "must be one of: " + strings.Join(values, ", ")
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield",
+		"eqcsfield", "necsfield", "gtcsfield", "gtecsfield", "ltcsfield", "ltecsfield":
+		return describeFieldComparison(err)
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		if msg, ok := describeSizeError(err); ok {
+			return msg
+		}
+
+		return describeTag(err)
 	default:
 		if strings.Contains(err.Tag(), "uuid") {
 			return "should be a valid " + strings.ToUpper(err.Tag())
 		}
 
-		resp := "should be " + err.Tag()
-		if err.Param() != "" {
-			resp += "=" + err.Param()
+		return describeTag(err)
+	}
+}
+
+// describeTag generates the fallback message for a validation tag that has no
+// tailored message, naming the tag and its param (e.g. "should be max=10").
+func describeTag(err validator.FieldError) string {
+	resp := "should be " + err.Tag()
+	if err.Param() != "" {
+		resp += "=" + err.Param()
+	}
+
+	return resp
+}
+
+// describeSizeError generates a message for a numeric or length constraint,
+// such as "must be at least 3" for a number or "must be at least 3 characters"
+// for a string. It reports false for kinds that the constraint does not apply
+// to as a size, such as time.Time, or when the tag has no param.
+func describeSizeError(err validator.FieldError) (string, bool) {
+	comparisons := map[string]string{
+		"min": "at least",
+		"gte": "at least",
+		"max": "at most",
+		"lte": "at most",
+		"len": "exactly",
+		"gt":  "more than",
+		"lt":  "fewer than",
+	}
+
+	comparison, param := comparisons[err.Tag()], err.Param()
+	if param == "" {
+		return "", false
+	}
+
+	//nolint:exhaustive // Other kinds are described by describeTag.
+	switch err.Kind() {
+	case reflect.String:
+		return "must be " + comparison + " " + countOf(param, "character"), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must contain " + comparison + " " + countOf(param, "item"), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		numericComparisons := map[string]string{"gt": "greater than", "lt": "less than"}
+		if c, ok := numericComparisons[err.Tag()]; ok {
+			comparison = c
 		}
 
-		return resp
+		return "must be " + comparison + " " + param, true
+	default:
+		return "", false
 	}
 }
 
+// describeFieldComparison generates a message for a tag comparing the field
+// with another field, such as "must be greater than StartDate" for gtfield. The
+// other field is named by the param of the tag, which is the name of the struct
+// field rather than its JSON name.
+func describeFieldComparison(err validator.FieldError) string {
+	comparisons := map[string]string{
+		"eq":  "must be equal to",
+		"ne":  "must not be equal to",
+		"gt":  "must be greater than",
+		"gte": "must be greater than or equal to",
+		"lt":  "must be less than",
+		"lte": "must be less than or equal to",
+	}
+
+	operator := strings.TrimSuffix(strings.TrimSuffix(err.Tag(), "field"), "cs")
+
+	return comparisons[operator] + " " + err.Param()
+}
+
+// countOf returns count followed by noun, pluralized unless count is 1.
+func countOf(count, noun string) string {
+	if count == "1" {
+		return count + " " + noun
+	}
+
+	return count + " " + noun + "s"
+}
+
 // translateDataError translates request body decoding and validation errors
 // into a field-to-message map. When fn is non-nil it is used for validation
 // errors; otherwise [describeValidationError] provides the default messages.
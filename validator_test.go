@@ -0,0 +1,134 @@
+package httputil_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+	"github.com/nickbryan/httputil/internal/testutil"
+	"github.com/nickbryan/httputil/problem"
+)
+
+// tenantCtxKey is the context key for the tenant placed in context by the
+// tenant guard in TestRegisterValidation.
+type tenantCtxKey struct{}
+
+func TestRegisterValidation(t *testing.T) {
+	// Validations are registered before the test is marked as parallel, as
+	// registration is not safe for concurrent use with validation.
+	taken := map[string][]string{"acme": {"taken"}}
+
+	err := httputil.RegisterValidation("tenant_unique", func(ctx context.Context, fl validator.FieldLevel) bool {
+		tenant, ok := ctx.Value(tenantCtxKey{}).(string)
+		if !ok {
+			return false
+		}
+
+		for _, name := range taken[tenant] {
+			if name == fl.Field().String() {
+				return false
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		t.Fatalf("unexpected error registering validation: %v", err)
+	}
+
+	t.Parallel()
+
+	tenantGuard := httputil.GuardFunc(func(r *http.Request) (*http.Request, error) {
+		return r.WithContext(context.WithValue(r.Context(), tenantCtxKey{}, r.Header.Get("X-Tenant"))), nil
+	})
+
+	type (
+		data struct {
+			Name string `json:"name" validate:"required,tenant_unique"`
+		}
+		params struct {
+			Slug string `param:"query=slug" validate:"omitempty,tenant_unique"`
+		}
+	)
+
+	testCases := map[string]struct {
+		tenant     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		"a name that is unique for the tenant is valid": {
+			tenant:     "globex",
+			target:     "/projects",
+			body:       `{"name":"taken"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"name":"taken"}`,
+		},
+		"a name that is taken by the tenant is invalid": {
+			tenant:     "acme",
+			target:     "/projects",
+			body:       `{"name":"taken"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: problem.ConstraintViolation(
+				httptest.NewRequest(http.MethodPost, "/projects", http.NoBody),
+				problem.Property{Detail: "should be tenant_unique", Pointer: "/name"},
+			).MustMarshalJSONString(),
+		},
+		"a param that is taken by the tenant is invalid": {
+			tenant:     "acme",
+			target:     "/projects?slug=taken",
+			body:       `{"name":"new"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody: problem.BadParameters(
+				httptest.NewRequest(http.MethodPost, "/projects?slug=taken", http.NoBody),
+				problem.Parameter{Parameter: "slug", Detail: "should be tenant_unique", Type: problem.ParameterTypeQuery},
+			).MustMarshalJSONString(),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.Endpoint{
+				Method: http.MethodPost,
+				Path:   "/projects",
+				Handler: httputil.NewHandler(func(r httputil.Request[data, params]) (*httputil.Response, error) {
+					return httputil.Created(r.Data)
+				}, httputil.WithHandlerGuard(tenantGuard)),
+			})
+
+			request := httptest.NewRequest(http.MethodPost, testCase.target, strings.NewReader(testCase.body))
+			request.Header.Set("X-Tenant", testCase.tenant)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			if response.Code != testCase.wantStatus {
+				t.Errorf("response.Code = %d, want: %d", response.Code, testCase.wantStatus)
+			}
+
+			if diff := testutil.DiffJSON(testCase.wantBody, response.Body.String()); diff != "" {
+				t.Errorf("response.Body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("an empty tag is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		if err := httputil.RegisterValidation("", func(context.Context, validator.FieldLevel) bool { return true }); err == nil {
+			t.Error("expected an error registering a validation without a tag")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package httputil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// varyHeader is the canonical name of the Vary header.
+const varyHeader = "Vary"
+
+// AddVary adds the given request header names to the Vary header in header,
+// keeping any names that are already present. Names are deduplicated case
+// insensitively and written as a single comma separated value. A name of "*"
+// replaces all others as the response varies on more than request headers.
+//
+// Middleware that produces responses differing by request header, such as
+// compression or content negotiation, should use AddVary rather than setting
+// the Vary header so that the names added by other middleware are preserved.
+func AddVary(header http.Header, names ...string) {
+	merged := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+
+	for _, name := range append(header.Values(varyHeader), names...) {
+		for field := range strings.SplitSeq(name, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			if field == "*" {
+				header.Set(varyHeader, "*")
+				return
+			}
+
+			key := http.CanonicalHeaderKey(field)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			merged = append(merged, key)
+		}
+	}
+
+	if len(merged) == 0 {
+		return
+	}
+
+	header.Set(varyHeader, strings.Join(merged, ", "))
+}
+
+// WithVary adds the given request header names to the Vary header of the
+// response. The names are merged with any Vary header already set on the
+// http.ResponseWriter, for example by middleware, using [AddVary]. It returns
+// the Response to allow chaining.
+func (r *Response) WithVary(names ...string) *Response {
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+
+	AddVary(r.header, names...)
+
+	return r
+}
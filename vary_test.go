@@ -0,0 +1,131 @@
+package httputil_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nickbryan/slogutil"
+
+	"github.com/nickbryan/httputil"
+)
+
+func TestAddVary(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		header   http.Header
+		names    []string
+		wantVary []string
+	}{
+		"adds names to an empty header": {
+			header:   http.Header{},
+			names:    []string{"Accept-Encoding", "Accept"},
+			wantVary: []string{"Accept-Encoding, Accept"},
+		},
+		"accumulates names with an existing header": {
+			header:   http.Header{"Vary": {"Accept-Encoding"}},
+			names:    []string{"Accept"},
+			wantVary: []string{"Accept-Encoding, Accept"},
+		},
+		"does not duplicate existing names regardless of case": {
+			header:   http.Header{"Vary": {"Accept-Encoding, Accept"}},
+			names:    []string{"accept-encoding", "ACCEPT", "Accept-Language"},
+			wantVary: []string{"Accept-Encoding, Accept, Accept-Language"},
+		},
+		"merges multiple existing header values": {
+			header:   http.Header{"Vary": {"Accept-Encoding", "Accept"}},
+			names:    []string{"Accept"},
+			wantVary: []string{"Accept-Encoding, Accept"},
+		},
+		"splits comma separated names": {
+			header:   http.Header{},
+			names:    []string{"Accept, Accept-Language"},
+			wantVary: []string{"Accept, Accept-Language"},
+		},
+		"a wildcard replaces all names": {
+			header:   http.Header{"Vary": {"Accept-Encoding"}},
+			names:    []string{"*"},
+			wantVary: []string{"*"},
+		},
+		"no names leaves the header unset": {
+			header:   http.Header{},
+			names:    nil,
+			wantVary: nil,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			httputil.AddVary(testCase.header, testCase.names...)
+
+			if diff := cmp.Diff(testCase.wantVary, testCase.header.Values("Vary")); diff != "" {
+				t.Errorf("header.Values(\"Vary\") mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResponse_WithVary(t *testing.T) {
+	t.Parallel()
+
+	varyOnEncoding := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httputil.AddVary(w.Header(), "Accept-Encoding")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	testCases := map[string]struct {
+		action     httputil.Action[struct{}, struct{}]
+		middleware []httputil.MiddlewareFunc
+		wantVary   []string
+	}{
+		"with data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+				return res.WithVary("Accept"), nil
+			},
+			wantVary: []string{"Accept"},
+		},
+		"without data": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.NoContent()
+				return res.WithVary("Accept", "Accept-Language"), nil
+			},
+			wantVary: []string{"Accept, Accept-Language"},
+		},
+		"accumulates with middleware without duplication": {
+			action: func(_ httputil.RequestEmpty) (*httputil.Response, error) {
+				res, _ := httputil.OK(map[string]string{"hello": "world"})
+				return res.WithVary("Accept", "accept-encoding").WithVary("Accept"), nil
+			},
+			middleware: []httputil.MiddlewareFunc{varyOnEncoding, varyOnEncoding},
+			wantVary:   []string{"Accept-Encoding, Accept"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger, _ := slogutil.NewInMemoryLogger(slog.LevelDebug)
+			server := httputil.NewServer(logger)
+			server.Register(httputil.EndpointGroup{
+				{Method: http.MethodGet, Path: "/test", Handler: httputil.NewHandler(testCase.action)},
+			}.WithMiddleware(testCase.middleware...)...)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if diff := cmp.Diff(testCase.wantVary, response.Header().Values("Vary")); diff != "" {
+				t.Errorf("response.Header().Values(\"Vary\") mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}